@@ -0,0 +1,114 @@
+package aggregate
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// Aggregate агрегирует показания в показания архива target (например, часовые показания в суточные или месячные).
+//
+// Показания группируются по прибору учета, тепловому вводу и каналу. Массы, объемы, тепловая энергия и время
+// штатной работы (M, V, Q, Q1, Q2, TI) суммируются; температуры и давление (T, P, TCW) усредняются способом,
+// заданным опцией WithAveraging. Пустые строки не учитываются, "плохие" строки учитываются согласно опции
+// WithBadRows; агрегированное показание считается "плохим", если в периоде была хотя бы одна "плохая" строка.
+// Значения null не учитываются; если в периоде нет ни одного значения величины, агрегированное значение равно null.
+//
+// Агрегированные показания упорядочены по прибору учета, тепловому вводу, каналу и моменту показания
+func Aggregate(readings []parsers.Readings, target archive.DataArchive, opts ...Option) ([]parsers.Readings, error) {
+	options := &aggregateOptions{}
+
+	for _, option := range opts {
+		option(options)
+	}
+
+	var period Period = target
+
+	if options.period != nil {
+		period = options.period
+	} else if !target.Periodic() {
+		return nil, fmt.Errorf("cannot aggregate into %s archive", target)
+	}
+
+	if options.dayStart != 0 {
+		period = &shifted{period: period, offset: options.dayStart}
+	}
+
+	groups := make(map[groupKey]*group)
+
+	for i := range readings {
+		r := &readings[i]
+
+		if r.Empty.Valid && r.Empty.Bool {
+			continue
+		}
+
+		dt := time.Time(r.DT)
+
+		if options.intervalEnd {
+			dt = dt.Add(-time.Nanosecond)
+		}
+
+		start := period.Truncate(dt)
+
+		key := groupKey{
+			device:     r.DeviceID,
+			input:      r.Input,
+			channel:    r.ChannelID,
+			channelNum: r.ChannelNum,
+			start:      start.UnixNano(),
+		}
+
+		g, ok := groups[key]
+
+		if !ok {
+			g = &group{key: key, start: start}
+			groups[key] = g
+		}
+
+		g.add(r, options)
+	}
+
+	result := make([]*group, 0, len(groups))
+
+	for _, g := range groups {
+		result = append(result, g)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].key.less(result[j].key)
+	})
+
+	aggregated := make([]parsers.Readings, 0, len(result))
+
+	for _, g := range result {
+		dt := g.start
+
+		if options.intervalEnd {
+			dt = period.Next(g.start)
+		}
+
+		aggregated = append(aggregated, g.readings(target, dt, options))
+	}
+
+	return aggregated, nil
+}
+
+// shifted период, смещенный относительно исходного на offset
+type shifted struct {
+	period Period
+	offset time.Duration
+}
+
+// Truncate реализация интерфейса Period
+func (s *shifted) Truncate(t time.Time) time.Time {
+	return s.period.Truncate(t.Add(-s.offset)).Add(s.offset)
+}
+
+// Next реализация интерфейса Period
+func (s *shifted) Next(t time.Time) time.Time {
+	return s.period.Next(t.Add(-s.offset)).Add(s.offset)
+}
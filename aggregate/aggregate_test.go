@@ -0,0 +1,136 @@
+package aggregate
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+func loadReadings(t *testing.T) []parsers.Readings {
+	path, err := filepath.Abs("../testdata/responses/readings200.json")
+
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path)
+
+	require.NoError(t, err)
+
+	items, err := parsers.ParseReadings(context.TODO(), data)
+
+	require.NoError(t, err)
+
+	readings := make([]parsers.Readings, 0)
+
+	for item := range items {
+		require.NoError(t, item.E)
+
+		readings = append(readings, *item.V.(*parsers.Readings))
+	}
+
+	return readings
+}
+
+func TestAggregate_Daily(t *testing.T) {
+	readings := loadReadings(t)
+
+	daily, err := Aggregate(readings, archive.DailyArchive)
+
+	require.NoError(t, err)
+	assert.Len(t, daily, 3*8)
+
+	var day *parsers.Readings
+
+	for i := range daily {
+		r := &daily[i]
+
+		if r.ChannelID.Int64 == 19265 && time.Time(r.DT).Equal(time.Date(2021, 4, 12, 0, 0, 0, 0, time.UTC)) {
+			day = r
+		}
+
+		assert.Equal(t, archive.DailyArchive, r.Archive)
+	}
+
+	require.NotNil(t, day)
+	assert.InDelta(t, 44.6213436126709, day.M.Float64, 1e-9)
+	assert.InDelta(t, 64.4400284022471, day.T.Float64, 1e-9)
+	assert.InDelta(t, 24, day.TI.Float64, 1e-9)
+	assert.False(t, day.Q.Valid)
+
+	daily, err = Aggregate(readings, archive.DailyArchive, WithIntervalEnd())
+
+	require.NoError(t, err)
+	assert.Len(t, daily, 3*8)
+
+	assert.True(t, time.Time(daily[0].DT).Equal(time.Date(2021, 4, 12, 0, 0, 0, 0, time.UTC)))
+	assert.InDelta(t, 24, daily[0].TI.Float64, 1e-9)
+
+	monthly, err := Aggregate(readings, archive.MonthlyArchive)
+
+	require.NoError(t, err)
+	assert.Len(t, monthly, 3)
+
+	_, err = Aggregate(readings, archive.CurrentArchive)
+
+	assert.Error(t, err)
+}
+
+func TestAggregate_Rows(t *testing.T) {
+	dt := time.Date(2021, 4, 11, 0, 0, 0, 0, time.UTC)
+
+	row := func(hour int, m, temp, ti null.Float, bad bool) parsers.Readings {
+		return parsers.Readings{
+			DeviceID:  null.IntFrom(1),
+			ChannelID: null.IntFrom(10),
+			DT:        parsers.ReadingTime(dt.Add(time.Duration(hour) * time.Hour)),
+			M:         m,
+			T:         temp,
+			TI:        ti,
+			IsBadRow:  bad,
+		}
+	}
+
+	empty := row(3, null.FloatFrom(100), null.FloatFrom(100), null.FloatFrom(1), false)
+	empty.Empty = null.BoolFrom(true)
+
+	readings := []parsers.Readings{
+		row(8, null.FloatFrom(1), null.FloatFrom(60), null.FloatFrom(1), false),
+		row(9, null.FloatFrom(3), null.FloatFrom(70), null.FloatFrom(0.5), false),
+		row(10, null.FloatFrom(50), null.FloatFrom(0), null.FloatFrom(1), true),
+		row(11, null.Float{}, null.Float{}, null.Float{}, false),
+		empty,
+	}
+
+	var cases = []struct {
+		options []Option
+		days    int
+		m       float64
+		t       float64
+	}{
+		{days: 1, m: 4, t: (60*1 + 70*3) / 4.0},
+		{options: []Option{WithAveraging(TimeWeighted)}, days: 1, m: 4, t: (60*1 + 70*0.5) / 1.5},
+		{options: []Option{WithBadRows(IncludeBadRows)}, days: 1, m: 54, t: (60*1 + 70*3) / 54.0},
+		{options: []Option{WithDayStart(9 * time.Hour)}, days: 2, m: 1, t: 60},
+	}
+
+	for _, test := range cases {
+		daily, err := Aggregate(readings, archive.DailyArchive, test.options...)
+
+		require.NoError(t, err)
+		require.Len(t, daily, test.days)
+
+		r := daily[0]
+
+		assert.True(t, r.IsBadRow || test.days == 2)
+		assert.InDelta(t, test.m, r.M.Float64, 1e-9)
+		assert.InDelta(t, test.t, r.T.Float64, 1e-9)
+	}
+}
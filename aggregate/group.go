@@ -0,0 +1,178 @@
+package aggregate
+
+import (
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// groupKey ключ группы показаний одного канала за один период
+type groupKey struct {
+	device, input, channel, channelNum null.Int
+	start                              int64
+}
+
+func (k groupKey) less(other groupKey) bool {
+	a := [...]null.Int{k.device, k.input, k.channel, k.channelNum}
+	b := [...]null.Int{other.device, other.input, other.channel, other.channelNum}
+
+	for i := range a {
+		if a[i].Valid != b[i].Valid {
+			return !a[i].Valid
+		}
+
+		if a[i].Int64 != b[i].Int64 {
+			return a[i].Int64 < b[i].Int64
+		}
+	}
+
+	return k.start < other.start
+}
+
+// group накопитель показаний одного канала за один период
+type group struct {
+	key   groupKey
+	start time.Time
+
+	createAt time.Time
+	badRow   bool
+
+	m, v, q, q1, q2, ti sum
+	t, p, tcw           average
+}
+
+func (g *group) add(r *parsers.Readings, options *aggregateOptions) {
+	if t := time.Time(r.CreateAt); t.After(g.createAt) {
+		g.createAt = t
+	}
+
+	if r.IsBadRow {
+		g.badRow = true
+
+		if options.badRows == ExcludeBadRows {
+			return
+		}
+	}
+
+	g.m.add(r.M)
+	g.v.add(r.V)
+	g.q.add(r.Q)
+	g.q1.add(r.Q1)
+	g.q2.add(r.Q2)
+	g.ti.add(r.TI)
+
+	g.t.add(r.T, r.M, r.TI)
+	g.p.add(r.P, r.M, r.TI)
+	g.tcw.add(r.TCW, r.M, r.TI)
+}
+
+func (g *group) readings(target archive.DataArchive, dt time.Time, options *aggregateOptions) parsers.Readings {
+	return parsers.Readings{
+		Archive:    target,
+		DeviceID:   g.key.device,
+		Input:      g.key.input,
+		ChannelID:  g.key.channel,
+		ChannelNum: g.key.channelNum,
+		CreateAt:   parsers.ReadingTime(g.createAt),
+		DT:         parsers.ReadingTime(dt),
+		IsBadRow:   g.badRow,
+		M:          g.m.value(),
+		V:          g.v.value(),
+		Q:          g.q.value(),
+		Q1:         g.q1.value(),
+		Q2:         g.q2.value(),
+		TI:         g.ti.value(),
+		T:          g.t.value(options.averaging),
+		P:          g.p.value(options.averaging),
+		TCW:        g.tcw.value(options.averaging),
+	}
+}
+
+// sum сумма значений величины без учета null
+type sum struct {
+	total float64
+	valid bool
+}
+
+func (s *sum) add(x null.Float) {
+	if x.Valid {
+		s.total += x.Float64
+		s.valid = true
+	}
+}
+
+func (s *sum) value() null.Float {
+	return null.NewFloat(s.total, s.valid)
+}
+
+// weighted средневзвешенное значение величины
+type weighted struct {
+	sum, weight float64
+
+	// complete признак наличия веса у всех значений величины
+	complete bool
+}
+
+func (w *weighted) add(x float64, weight null.Float) {
+	if !weight.Valid {
+		w.complete = false
+		return
+	}
+
+	w.sum += x * weight.Float64
+	w.weight += weight.Float64
+}
+
+func (w *weighted) value() (float64, bool) {
+	if !w.complete || w.weight <= 0 {
+		return 0, false
+	}
+
+	return w.sum / w.weight, true
+}
+
+// average среднее значение величины: средневзвешенное по массе, по времени или среднее арифметическое, если веса
+// указаны не для всех значений
+type average struct {
+	byMass, byTime weighted
+	arithmetic     sum
+	count          int
+}
+
+func (a *average) add(x, mass, ti null.Float) {
+	if !x.Valid {
+		return
+	}
+
+	if a.count == 0 {
+		a.byMass.complete = true
+		a.byTime.complete = true
+	}
+
+	a.count++
+
+	a.byMass.add(x.Float64, mass)
+	a.byTime.add(x.Float64, ti)
+	a.arithmetic.add(x)
+}
+
+func (a *average) value(averaging Averaging) null.Float {
+	if a.count == 0 {
+		return null.Float{}
+	}
+
+	if averaging == MassWeighted {
+		if v, ok := a.byMass.value(); ok {
+			return null.FloatFrom(v)
+		}
+	}
+
+	if v, ok := a.byTime.value(); ok {
+		return null.FloatFrom(v)
+	}
+
+	return null.FloatFrom(a.arithmetic.total / float64(a.count))
+}
@@ -0,0 +1,85 @@
+package aggregate
+
+import (
+	"time"
+)
+
+// Averaging способ усреднения температур и давления
+type Averaging byte
+
+const (
+	// MassWeighted средневзвешенное по массе теплоносителя значение. Если масса не указана, значение усредняется по
+	// времени штатной работы прибора учета
+	MassWeighted Averaging = iota
+
+	// TimeWeighted средневзвешенное по времени штатной работы прибора учета значение
+	TimeWeighted
+)
+
+// BadRows способ учета "плохих" строк показаний
+type BadRows byte
+
+const (
+	// ExcludeBadRows "плохие" строки не учитываются
+	ExcludeBadRows BadRows = iota
+
+	// IncludeBadRows "плохие" строки учитываются наравне с остальными
+	IncludeBadRows
+)
+
+// Period правило разбиения показаний на периоды агрегирования. Тип archive.DataArchive реализует этот интерфейс
+type Period interface {
+	// Truncate возвращает начало периода, в который попадает момент t
+	Truncate(t time.Time) time.Time
+
+	// Next возвращает начало периода, следующего за периодом, в который попадает момент t
+	Next(t time.Time) time.Time
+}
+
+type aggregateOptions struct {
+	period      Period
+	dayStart    time.Duration
+	intervalEnd bool
+	averaging   Averaging
+	badRows     BadRows
+}
+
+// Option опция агрегирования показаний
+type Option func(options *aggregateOptions)
+
+// WithPeriod устанавливает произвольное правило разбиения показаний на периоды взамен интервалов архива
+func WithPeriod(period Period) Option {
+	return func(options *aggregateOptions) {
+		options.period = period
+	}
+}
+
+// WithDayStart устанавливает начало отчетных суток: отчетные сутки (и месяц) начинаются через offset после полуночи.
+// Смещение применяется и к периоду, заданному опцией WithPeriod
+func WithDayStart(offset time.Duration) Option {
+	return func(options *aggregateOptions) {
+		options.dayStart = offset
+	}
+}
+
+// WithIntervalEnd указывает, что момент показания обозначает окончание интервала архива, а не его начало. Моменты
+// агрегированных показаний в этом случае также обозначают окончание периода
+func WithIntervalEnd() Option {
+	return func(options *aggregateOptions) {
+		options.intervalEnd = true
+	}
+}
+
+// WithAveraging устанавливает способ усреднения температур и давления. По умолчанию MassWeighted
+func WithAveraging(averaging Averaging) Option {
+	return func(options *aggregateOptions) {
+		options.averaging = averaging
+	}
+}
+
+// WithBadRows устанавливает способ учета "плохих" строк показаний. По умолчанию ExcludeBadRows
+func WithBadRows(badRows BadRows) Option {
+	return func(options *aggregateOptions) {
+		options.badRows = badRows
+	}
+}
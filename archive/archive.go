@@ -1,8 +1,14 @@
 package archive
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"flag"
 	"fmt"
-	"strings"
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/internal/jsontext"
 )
 
 // DataArchive архив показаний прибора учета
@@ -15,14 +21,31 @@ const (
 	HourArchive DataArchive = 1
 	// DailyArchive суточный архив
 	DailyArchive DataArchive = 2
+	// MonthlyArchive месячный архив
+	MonthlyArchive DataArchive = 3
+	// CurrentArchive архив текущих (мгновенных) показаний
+	CurrentArchive DataArchive = 4
+	// TotalArchive архив итоговых (нарастающих) показаний
+	TotalArchive DataArchive = 5
 )
 
 const (
 	hourArchive    = "Hour"
 	dailyArchive   = "Day"
+	monthlyArchive = "Month"
+	currentArchive = "Current"
+	totalArchive   = "Total"
 	unknownArchive = "Unknown"
 )
 
+var (
+	_ encoding.TextMarshaler   = DataArchive(0)
+	_ encoding.TextUnmarshaler = (*DataArchive)(nil)
+	_ flag.Value               = (*DataArchive)(nil)
+	_ sql.Scanner              = (*DataArchive)(nil)
+	_ driver.Valuer            = DataArchive(0)
+)
+
 // String возвращает строковое описание типа архива показаний
 func (a DataArchive) String() string {
 	switch a {
@@ -30,6 +53,12 @@ func (a DataArchive) String() string {
 		return hourArchive
 	case DailyArchive:
 		return dailyArchive
+	case MonthlyArchive:
+		return monthlyArchive
+	case CurrentArchive:
+		return currentArchive
+	case TotalArchive:
+		return totalArchive
 	default:
 		return unknownArchive
 	}
@@ -37,35 +66,157 @@ func (a DataArchive) String() string {
 
 // UnmarshalJSON реализация интерфейса Unmarshaler для типа DataArchive
 func (a *DataArchive) UnmarshalJSON(b []byte) (err error) {
-	s := strings.Trim(string(b), `"`)
+	s, err := jsontext.Unquote(b)
 
-	switch s {
-	case hourArchive:
-		*a = HourArchive
-	case dailyArchive:
-		*a = DailyArchive
-	default:
-		*a = UnknownArchive
-		err = fmt.Errorf("unknown archive type %s", s)
+	if err != nil {
+		return err
 	}
 
+	*a, err = ParseArchive(s)
+
 	return
 }
 
-// MarshalJSON реализация интерфейса Marshaler для типа RequestTime
+// MarshalJSON реализация интерфейса Marshaler для типа DataArchive
 func (a DataArchive) MarshalJSON() ([]byte, error) {
 	s := fmt.Sprintf(`"%s"`, a.String())
 	return []byte(s), nil
 }
 
+// UnmarshalText реализация интерфейса encoding.TextUnmarshaler для типа DataArchive
+func (a *DataArchive) UnmarshalText(text []byte) (err error) {
+	*a, err = ParseArchive(string(text))
+
+	return
+}
+
+// MarshalText реализация интерфейса encoding.TextMarshaler для типа DataArchive
+func (a DataArchive) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// Set реализация интерфейса flag.Value для типа DataArchive
+func (a *DataArchive) Set(s string) (err error) {
+	*a, err = ParseArchive(s)
+
+	return
+}
+
+// Scan реализация интерфейса sql.Scanner для типа DataArchive. Допускается чтение как строкового, так и числового
+// представления типа архива. Строка "Unknown", которую записывает Value для неизвестного типа архива, читается как
+// UnknownArchive
+func (a *DataArchive) Scan(src interface{}) (err error) {
+	switch v := src.(type) {
+	case nil:
+		*a = UnknownArchive
+
+	case string:
+		*a, err = scanArchive(v)
+
+	case []byte:
+		*a, err = scanArchive(string(v))
+
+	case int64:
+		if v < 0 || v > int64(TotalArchive) {
+			*a = UnknownArchive
+			return fmt.Errorf("unknown archive type %d", v)
+		}
+
+		*a = DataArchive(v)
+
+	default:
+		err = fmt.Errorf("cannot scan %T into DataArchive", src)
+	}
+
+	return
+}
+
+// scanArchive преобразование строкового представления типа архива, прочитанного из базы данных
+func scanArchive(s string) (DataArchive, error) {
+	if s == unknownArchive {
+		return UnknownArchive, nil
+	}
+
+	return ParseArchive(s)
+}
+
+// Value реализация интерфейса driver.Valuer для типа DataArchive
+func (a DataArchive) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Duration возвращает номинальную продолжительность интервала архива. Для месячного архива возвращается
+// продолжительность 30 суток, для архивов без фиксированного интервала (текущие и итоговые показания) - 0
+func (a DataArchive) Duration() time.Duration {
+	switch a {
+	case HourArchive:
+		return time.Hour
+	case DailyArchive:
+		return 24 * time.Hour
+	case MonthlyArchive:
+		return 30 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Truncate возвращает начало интервала архива, в который попадает момент t. Интервалы выравниваются по календарю
+// в часовом поясе t. Для архивов без фиксированного интервала момент t возвращается без изменений
+func (a DataArchive) Truncate(t time.Time) time.Time {
+	switch a {
+	case HourArchive:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case DailyArchive:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case MonthlyArchive:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	default:
+		return t
+	}
+}
+
+// Next возвращает начало интервала архива, следующего за интервалом, в который попадает момент t. Для архивов без
+// фиксированного интервала момент t возвращается без изменений
+func (a DataArchive) Next(t time.Time) time.Time {
+	t = a.Truncate(t)
+
+	switch a {
+	case HourArchive:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+	case DailyArchive:
+		return t.AddDate(0, 0, 1)
+	case MonthlyArchive:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t
+	}
+}
+
+// Periodic возвращает признак архива с фиксированным интервалом показаний
+func (a DataArchive) Periodic() bool {
+	return a == HourArchive || a == DailyArchive || a == MonthlyArchive
+}
+
 // Parse преобразование строки в значение DataArchive
 func Parse(archive string) DataArchive {
+	a, _ := ParseArchive(archive)
+	return a
+}
+
+// ParseArchive преобразование строки в значение DataArchive. Возвращает ошибку, если тип архива неизвестен
+func ParseArchive(archive string) (DataArchive, error) {
 	switch archive {
 	case hourArchive:
-		return HourArchive
+		return HourArchive, nil
 	case dailyArchive:
-		return DailyArchive
+		return DailyArchive, nil
+	case monthlyArchive:
+		return MonthlyArchive, nil
+	case currentArchive:
+		return CurrentArchive, nil
+	case totalArchive:
+		return TotalArchive, nil
 	default:
-		return UnknownArchive
+		return UnknownArchive, fmt.Errorf("unknown archive type %s", archive)
 	}
 }
@@ -0,0 +1,151 @@
+package archive
+
+import (
+	"encoding/json"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataArchive_JSON(t *testing.T) {
+	var cases = []struct {
+		archive DataArchive
+		json    string
+	}{
+		{archive: HourArchive, json: `"Hour"`},
+		{archive: DailyArchive, json: `"Day"`},
+		{archive: MonthlyArchive, json: `"Month"`},
+		{archive: CurrentArchive, json: `"Current"`},
+		{archive: TotalArchive, json: `"Total"`},
+	}
+
+	for _, test := range cases {
+		b, err := json.Marshal(test.archive)
+
+		require.NoError(t, err, test.json)
+		assert.Equal(t, test.json, string(b))
+
+		var a DataArchive
+
+		err = json.Unmarshal(b, &a)
+
+		require.NoError(t, err, test.json)
+		assert.Equal(t, test.archive, a)
+	}
+
+	var a DataArchive
+
+	err := json.Unmarshal([]byte(`"Minute"`), &a)
+
+	assert.Error(t, err)
+	assert.Equal(t, UnknownArchive, a)
+}
+
+func TestDataArchive_Set(t *testing.T) {
+	var a DataArchive
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&a, "archive", "archive type")
+
+	err := fs.Parse([]string{"-archive", "Month"})
+
+	require.NoError(t, err)
+	assert.Equal(t, MonthlyArchive, a)
+
+	err = fs.Parse([]string{"-archive", "Week"})
+
+	assert.Error(t, err)
+}
+
+func TestDataArchive_Scan(t *testing.T) {
+	var cases = []struct {
+		src     interface{}
+		archive DataArchive
+		err     bool
+	}{
+		{src: "Hour", archive: HourArchive},
+		{src: []byte("Day"), archive: DailyArchive},
+		{src: int64(3), archive: MonthlyArchive},
+		{src: nil, archive: UnknownArchive},
+		{src: int64(42), archive: UnknownArchive, err: true},
+		{src: int64(256), archive: UnknownArchive, err: true},
+		{src: int64(257), archive: UnknownArchive, err: true},
+		{src: int64(-255), archive: UnknownArchive, err: true},
+		{src: "Unknown", archive: UnknownArchive},
+		{src: "Week", archive: UnknownArchive, err: true},
+		{src: 1.5, archive: UnknownArchive, err: true},
+	}
+
+	for _, test := range cases {
+		var a DataArchive
+
+		err := a.Scan(test.src)
+
+		if test.err {
+			assert.Error(t, err, test.src)
+		} else {
+			assert.NoError(t, err, test.src)
+		}
+
+		assert.Equal(t, test.archive, a, test.src)
+	}
+
+	v, err := DailyArchive.Value()
+
+	require.NoError(t, err)
+	assert.Equal(t, "Day", v)
+
+	for _, archive := range []DataArchive{UnknownArchive, HourArchive, TotalArchive} {
+		v, err := archive.Value()
+
+		require.NoError(t, err)
+
+		var scanned DataArchive
+
+		require.NoError(t, scanned.Scan(v))
+		assert.Equal(t, archive, scanned)
+	}
+}
+
+func TestDataArchive_Truncate(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	moment := time.Date(2021, 4, 11, 13, 45, 10, 0, loc)
+
+	var cases = []struct {
+		archive  DataArchive
+		truncate time.Time
+		next     time.Time
+	}{
+		{
+			archive:  HourArchive,
+			truncate: time.Date(2021, 4, 11, 13, 0, 0, 0, loc),
+			next:     time.Date(2021, 4, 11, 14, 0, 0, 0, loc),
+		},
+		{
+			archive:  DailyArchive,
+			truncate: time.Date(2021, 4, 11, 0, 0, 0, 0, loc),
+			next:     time.Date(2021, 4, 12, 0, 0, 0, 0, loc),
+		},
+		{
+			archive:  MonthlyArchive,
+			truncate: time.Date(2021, 4, 1, 0, 0, 0, 0, loc),
+			next:     time.Date(2021, 5, 1, 0, 0, 0, 0, loc),
+		},
+		{
+			archive:  CurrentArchive,
+			truncate: moment,
+			next:     moment,
+		},
+	}
+
+	for _, test := range cases {
+		assert.True(t, test.truncate.Equal(test.archive.Truncate(moment)), test.archive)
+		assert.True(t, test.next.Equal(test.archive.Next(moment)), test.archive)
+	}
+
+	assert.Equal(t, time.Hour, HourArchive.Duration())
+	assert.Equal(t, time.Duration(0), TotalArchive.Duration())
+}
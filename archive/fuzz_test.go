@@ -0,0 +1,71 @@
+package archive
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func FuzzDataArchive(f *testing.F) {
+	for _, a := range []DataArchive{HourArchive, DailyArchive, MonthlyArchive, CurrentArchive, TotalArchive} {
+		b, err := json.Marshal(a)
+
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(b)
+	}
+
+	f.Add([]byte(`null`))
+	f.Add([]byte(`"Minute"`))
+	f.Add([]byte(`Hour`))
+	f.Add([]byte(`"Hour"`))
+	f.Add([]byte(`3`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var scanned DataArchive
+
+		want, wantErr := ParseArchive(string(data))
+
+		if err := scanned.Scan(data); (err == nil) != (wantErr == nil) || scanned != want {
+			t.Fatalf("scan %q: got %v (%v), want %v (%v)", data, scanned, err, want, wantErr)
+		}
+
+		var a DataArchive
+
+		err := a.UnmarshalJSON(data)
+
+		var s string
+
+		if json.Unmarshal(data, &s) != nil {
+			if err == nil {
+				t.Fatalf("invalid JSON string %q accepted", data)
+			}
+
+			return
+		}
+
+		want, wantErr = ParseArchive(s)
+
+		if (err == nil) != (wantErr == nil) || a != want {
+			t.Fatalf("%q: got %v (%v), want %v (%v)", data, a, err, want, wantErr)
+		}
+
+		if err != nil {
+			return
+		}
+
+		b, err := json.Marshal(a)
+
+		if err != nil {
+			t.Fatalf("marshal %v: %v", a, err)
+		}
+
+		var again DataArchive
+
+		if err = json.Unmarshal(b, &again); err != nil || again != a {
+			t.Fatalf("round trip %s: got %v (%v), want %v", data, again, err, a)
+		}
+
+	})
+}
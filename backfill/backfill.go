@@ -0,0 +1,248 @@
+package backfill
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	cascade "github.com/vitpelekhaty/go-cascade-client/v2"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// Handler обработчик показаний, загруженных единицей работы unit, например сохранение показаний в хранилище.
+// Вызовы обработчика выполняются последовательно. Единица работы отмечается выполненной после успешного вызова
+// обработчика, поэтому при возобновлении загрузки показания единицы работы, прерванной после вызова обработчика,
+// могут быть переданы обработчику повторно
+type Handler func(ctx context.Context, unit Unit, readings []parsers.Readings) error
+
+// Progress состояние загрузки после завершения единицы работы
+type Progress struct {
+	// Unit завершенная единица работы
+	Unit Unit
+
+	// Err ошибка единицы работы
+	Err error
+
+	// Resumed признак единицы работы, выполненной до возобновления загрузки
+	Resumed bool
+
+	// Total количество единиц работы
+	Total int
+
+	// Done количество выполненных единиц работы, включая пропущенные
+	Done int
+
+	// Skipped количество единиц работы, выполненных до возобновления загрузки
+	Skipped int
+
+	// Failed количество единиц работы, завершенных с ошибкой
+	Failed int
+
+	// Readings количество загруженных показаний
+	Readings int
+}
+
+// UnitError ошибка единицы работы
+type UnitError struct {
+	// Unit единица работы
+	Unit Unit
+
+	// Err ошибка
+	Err error
+}
+
+// Error реализация интерфейса error
+func (e *UnitError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Unit, e.Err)
+}
+
+// Unwrap возвращает исходную ошибку
+func (e *UnitError) Unwrap() error {
+	return e.Err
+}
+
+// Run выполняет единицы работы units: запрашивает текущие показания у API Каскада conn и передает их обработчику
+// handler. Единицы работы выполняются одновременно (см. WithConcurrency). Ошибка единицы работы не прерывает
+// загрузку: Run возвращает состояние загрузки и ошибки единиц работы (см. UnitError). При отмене ctx загрузка
+// прерывается, выполненные единицы работы остаются отмеченными в контрольной точке.
+//
+// Период запроса показаний единицы работы заканчивается за секунду до окончания ее периода, чтобы показания на
+// границе соседних периодов не запрашивались дважды
+func Run(ctx context.Context, conn cascade.IConnection, units []Unit, handler Handler, opts ...Option) (
+	*Progress, error) {
+	options := newRunOptions(opts)
+
+	r := &runner{
+		conn:     conn,
+		handler:  handler,
+		options:  options,
+		progress: Progress{Total: len(units)},
+	}
+
+	pending := make(chan Unit)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < options.concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for unit := range pending {
+				r.execute(ctx, unit)
+			}
+		}()
+	}
+
+loop:
+	for _, unit := range units {
+		if options.checkpoint != nil {
+			if _, ok := options.checkpoint.Done(unit); ok {
+				r.finish(unit, 0, nil, true)
+				continue
+			}
+		}
+
+		select {
+		case pending <- unit:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	close(pending)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return &r.progress, err
+	}
+
+	return &r.progress, errors.Join(r.errors...)
+}
+
+// runner выполнение единиц работы
+type runner struct {
+	conn    cascade.IConnection
+	handler Handler
+	options *runOptions
+
+	handlerMu sync.Mutex
+
+	mu       sync.Mutex
+	progress Progress
+	errors   []error
+}
+
+// execute выполняет единицу работы
+func (r *runner) execute(ctx context.Context, unit Unit) {
+	readings, err := r.fetch(ctx, unit)
+
+	if err == nil {
+		err = r.handle(ctx, unit, readings)
+	}
+
+	if err == nil && r.options.checkpoint != nil {
+		err = r.options.checkpoint.MarkDone(unit, Done{Readings: len(readings), At: time.Now()})
+	}
+
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	r.finish(unit, len(readings), err, false)
+}
+
+// fetch запрашивает показания единицы работы с повторами
+func (r *runner) fetch(ctx context.Context, unit Unit) ([]parsers.Readings, error) {
+	var err error
+
+	for attempt := 0; attempt <= r.options.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(r.options.backoff * time.Duration(attempt)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		var data []byte
+
+		data, err = r.conn.CurrentReadings(ctx, unit.DeviceID, unit.Archive, unit.From, unit.To.Add(-time.Second))
+
+		if err == nil {
+			return parse(ctx, data)
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, err
+}
+
+// handle передает показания обработчику
+func (r *runner) handle(ctx context.Context, unit Unit, readings []parsers.Readings) error {
+	r.handlerMu.Lock()
+	defer r.handlerMu.Unlock()
+
+	return r.handler(ctx, unit, readings)
+}
+
+// finish учитывает завершение единицы работы
+func (r *runner) finish(unit Unit, readings int, err error, skipped bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.progress.Unit, r.progress.Err, r.progress.Resumed = unit, nil, skipped
+
+	switch {
+	case err != nil:
+		r.progress.Failed++
+		r.progress.Err = &UnitError{Unit: unit, Err: err}
+		r.errors = append(r.errors, r.progress.Err)
+	case skipped:
+		r.progress.Done++
+		r.progress.Skipped++
+	default:
+		r.progress.Done++
+		r.progress.Readings += readings
+	}
+
+	if r.options.progress != nil {
+		r.options.progress(r.progress)
+	}
+}
+
+// parse разбирает показания, декодируя их непосредственно в результирующий срез
+func parse(ctx context.Context, data []byte) ([]parsers.Readings, error) {
+	d := parsers.NewReadingsDecoder(bytes.NewReader(data), parsers.WithStrict())
+
+	readings := make([]parsers.Readings, 0, parsers.DefaultBatchSize)
+
+	for {
+		if cap(readings)-len(readings) < parsers.DefaultBatchSize {
+			grown := make([]parsers.Readings, len(readings), 2*cap(readings)+parsers.DefaultBatchSize)
+			copy(grown, readings)
+
+			readings = grown
+		}
+
+		n, err := d.Decode(readings[len(readings):cap(readings)])
+
+		readings = readings[:len(readings)+n]
+
+		if err == io.EOF {
+			return readings, ctx.Err()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package backfill
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cascade "github.com/vitpelekhaty/go-cascade-client/v2"
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+type fakeConnection struct {
+	mu       sync.Mutex
+	calls    []string
+	failures map[int64]int
+}
+
+var _ cascade.IConnection = (*fakeConnection)(nil)
+
+func (conn *fakeConnection) Open(context.Context, string, string, string, ...cascade.OpenOption) error {
+	return nil
+}
+
+func (conn *fakeConnection) Close(context.Context) error {
+	return nil
+}
+
+func (conn *fakeConnection) Connected() bool {
+	return true
+}
+
+func (conn *fakeConnection) Gauges(context.Context) ([]byte, error) {
+	return []byte("[]"), nil
+}
+
+func (conn *fakeConnection) CurrentReadings(_ context.Context, deviceID int64, a archive.DataArchive, beginAt,
+	endAt time.Time, _ ...byte) ([]byte, error) {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	conn.calls = append(conn.calls, fmt.Sprintf("%d %s %s", deviceID, beginAt.Format(unitTimeLayout),
+		endAt.Format(unitTimeLayout)))
+
+	if conn.failures[deviceID] > 0 {
+		conn.failures[deviceID]--
+		return nil, errors.New("service unavailable")
+	}
+
+	return []byte(fmt.Sprintf(`[{"id": %d, "deviceId": %d, "archiveType": "%s", "dt": "%s"}]`,
+		deviceID*1000000+beginAt.Unix()/3600, deviceID, a, beginAt.Format(unitTimeLayout))), nil
+}
+
+func (conn *fakeConnection) AlteredReadings(context.Context, int64, archive.DataArchive, time.Time, time.Time,
+	...byte) ([]byte, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestPlan(t *testing.T) {
+	from := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	units, err := Plan([]int64{2, 1}, archive.HourArchive, from, to, MaxWindow)
+
+	require.NoError(t, err)
+	require.Len(t, units, 6)
+
+	assert.Equal(t, Unit{DeviceID: 1, Archive: archive.HourArchive, From: from, To: from.Add(MaxWindow)}, units[0])
+	assert.Equal(t, int64(2), units[1].DeviceID)
+	assert.Equal(t, to, units[5].To)
+	assert.Equal(t, from.Add(2*MaxWindow), units[5].From)
+	assert.Equal(t, "1/Hour/2021-01-01T00:00:00/2021-01-08T00:00:00", units[0].Key())
+
+	_, err = Plan([]int64{1}, archive.HourArchive, from, to, 8*24*time.Hour)
+
+	assert.Error(t, err)
+
+	_, err = Plan([]int64{1}, archive.HourArchive, to, from, time.Hour)
+
+	assert.Error(t, err)
+}
+
+func TestRun_Resume(t *testing.T) {
+	from := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 1, 29, 0, 0, 0, 0, time.UTC)
+
+	units, err := Plan([]int64{1, 2, 3}, archive.HourArchive, from, to, MaxWindow)
+
+	require.NoError(t, err)
+	require.Len(t, units, 12)
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	checkpoint, err := OpenCheckpoint(path)
+
+	require.NoError(t, err)
+
+	conn := &fakeConnection{failures: map[int64]int{2: 100}}
+	saved := make(map[int64]bool)
+
+	handler := func(_ context.Context, unit Unit, readings []parsers.Readings) error {
+		for _, r := range readings {
+			assert.Equal(t, unit.DeviceID, r.DeviceID.Int64)
+
+			saved[r.ID.Int64] = true
+		}
+
+		return nil
+	}
+
+	var last Progress
+
+	progress, err := Run(context.TODO(), conn, units, handler, WithCheckpoint(checkpoint), WithConcurrency(3),
+		WithRetries(1, time.Millisecond), WithProgress(func(p Progress) {
+			last = p
+		}))
+
+	require.Error(t, err)
+
+	var unitErr *UnitError
+
+	require.ErrorAs(t, err, &unitErr)
+	assert.Equal(t, int64(2), unitErr.Unit.DeviceID)
+
+	assert.Equal(t, Progress{Total: 12, Done: 8, Failed: 4, Readings: 8}, Progress{
+		Total: progress.Total, Done: progress.Done, Failed: progress.Failed, Readings: progress.Readings,
+	})
+	assert.Equal(t, *progress, last)
+	assert.False(t, last.Resumed)
+	assert.Len(t, conn.calls, 8+4*2)
+	assert.Contains(t, conn.calls, "1 2021-01-01T00:00:00 2021-01-07T23:59:59")
+	assert.Len(t, saved, 8)
+	require.NoError(t, checkpoint.Close())
+
+	conn = &fakeConnection{}
+
+	checkpoint, err = OpenCheckpoint(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, 8, checkpoint.Len())
+
+	progress, err = Run(context.TODO(), conn, units, handler, WithCheckpoint(checkpoint))
+
+	require.NoError(t, err)
+	assert.Equal(t, 12, progress.Done)
+	assert.Equal(t, 8, progress.Skipped)
+	assert.Equal(t, 4, progress.Readings)
+	assert.Len(t, conn.calls, 4)
+	assert.Len(t, saved, 12)
+
+	for _, call := range conn.calls {
+		assert.Equal(t, "2 ", call[:2])
+	}
+
+	require.NoError(t, checkpoint.Close())
+}
+
+func TestCheckpoint(t *testing.T) {
+	from := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(2 * MaxWindow)
+
+	units, err := Plan([]int64{1}, archive.HourArchive, from, to, MaxWindow)
+
+	require.NoError(t, err)
+	require.Len(t, units, 2)
+
+	path := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+
+	checkpoint, err := OpenCheckpoint(path)
+
+	require.NoError(t, err)
+
+	_, ok := checkpoint.End()
+
+	assert.False(t, ok)
+
+	require.NoError(t, checkpoint.SetEnd(to))
+	require.NoError(t, checkpoint.MarkDone(units[0], Done{Readings: 1}))
+	require.NoError(t, checkpoint.MarkDone(units[0], Done{Readings: 2}))
+	require.NoError(t, checkpoint.MarkDone(units[1], Done{Readings: 3}))
+	require.NoError(t, checkpoint.Close())
+
+	data, err := os.ReadFile(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, bytes.Count(data, []byte("\n")))
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+
+	require.NoError(t, err)
+
+	_, err = f.WriteString(`{"key": "1/Hour/`)
+
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	checkpoint, err = OpenCheckpoint(path)
+
+	require.NoError(t, err)
+
+	defer checkpoint.Close()
+
+	assert.Equal(t, 2, checkpoint.Len())
+
+	end, ok := checkpoint.End()
+
+	assert.True(t, ok)
+	assert.True(t, end.Equal(to))
+
+	done, ok := checkpoint.Done(units[0])
+
+	assert.True(t, ok)
+	assert.Equal(t, 2, done.Readings)
+
+	data, err = os.ReadFile(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, bytes.Count(data, []byte("\n")))
+	assert.True(t, bytes.HasSuffix(data, []byte("\n")))
+
+	require.NoError(t, os.WriteFile(path, []byte("{\"done\": {}}\nnot json\n"), 0o644))
+
+	_, err = OpenCheckpoint(path)
+
+	assert.Error(t, err)
+}
+
+func TestRun_Cancel(t *testing.T) {
+	from := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	units, err := Plan([]int64{1}, archive.HourArchive, from, from.Add(10*MaxWindow), MaxWindow)
+
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	handler := func(context.Context, Unit, []parsers.Readings) error {
+		cancel()
+		return nil
+	}
+
+	checkpoint, err := OpenCheckpoint("")
+
+	require.NoError(t, err)
+
+	_, err = Run(ctx, &fakeConnection{}, units, handler, WithCheckpoint(checkpoint), WithConcurrency(1))
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, checkpoint.Len(), len(units))
+}
@@ -0,0 +1,264 @@
+package backfill
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Done результат выполненной единицы работы
+type Done struct {
+	// Readings количество загруженных показаний
+	Readings int `json:"readings"`
+
+	// At момент завершения единицы работы
+	At time.Time `json:"at"`
+}
+
+// Checkpoint контрольная точка загрузки: выполненные единицы работы и конец периода загрузки. Контрольная точка
+// хранится в файле, каждая строка которого - запись формата JSON. Записи дописываются в конец файла, поэтому
+// сохранение выполненной единицы работы не зависит от их количества. При открытии контрольной точки повторяющиеся
+// записи и запись, оборванная аварийным завершением загрузки, удаляются из файла
+type Checkpoint struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+	done map[string]Done
+	end  time.Time
+}
+
+// checkpointRecord запись файла контрольной точки
+type checkpointRecord struct {
+	// Key ключ выполненной единицы работы
+	Key string `json:"key,omitempty"`
+
+	// Done результат выполненной единицы работы
+	Done *Done `json:"done,omitempty"`
+
+	// End конец периода загрузки
+	End *time.Time `json:"end,omitempty"`
+}
+
+// OpenCheckpoint открывает контрольную точку в файле path. Если файл не существует, контрольная точка пуста. Пустой
+// путь означает контрольную точку в памяти. Контрольная точка в файле закрывается методом Close
+func OpenCheckpoint(path string) (*Checkpoint, error) {
+	c := &Checkpoint{path: path, done: make(map[string]Done)}
+
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// последняя строка без перевода строки могла быть оборвана аварийным завершением загрузки
+	complete := data[:bytes.LastIndexByte(data, '\n')+1]
+
+	records, err := c.load(complete)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(complete) < len(data) || records > c.records() {
+		if err := c.compact(); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// load загружает строки файла контрольной точки data и возвращает количество записей
+func (c *Checkpoint) load(data []byte) (int, error) {
+	var records int
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var record checkpointRecord
+
+		if err := json.Unmarshal(line, &record); err != nil {
+			return 0, fmt.Errorf("checkpoint record %d: %w", records+1, err)
+		}
+
+		records++
+
+		if record.Key != "" && record.Done != nil {
+			c.done[record.Key] = *record.Done
+		}
+
+		if record.End != nil {
+			c.end = *record.End
+		}
+	}
+
+	return records, nil
+}
+
+// records возвращает количество записей компактного файла контрольной точки
+func (c *Checkpoint) records() int {
+	if c.end.IsZero() {
+		return len(c.done)
+	}
+
+	return len(c.done) + 1
+}
+
+// compact перезаписывает файл контрольной точки записями, по одной на выполненную единицу работы и конец периода,
+// записью во временный файл с последующим переименованием
+func (c *Checkpoint) compact() error {
+	var buf bytes.Buffer
+
+	e := json.NewEncoder(&buf)
+
+	if !c.end.IsZero() {
+		if err := e.Encode(checkpointRecord{End: &c.end}); err != nil {
+			return err
+		}
+	}
+
+	for key, done := range c.done {
+		done := done
+
+		if err := e.Encode(checkpointRecord{Key: key, Done: &done}); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// Done возвращает результат единицы работы u, если она выполнена
+func (c *Checkpoint) Done(u Unit) (Done, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	done, ok := c.done[u.Key()]
+
+	return done, ok
+}
+
+// Len возвращает количество выполненных единиц работы
+func (c *Checkpoint) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.done)
+}
+
+// MarkDone отмечает единицу работы u выполненной и сохраняет контрольную точку
+func (c *Checkpoint) MarkDone(u Unit, done Done) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := u.Key()
+
+	c.done[key] = done
+
+	return c.append(checkpointRecord{Key: key, Done: &done})
+}
+
+// End возвращает конец периода загрузки, сохраненный в контрольной точке
+func (c *Checkpoint) End() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.end, !c.end.IsZero()
+}
+
+// SetEnd сохраняет в контрольной точке конец периода загрузки to. Возобновленная загрузка с концом периода из
+// контрольной точки планирует те же единицы работы, что и прерванная
+func (c *Checkpoint) SetEnd(to time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if to.Equal(c.end) {
+		return nil
+	}
+
+	c.end = to
+
+	return c.append(checkpointRecord{End: &to})
+}
+
+// append дописывает запись record в конец файла контрольной точки
+func (c *Checkpoint) append(record checkpointRecord) error {
+	if c.path == "" {
+		return nil
+	}
+
+	if c.file == nil {
+		f, err := os.OpenFile(c.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+
+		if err != nil {
+			return err
+		}
+
+		c.file = f
+	}
+
+	data, err := json.Marshal(record)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	return c.file.Sync()
+}
+
+// Close закрывает файл контрольной точки
+func (c *Checkpoint) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		return nil
+	}
+
+	err := c.file.Close()
+	c.file = nil
+
+	return err
+}
@@ -0,0 +1,65 @@
+package backfill
+
+import (
+	"time"
+)
+
+type runOptions struct {
+	concurrency int
+	checkpoint  *Checkpoint
+	retries     int
+	backoff     time.Duration
+	progress    func(Progress)
+}
+
+func newRunOptions(opts []Option) *runOptions {
+	options := &runOptions{
+		concurrency: 4,
+		retries:     2,
+		backoff:     time.Second,
+	}
+
+	for _, option := range opts {
+		option(options)
+	}
+
+	if options.concurrency < 1 {
+		options.concurrency = 1
+	}
+
+	return options
+}
+
+// Option опция загрузки показаний
+type Option func(options *runOptions)
+
+// WithConcurrency устанавливает количество одновременно выполняемых единиц работы. По умолчанию 4
+func WithConcurrency(n int) Option {
+	return func(options *runOptions) {
+		options.concurrency = n
+	}
+}
+
+// WithCheckpoint устанавливает контрольную точку загрузки. Выполненные единицы работы контрольной точки
+// пропускаются, выполненные при загрузке - отмечаются в контрольной точке
+func WithCheckpoint(checkpoint *Checkpoint) Option {
+	return func(options *runOptions) {
+		options.checkpoint = checkpoint
+	}
+}
+
+// WithRetries устанавливает количество повторов неудачного запроса показаний и паузу перед первым повтором,
+// увеличивающуюся с каждым повтором. По умолчанию 2 повтора с паузой 1 с
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(options *runOptions) {
+		options.retries = retries
+		options.backoff = backoff
+	}
+}
+
+// WithProgress устанавливает функцию, вызываемую после завершения каждой единицы работы
+func WithProgress(progress func(Progress)) Option {
+	return func(options *runOptions) {
+		options.progress = progress
+	}
+}
@@ -0,0 +1,77 @@
+package backfill
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+)
+
+// MaxWindow наибольший период показаний, запрашиваемых у API Каскада одним запросом
+const MaxWindow = 7 * 24 * time.Hour
+
+// unitTimeLayout формат моментов времени в ключе единицы работы
+const unitTimeLayout = "2006-01-02T15:04:05"
+
+// Unit единица работы: загрузка показаний архива прибора учета за период [From, To)
+type Unit struct {
+	// DeviceID идентификатор прибора учета
+	DeviceID int64
+
+	// Archive тип архива показаний
+	Archive archive.DataArchive
+
+	// From начало периода по часам сервера Каскада
+	From time.Time
+
+	// To окончание периода по часам сервера Каскада (не включается в период)
+	To time.Time
+}
+
+// Key возвращает ключ единицы работы в файле контрольной точки. Ключ не зависит от часового пояса моментов
+// времени периода
+func (u Unit) Key() string {
+	return fmt.Sprintf("%d/%s/%s/%s", u.DeviceID, u.Archive, u.From.Format(unitTimeLayout),
+		u.To.Format(unitTimeLayout))
+}
+
+// String возвращает описание единицы работы
+func (u Unit) String() string {
+	return u.Key()
+}
+
+// Plan разбивает загрузку показаний архива a приборов учета devices за период [from, to) на единицы работы с
+// периодом не более window. Периоды единиц работы отсчитываются от from, период последней единицы работы
+// заканчивается в to. Единицы работы упорядочены по периоду, затем по прибору учета
+func Plan(devices []int64, a archive.DataArchive, from, to time.Time, window time.Duration) ([]Unit, error) {
+	if window <= 0 || window > MaxWindow {
+		return nil, fmt.Errorf("window must be in (0, %s], got %s", MaxWindow, window)
+	}
+
+	if !from.Before(to) {
+		return nil, fmt.Errorf("beginning of the period %s is not before its end %s", from, to)
+	}
+
+	ids := append([]int64(nil), devices...)
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	units := make([]Unit, 0)
+
+	for begin := from; begin.Before(to); begin = begin.Add(window) {
+		end := begin.Add(window)
+
+		if end.After(to) {
+			end = to
+		}
+
+		for _, id := range ids {
+			units = append(units, Unit{DeviceID: id, Archive: a, From: begin, To: end})
+		}
+	}
+
+	return units, nil
+}
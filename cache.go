@@ -0,0 +1,315 @@
+package cascade
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL срок актуальности кэшированного списка приборов учета по умолчанию
+const DefaultCacheTTL = time.Hour
+
+// CachedGauges кэшированный список приборов учета
+type CachedGauges struct {
+	// URL адрес метода получения списка приборов учета
+	URL string `json:"url"`
+
+	// User имя пользователя, получившего список. Списки приборов учета разных пользователей могут различаться
+	User string `json:"user"`
+
+	// Data ответ метода получения списка приборов учета
+	Data []byte `json:"data"`
+
+	// ETag, LastModified значения заголовков ETag и Last-Modified ответа. Используются для условного запроса списка
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+
+	// FetchedAt момент получения или последней проверки списка. Нулевое значение означает, что список устарел
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// clone возвращает копию кэшированного списка
+func (c *CachedGauges) clone() *CachedGauges {
+	if c == nil {
+		return nil
+	}
+
+	clone := *c
+	clone.Data = bytes.Clone(c.Data)
+
+	return &clone
+}
+
+// GaugesStore хранилище кэшированного списка приборов учета
+type GaugesStore interface {
+	// Load возвращает кэшированный список или nil, если список не сохранен
+	Load(ctx context.Context) (*CachedGauges, error)
+
+	// Save сохраняет список
+	Save(ctx context.Context, gauges *CachedGauges) error
+
+	// Delete удаляет сохраненный список
+	Delete(ctx context.Context) error
+}
+
+// NewMemoryGaugesStore возвращает хранилище списка приборов учета в памяти
+func NewMemoryGaugesStore() GaugesStore {
+	return &memoryGaugesStore{}
+}
+
+type memoryGaugesStore struct {
+	mu     sync.Mutex
+	gauges *CachedGauges
+}
+
+func (s *memoryGaugesStore) Load(ctx context.Context) (*CachedGauges, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.gauges.clone(), nil
+}
+
+func (s *memoryGaugesStore) Save(ctx context.Context, gauges *CachedGauges) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gauges = gauges.clone()
+
+	return nil
+}
+
+func (s *memoryGaugesStore) Delete(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gauges = nil
+
+	return nil
+}
+
+// NewFileGaugesStore возвращает хранилище списка приборов учета в файле path формата JSON. Файл сохраняется записью
+// во временный файл с последующим переименованием, поэтому аварийное завершение не повреждает его
+func NewFileGaugesStore(path string) GaugesStore {
+	return &fileGaugesStore{path: path}
+}
+
+type fileGaugesStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (s *fileGaugesStore) Load(ctx context.Context) (*CachedGauges, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var gauges CachedGauges
+
+	if err := json.Unmarshal(data, &gauges); err != nil {
+		return nil, err
+	}
+
+	return &gauges, nil
+}
+
+func (s *fileGaugesStore) Save(ctx context.Context, gauges *CachedGauges) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(gauges)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *fileGaugesStore) Delete(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// GaugesCache кэш списка приборов учета, возвращаемого методом Gauges соединения (см. WithGaugesCache). Список
+// возвращается из кэша в течение срока актуальности. По истечении срока список запрашивается повторно, условным
+// запросом, если сервер передал заголовки ETag или Last-Modified. Кэш хранит список одного пользователя одного
+// сервера: список, полученный для другого сервера или пользователя, запрашивается заново и заменяет кэшированный.
+// Один кэш может использоваться несколькими соединениями одного пользователя с одним сервером, соединениям разных
+// пользователей следует использовать разные кэши.
+//
+// Ошибки чтения и сохранения кэша не прерывают получение списка: при ошибке чтения список запрашивается у сервера,
+// ошибка сохранения игнорируется
+type GaugesCache struct {
+	store   GaugesStore
+	options *cacheOptions
+	mu      sync.Mutex
+}
+
+// NewGaugesCache возвращает кэш списка приборов учета в хранилище store
+func NewGaugesCache(store GaugesStore, options ...CacheOption) *GaugesCache {
+	opts := &cacheOptions{
+		ttl: DefaultCacheTTL,
+	}
+
+	for _, option := range options {
+		option(opts)
+	}
+
+	return &GaugesCache{
+		store:   store,
+		options: opts,
+	}
+}
+
+// Invalidate отмечает кэшированный список устаревшим. Следующий вызов Gauges запрашивает список безусловно,
+// при этом кэшированный список используется для определения изменений
+func (c *GaugesCache) Invalidate(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, err := c.store.Load(ctx)
+
+	if err != nil || cached == nil {
+		return err
+	}
+
+	cached.ETag, cached.LastModified, cached.FetchedAt = "", "", time.Time{}
+
+	return c.store.Save(ctx, cached)
+}
+
+// Clear удаляет кэшированный список
+func (c *GaugesCache) Clear(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.store.Delete(ctx)
+}
+
+// fetchFunc запрашивает список приборов учета по адресу methodURL условным запросом по кэшированному списку cached
+type fetchFunc func(ctx context.Context, methodURL string, cached *CachedGauges) (*gaugesResponse, error)
+
+// gauges возвращает список приборов учета пользователя user по адресу methodURL из кэша или, если список устарел,
+// запрашивает его функцией fetch
+func (c *GaugesCache) gauges(ctx context.Context, methodURL, user string, fetch fetchFunc) ([]byte, error) {
+	previous, current, err := c.load(ctx, methodURL, user, fetch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if previous != nil && c.options.onChanged != nil && !bytes.Equal(previous, current) {
+		c.options.onChanged(ctx, previous, current)
+	}
+
+	return bytes.Clone(current), nil
+}
+
+// load возвращает кэшированный список previous, если список был запрошен повторно, и актуальный список current
+func (c *GaugesCache) load(ctx context.Context, methodURL, user string, fetch fetchFunc) (previous,
+	current []byte, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, err := c.store.Load(ctx)
+
+	// список другого сервера или пользователя или ошибка чтения кэша означают отсутствие списка в кэше
+	if err != nil || (cached != nil && (cached.URL != methodURL || cached.User != user)) {
+		cached = nil
+	}
+
+	now := time.Now()
+
+	if cached != nil && !cached.FetchedAt.IsZero() && now.Sub(cached.FetchedAt) < c.options.ttl {
+		return nil, cached.Data, nil
+	}
+
+	resp, err := fetch(ctx, methodURL, cached)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.notModified && cached != nil {
+		cached.FetchedAt = now
+
+		if resp.etag != "" {
+			cached.ETag = resp.etag
+		}
+
+		if resp.lastModified != "" {
+			cached.LastModified = resp.lastModified
+		}
+
+		_ = c.store.Save(ctx, cached)
+
+		return nil, cached.Data, nil
+	}
+
+	if resp.notModified {
+		return nil, nil, fmt.Errorf("GET %s: unexpected response %d", methodGauges, http.StatusNotModified)
+	}
+
+	_ = c.store.Save(ctx, &CachedGauges{
+		URL:          methodURL,
+		User:         user,
+		Data:         resp.data,
+		ETag:         resp.etag,
+		LastModified: resp.lastModified,
+		FetchedAt:    now,
+	})
+
+	if cached != nil {
+		previous = cached.Data
+	}
+
+	return previous, resp.data, nil
+}
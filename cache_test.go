@@ -0,0 +1,183 @@
+package cascade_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cascade "github.com/vitpelekhaty/go-cascade-client/v2"
+	"github.com/vitpelekhaty/go-cascade-client/v2/cascadetest"
+)
+
+func openCached(t *testing.T, server *cascadetest.Server, cache *cascade.GaugesCache) cascade.IConnection {
+	conn, err := cascade.NewConnection(cascade.WithHTTPClient(server.Client()), cascade.WithGaugesCache(cache))
+
+	require.NoError(t, err)
+
+	err = conn.Open(context.TODO(), server.URL, cascadetest.DefaultUsername, cascadetest.DefaultPassword,
+		cascade.WithAuthURL(server.AuthURL()))
+
+	require.NoError(t, err)
+
+	return conn
+}
+
+// gaugesStatuses возвращает коды ответов на запросы списка приборов учета
+func gaugesStatuses(server *cascadetest.Server) []int {
+	var statuses []int
+
+	for _, request := range server.Requests() {
+		if request.Path == cascadetest.PathGauges {
+			statuses = append(statuses, request.StatusCode)
+		}
+	}
+
+	return statuses
+}
+
+func TestGaugesCache_TTL(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "responses", "counterHouse.json"))
+
+	require.NoError(t, err)
+
+	server := cascadetest.NewServer(cascadetest.WithGauges(data))
+
+	defer server.Close()
+
+	conn := openCached(t, server, cascade.NewGaugesCache(cascade.NewMemoryGaugesStore()))
+
+	for i := 0; i < 3; i++ {
+		gauges, err := conn.Gauges(context.TODO())
+
+		require.NoError(t, err)
+
+		assert.Equal(t, data, gauges)
+	}
+
+	assert.Equal(t, []int{http.StatusOK}, gaugesStatuses(server))
+}
+
+func TestGaugesCache_Revalidate(t *testing.T) {
+	server := cascadetest.NewServer(cascadetest.WithGauges([]byte(`[{"id": 1}]`)),
+		cascadetest.WithConditionalGauges())
+
+	defer server.Close()
+
+	var changes [][2]string
+
+	cache := cascade.NewGaugesCache(cascade.NewMemoryGaugesStore(), cascade.WithCacheTTL(0),
+		cascade.WithGaugesChanged(func(ctx context.Context, previous, current []byte) {
+			changes = append(changes, [2]string{string(previous), string(current)})
+		}))
+
+	conn := openCached(t, server, cache)
+
+	for i := 0; i < 2; i++ {
+		gauges, err := conn.Gauges(context.TODO())
+
+		require.NoError(t, err)
+
+		assert.Equal(t, `[{"id": 1}]`, string(gauges))
+	}
+
+	assert.Equal(t, []int{http.StatusOK, http.StatusNotModified}, gaugesStatuses(server))
+
+	assert.Empty(t, changes)
+
+	server.SetGauges([]byte(`[{"id": 1}, {"id": 2}]`))
+
+	gauges, err := conn.Gauges(context.TODO())
+
+	require.NoError(t, err)
+
+	assert.Equal(t, `[{"id": 1}, {"id": 2}]`, string(gauges))
+
+	assert.Equal(t, [][2]string{{`[{"id": 1}]`, `[{"id": 1}, {"id": 2}]`}}, changes)
+}
+
+func TestGaugesCache_FileStore(t *testing.T) {
+	server := cascadetest.NewServer(cascadetest.WithGauges([]byte(`[{"id": 1}]`)),
+		cascadetest.WithConditionalGauges())
+
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cache", "gauges.json")
+
+	_, err := openCached(t, server, cascade.NewGaugesCache(cascade.NewFileGaugesStore(path))).Gauges(context.TODO())
+
+	require.NoError(t, err)
+
+	var changed int
+
+	cache := cascade.NewGaugesCache(cascade.NewFileGaugesStore(path),
+		cascade.WithGaugesChanged(func(ctx context.Context, previous, current []byte) {
+			changed++
+		}))
+
+	conn := openCached(t, server, cache)
+
+	gauges, err := conn.Gauges(context.TODO())
+
+	require.NoError(t, err)
+
+	assert.Equal(t, `[{"id": 1}]`, string(gauges))
+
+	assert.Equal(t, []int{http.StatusOK}, gaugesStatuses(server))
+
+	require.NoError(t, cache.Invalidate(context.TODO()))
+
+	gauges, err = conn.Gauges(context.TODO())
+
+	require.NoError(t, err)
+
+	assert.Equal(t, `[{"id": 1}]`, string(gauges))
+
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK}, gaugesStatuses(server))
+
+	assert.Zero(t, changed)
+
+	require.NoError(t, cache.Clear(context.TODO()))
+
+	_, err = os.Stat(path)
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestGaugesCache_Users(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			user, _, _ := r.BasicAuth()
+
+			_, _ = fmt.Fprintf(w, `{"access_token": %q, "token_type": "bearer"}`, user)
+
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `[{"title": %q}]`, strings.TrimPrefix(r.Header.Get("Authorization"), "bearer "))
+	}))
+
+	defer server.Close()
+
+	cache := cascade.NewGaugesCache(cascade.NewMemoryGaugesStore())
+
+	for _, user := range []string{"alice", "bob", "bob"} {
+		conn, err := cascade.NewConnection(cascade.WithHTTPClient(server.Client()), cascade.WithGaugesCache(cache))
+
+		require.NoError(t, err)
+		require.NoError(t, conn.Open(context.TODO(), server.URL, user, "password"))
+
+		gauges, err := conn.Gauges(context.TODO())
+
+		require.NoError(t, err)
+
+		assert.Equal(t, fmt.Sprintf(`[{"title": %q}]`, user), string(gauges))
+	}
+}
@@ -0,0 +1,92 @@
+package cascadetest
+
+import (
+	"time"
+)
+
+type serverOptions struct {
+	username  string
+	password  string
+	gauges    []byte
+	readings  [][]byte
+	maxPeriod time.Duration
+	tokenTTL  time.Duration
+	latency   time.Duration
+
+	conditionalGauges bool
+}
+
+func newServerOptions(opts []Option) *serverOptions {
+	options := &serverOptions{
+		username:  DefaultUsername,
+		password:  DefaultPassword,
+		gauges:    []byte("[]"),
+		maxPeriod: MaxPeriod,
+	}
+
+	for _, option := range opts {
+		option(options)
+	}
+
+	return options
+}
+
+// Option опция тестового сервера
+type Option func(options *serverOptions)
+
+// WithCredentials устанавливает имя и пароль пользователя. По умолчанию DefaultUsername и DefaultPassword
+func WithCredentials(username, password string) Option {
+	return func(options *serverOptions) {
+		options.username = username
+		options.password = password
+	}
+}
+
+// WithGauges устанавливает ответ метода получения списка приборов учета (например, содержимое файла
+// testdata/responses/counterHouse.json). По умолчанию пустой список
+func WithGauges(data []byte) Option {
+	return func(options *serverOptions) {
+		options.gauges = data
+	}
+}
+
+// WithConditionalGauges включает поддержку условных запросов списка приборов учета: ответ содержит заголовки ETag
+// и Last-Modified, а запрос с совпадающим If-None-Match или If-Modified-Since завершается ответом 304. По
+// умолчанию условные запросы не поддерживаются
+func WithConditionalGauges() Option {
+	return func(options *serverOptions) {
+		options.conditionalGauges = true
+	}
+}
+
+// WithReadings добавляет показания в формате ответа метода чтения архива показаний (например, содержимое файла
+// testdata/responses/readings200.json). Методы чтения показаний возвращают показания, отобранные по параметрам
+// запроса
+func WithReadings(data []byte) Option {
+	return func(options *serverOptions) {
+		options.readings = append(options.readings, data)
+	}
+}
+
+// WithMaxPeriod устанавливает наибольший период запроса показаний. Запрос показаний за больший период завершается
+// ошибкой 422. По умолчанию MaxPeriod
+func WithMaxPeriod(period time.Duration) Option {
+	return func(options *serverOptions) {
+		options.maxPeriod = period
+	}
+}
+
+// WithTokenTTL устанавливает срок действия токена сессии. По истечении срока запросы с токеном завершаются ошибкой
+// 401. По умолчанию срок действия не ограничен (см. также Server.ExpireTokens)
+func WithTokenTTL(ttl time.Duration) Option {
+	return func(options *serverOptions) {
+		options.tokenTTL = ttl
+	}
+}
+
+// WithLatency устанавливает задержку ответов сервера (см. также Server.SetLatency)
+func WithLatency(latency time.Duration) Option {
+	return func(options *serverOptions) {
+		options.latency = latency
+	}
+}
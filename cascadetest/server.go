@@ -0,0 +1,473 @@
+package cascadetest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// Параметры тестового сервера по умолчанию
+const (
+	// DefaultUsername имя пользователя
+	DefaultUsername = "user"
+	// DefaultPassword пароль пользователя
+	DefaultPassword = "password"
+	// MaxPeriod наибольший период запроса показаний
+	MaxPeriod = 7 * 24 * time.Hour
+)
+
+// Пути методов API
+const (
+	// AuthPath путь альтернативного метода авторизации. Авторизация также выполняется запросом POST по корневому
+	// адресу сервера
+	AuthPath = "/oauth/token"
+	// PathGauges путь метода получения списка приборов учета
+	PathGauges = "/api/cascade/counter-house"
+	// PathReadings путь метода чтения архива показаний
+	PathReadings = "/api/cascade/counter-house/reading"
+	// PathAlteredReadings путь метода чтения архива измененных показаний
+	PathAlteredReadings = "/api/cascade/counter-house/reading/created"
+)
+
+// requestTimeLayout формат времени в запросах к API Каскада
+const requestTimeLayout = "02.01.2006 15:04:05"
+
+// periodLimitDescription описание ошибки превышения периода запроса показаний
+const periodLimitDescription = "Получение статистики расходов более чем за 7 дней временно недоступно"
+
+// Request запрос к тестовому серверу
+type Request struct {
+	// Method метод HTTP
+	Method string
+
+	// Path путь метода API
+	Path string
+
+	// Body тело запроса
+	Body []byte
+
+	// StatusCode код ответа
+	StatusCode int
+}
+
+// fault сбой метода API
+type fault struct {
+	status int
+	times  int
+}
+
+// Server тестовый сервер API Каскада на основе httptest.Server. Сервер реализует авторизацию и методы получения
+// списка приборов учета и чтения архивов показаний по данным, заданным опциями, и позволяет имитировать
+// истечение срока действия токена (401), превышение периода запроса показаний (422), медленные ответы и сбои
+// сервера
+type Server struct {
+	*httptest.Server
+
+	options  *serverOptions
+	readings []parsers.Readings
+
+	mu       sync.Mutex
+	tokens   map[string]time.Time
+	issued   int
+	latency  time.Duration
+	faults   map[string]*fault
+	requests []Request
+
+	gauges         []byte
+	gaugesModified time.Time
+}
+
+// NewServer запускает тестовый сервер. Сервер останавливается вызовом Close. Если данные показаний, заданные
+// опцией WithReadings, не соответствуют формату ответа API, вызывается panic
+func NewServer(opts ...Option) *Server {
+	options := newServerOptions(opts)
+
+	s := &Server{
+		options: options,
+		tokens:  make(map[string]time.Time),
+		latency: options.latency,
+		faults:  make(map[string]*fault),
+		gauges:  options.gauges,
+
+		gaugesModified: time.Now().UTC().Truncate(time.Second),
+	}
+
+	for _, data := range options.readings {
+		var readings []parsers.Readings
+
+		if err := json.Unmarshal(data, &readings); err != nil {
+			panic(fmt.Sprintf("cascadetest: invalid readings fixture: %v", err))
+		}
+
+		s.readings = append(s.readings, readings...)
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", s.handleLogin)
+	mux.HandleFunc(AuthPath, s.handleLogin)
+	mux.HandleFunc(PathGauges, s.authorized(s.handleGauges))
+	mux.HandleFunc(PathReadings, s.authorized(s.handleReadings(false)))
+	mux.HandleFunc(PathAlteredReadings, s.authorized(s.handleReadings(true)))
+
+	s.Server = httptest.NewServer(s.middleware(mux))
+
+	return s
+}
+
+// AuthURL возвращает адрес альтернативного метода авторизации
+func (s *Server) AuthURL() string {
+	return s.URL + AuthPath
+}
+
+// ExpireTokens прекращает действие выданных токенов сессии. Следующие запросы с этими токенами завершаются
+// ошибкой 401
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens = make(map[string]time.Time)
+}
+
+// SetLatency устанавливает задержку ответов сервера
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = latency
+}
+
+// Fail завершает следующие times запросов к методу API path ошибкой с кодом status (например,
+// http.StatusInternalServerError)
+func (s *Server) Fail(path string, status int, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults[path] = &fault{status: status, times: times}
+}
+
+// SetGauges заменяет ответ метода получения списка приборов учета
+func (s *Server) SetGauges(data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gauges = data
+
+	// Last-Modified передается с точностью до секунды, поэтому момент изменения всегда увеличивается
+	modified := time.Now().UTC().Truncate(time.Second)
+
+	if !modified.After(s.gaugesModified) {
+		modified = s.gaugesModified.Add(time.Second)
+	}
+
+	s.gaugesModified = modified
+}
+
+// Requests возвращает запросы к серверу в порядке поступления
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// statusRecorder сохраняет код ответа
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader реализация метода http.ResponseWriter.WriteHeader
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// middleware регистрирует запросы, задерживает ответы и имитирует сбои методов API
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		defer func() {
+			s.mu.Lock()
+			s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Body: body,
+				StatusCode: rec.status})
+			s.mu.Unlock()
+		}()
+
+		s.mu.Lock()
+
+		latency := s.latency
+		status := 0
+
+		if f, ok := s.faults[r.URL.Path]; ok && f.times > 0 {
+			f.times--
+			status = f.status
+		}
+
+		s.mu.Unlock()
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if status != 0 {
+			writeError(rec, status, "simulated failure")
+			return
+		}
+
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// handleLogin выдает токен сессии по имени и паролю пользователя
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" && r.URL.Path != AuthPath {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	secret := base64.StdEncoding.EncodeToString([]byte(s.options.username + ":" + s.options.password))
+
+	if r.Header.Get("Authorization") != "Basic "+secret {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+		writeError(w, http.StatusBadRequest, "unsupported grant type")
+		return
+	}
+
+	s.mu.Lock()
+
+	s.issued++
+	value := fmt.Sprintf("token-%d", s.issued)
+	s.tokens[value] = time.Now()
+
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": value,
+		"token_type":   "bearer",
+		"expires_in":   int64(s.options.tokenTTL / time.Second),
+		"scope":        "trust",
+		"userid":       1,
+		"login":        s.options.username,
+		"name":         "cascadetest",
+		"server_type":  "Test",
+	})
+}
+
+// authorized проверяет токен сессии запроса
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") || !s.valid(parts[1]) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// valid проверяет действие токена сессии
+func (s *Server) valid(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issued, ok := s.tokens[token]
+
+	if !ok {
+		return false
+	}
+
+	if s.options.tokenTTL > 0 && time.Since(issued) > s.options.tokenTTL {
+		delete(s.tokens, token)
+		return false
+	}
+
+	return true
+}
+
+// handleGauges возвращает список приборов учета
+func (s *Server) handleGauges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	data, modified := s.gauges, s.gaugesModified
+	s.mu.Unlock()
+
+	if s.options.conditionalGauges {
+		etag := fmt.Sprintf(`"%x"`, sha256.Sum256(data))
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+
+		if notModified(r, etag, modified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = w.Write(data)
+}
+
+// notModified возвращает признак того, что данные с версией etag и моментом изменения modified не изменились
+// относительно версии, указанной в условном запросе r
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			if tag = strings.TrimSpace(tag); tag == etag || tag == "*" {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+
+	return err == nil && !modified.After(since)
+}
+
+// readingsRequest запрос чтения архива показаний или архива измененных показаний
+type readingsRequest struct {
+	DeviceID      int64               `json:"deviceId"`
+	InputNum      int64               `json:"inputNum"`
+	Archive       archive.DataArchive `json:"archiveType"`
+	BeginAt       string              `json:"beginAt"`
+	EndAt         string              `json:"endAt"`
+	BeginCreateAt string              `json:"beginCreateAt"`
+	EndCreateAt   string              `json:"endCreateAt"`
+}
+
+// handleReadings возвращает показания, отобранные по моменту показания или, если altered, по моменту чтения
+// показания
+func (s *Server) handleReadings(altered bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req readingsRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		begin, end := req.BeginAt, req.EndAt
+
+		if altered {
+			begin, end = req.BeginCreateAt, req.EndCreateAt
+		}
+
+		from, err := time.Parse(requestTimeLayout, begin)
+
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		to, err := time.Parse(requestTimeLayout, end)
+
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if to.Sub(from) > s.options.maxPeriod {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+				"exception":        "ru.bicenter.core.exception.domain.http.HttpDomainException",
+				"message":          "Domain exception has occurred",
+				"description":      periodLimitDescription,
+				"nestedExceptions": []string{},
+				"status":           "UNPROCESSABLE_ENTITY",
+			})
+
+			return
+		}
+
+		result := make([]parsers.Readings, 0)
+
+		for _, reading := range s.readings {
+			if reading.DeviceID.Int64 != req.DeviceID || reading.Archive != req.Archive {
+				continue
+			}
+
+			if req.InputNum > 0 && reading.Input.Int64 != req.InputNum {
+				continue
+			}
+
+			moment := reading.DT.Time()
+
+			if altered {
+				moment = reading.CreateAt.Time()
+			}
+
+			if moment.Before(from) || moment.After(to) {
+				continue
+			}
+
+			result = append(result, reading)
+		}
+
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// writeJSON выводит ответ в формате JSON
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, _ = w.Write(data)
+}
+
+// writeError выводит сообщение об ошибке в формате ответа API Каскада
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error":   http.StatusText(status),
+		"status":  strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_")),
+		"message": message,
+	})
+}
@@ -0,0 +1,192 @@
+package cascadetest_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cascade "github.com/vitpelekhaty/go-cascade-client/v2"
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/cascadetest"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+func loadResponse(t *testing.T, name string) []byte {
+	path, err := filepath.Abs(filepath.Join("../testdata/responses", name))
+
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path)
+
+	require.NoError(t, err)
+
+	return data
+}
+
+func newServer(t *testing.T, opts ...cascadetest.Option) *cascadetest.Server {
+	opts = append([]cascadetest.Option{
+		cascadetest.WithGauges(loadResponse(t, "counterHouse.json")),
+		cascadetest.WithReadings(loadResponse(t, "readings200.json")),
+	}, opts...)
+
+	server := cascadetest.NewServer(opts...)
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func connect(t *testing.T, server *cascadetest.Server, client *http.Client) cascade.IConnection {
+	if client == nil {
+		client = server.Client()
+	}
+
+	conn, err := cascade.NewConnection(cascade.WithHTTPClient(client))
+
+	require.NoError(t, err)
+
+	err = conn.Open(context.TODO(), server.URL, cascadetest.DefaultUsername, cascadetest.DefaultPassword,
+		cascade.WithAuthURL(server.AuthURL()))
+
+	require.NoError(t, err)
+
+	return conn
+}
+
+func countReadings(t *testing.T, data []byte) int {
+	items, err := parsers.ParseReadings(context.TODO(), data, parsers.WithStrict())
+
+	require.NoError(t, err)
+
+	var count int
+
+	for item := range items {
+		require.NoError(t, item.E)
+
+		count++
+	}
+
+	return count
+}
+
+func TestServer_Login(t *testing.T) {
+	server := newServer(t)
+
+	conn, err := cascade.NewConnection(cascade.WithHTTPClient(server.Client()))
+
+	require.NoError(t, err)
+
+	err = conn.Open(context.TODO(), server.URL, "user", "wrong")
+
+	assert.Error(t, err)
+	assert.False(t, conn.Connected())
+
+	err = conn.Open(context.TODO(), server.URL, cascadetest.DefaultUsername, cascadetest.DefaultPassword)
+
+	require.NoError(t, err)
+	assert.True(t, conn.Connected())
+}
+
+func TestServer_Readings(t *testing.T) {
+	server := newServer(t)
+	conn := connect(t, server, nil)
+
+	gauges, err := conn.Gauges(context.TODO())
+
+	require.NoError(t, err)
+	assert.Equal(t, loadResponse(t, "counterHouse.json"), gauges)
+
+	from := time.Date(2021, 4, 11, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 4, 11, 23, 59, 59, 0, time.UTC)
+
+	data, err := conn.CurrentReadings(context.TODO(), 12032, archive.HourArchive, from, to)
+
+	require.NoError(t, err)
+	assert.Equal(t, 23*3, countReadings(t, data))
+
+	data, err = conn.CurrentReadings(context.TODO(), 12032, archive.DailyArchive, from, to)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, countReadings(t, data))
+
+	data, err = conn.CurrentReadings(context.TODO(), 12032, archive.HourArchive, from, to, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, countReadings(t, data))
+
+	data, err = conn.AlteredReadings(context.TODO(), 12032, archive.HourArchive,
+		time.Date(2021, 4, 13, 0, 0, 0, 0, time.UTC), time.Date(2021, 4, 14, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	assert.NotZero(t, countReadings(t, data))
+
+	_, err = conn.CurrentReadings(context.TODO(), 12032, archive.HourArchive, from, from.Add(8*24*time.Hour))
+
+	var e *cascade.Error
+
+	require.ErrorAs(t, err, &e)
+	assert.Equal(t, http.StatusUnprocessableEntity, e.StatusCode())
+	assert.Equal(t, "UNPROCESSABLE_ENTITY", e.ExceptionStatus())
+}
+
+func TestServer_Faults(t *testing.T) {
+	server := newServer(t)
+	conn := connect(t, server, nil)
+
+	server.ExpireTokens()
+
+	_, err := conn.Gauges(context.TODO())
+
+	require.NoError(t, err)
+
+	requests := server.Requests()
+
+	require.Len(t, requests, 4)
+	assert.Equal(t, http.StatusUnauthorized, requests[1].StatusCode)
+	assert.Equal(t, cascadetest.AuthPath, requests[2].Path)
+
+	server.Fail(cascadetest.PathReadings, http.StatusServiceUnavailable, 1)
+
+	from := time.Date(2021, 4, 11, 0, 0, 0, 0, time.UTC)
+
+	_, err = conn.CurrentReadings(context.TODO(), 12032, archive.HourArchive, from, from.Add(time.Hour))
+
+	var e *cascade.Error
+
+	require.ErrorAs(t, err, &e)
+	assert.Equal(t, http.StatusServiceUnavailable, e.StatusCode())
+
+	_, err = conn.CurrentReadings(context.TODO(), 12032, archive.HourArchive, from, from.Add(time.Hour))
+
+	require.NoError(t, err)
+
+	server.SetLatency(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = conn.Gauges(ctx)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+}
+
+func TestServer_TokenTTL(t *testing.T) {
+	server := newServer(t, cascadetest.WithTokenTTL(100*time.Millisecond))
+	conn := connect(t, server, nil)
+
+	time.Sleep(150 * time.Millisecond)
+
+	from := time.Date(2021, 4, 11, 0, 0, 0, 0, time.UTC)
+
+	data, err := conn.CurrentReadings(context.TODO(), 12032, archive.HourArchive, from, from.Add(time.Hour))
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, countReadings(t, data))
+}
@@ -0,0 +1,126 @@
+package cassette
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Version версия формата кассеты
+const Version = 1
+
+// Cassette записанные обмены с сервером
+type Cassette struct {
+	// Version версия формата кассеты
+	Version int `json:"version"`
+
+	// RecordedAt время начала записи
+	RecordedAt time.Time `json:"recordedAt"`
+
+	// Meta произвольные параметры сценария, сохраненные вместе с записью
+	Meta map[string]string `json:"meta,omitempty"`
+
+	// Interactions обмены с сервером в порядке их выполнения
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction обмен с сервером: запрос и ответ на него
+type Interaction struct {
+	// Request запрос
+	Request Request `json:"request"`
+
+	// Response ответ
+	Response Response `json:"response"`
+}
+
+// Request записанный запрос
+type Request struct {
+	// Method метод HTTP
+	Method string `json:"method"`
+
+	// URL адрес запроса
+	URL string `json:"url"`
+
+	// Header заголовки запроса
+	Header http.Header `json:"header,omitempty"`
+
+	// Body тело запроса
+	Body string `json:"body,omitempty"`
+}
+
+// Response записанный ответ
+type Response struct {
+	// StatusCode код ответа
+	StatusCode int `json:"status"`
+
+	// Header заголовки ответа
+	Header http.Header `json:"header,omitempty"`
+
+	// Body тело ответа
+	Body string `json:"body,omitempty"`
+}
+
+// Load загружает кассету из файла path
+func Load(path string) (*Cassette, error) {
+	b, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var c Cassette
+
+	if err = json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("cassette %s: %v", path, err)
+	}
+
+	if c.Version != Version {
+		return nil, fmt.Errorf("cassette %s: unsupported version %d", path, c.Version)
+	}
+
+	return &c, nil
+}
+
+// Save сохраняет кассету в файл path. Каталог файла создается при необходимости, файл заменяется атомарно
+func (c *Cassette) Save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".*")
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	if err = f.Chmod(0o644); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if _, err = f.Write(append(b, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,50 @@
+package cassette
+
+import (
+	"net/http"
+)
+
+type recorderOptions struct {
+	transport http.RoundTripper
+	matcher   Matcher
+	scrubbers []Scrubber
+}
+
+func newRecorderOptions(opts []Option) *recorderOptions {
+	options := &recorderOptions{
+		transport: http.DefaultTransport,
+		matcher:   DefaultMatcher,
+	}
+
+	for _, option := range opts {
+		option(options)
+	}
+
+	return options
+}
+
+// Option опция записи и воспроизведения
+type Option func(options *recorderOptions)
+
+// WithTransport устанавливает транспорт, которому в режиме записи передаются запросы. По умолчанию
+// http.DefaultTransport
+func WithTransport(transport http.RoundTripper) Option {
+	return func(options *recorderOptions) {
+		options.transport = transport
+	}
+}
+
+// WithMatcher устанавливает функцию сопоставления запросов записанным обменам. По умолчанию DefaultMatcher
+func WithMatcher(matcher Matcher) Option {
+	return func(options *recorderOptions) {
+		options.matcher = matcher
+	}
+}
+
+// WithScrubber добавляет функцию удаления конфиденциальных сведений. Функции вызываются перед сохранением каждого
+// обмена после удаления адреса сервера, учетных данных и токенов
+func WithScrubber(scrubber Scrubber) Option {
+	return func(options *recorderOptions) {
+		options.scrubbers = append(options.scrubbers, scrubber)
+	}
+}
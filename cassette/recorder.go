@@ -0,0 +1,336 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mode режим работы Recorder
+type Mode byte
+
+const (
+	// ModeReplay воспроизведение записанных обменов без обращения к серверу
+	ModeReplay Mode = iota
+	// ModeRecord выполнение запросов к серверу с записью обменов
+	ModeRecord
+	// ModeAuto воспроизведение, если файл кассеты существует, иначе запись
+	ModeAuto
+)
+
+// String возвращает наименование режима
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	case ModeAuto:
+		return "auto"
+	default:
+		return fmt.Sprintf("Mode(%d)", m)
+	}
+}
+
+// ParseMode возвращает режим по его наименованию
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "replay":
+		return ModeReplay, nil
+	case "record":
+		return ModeRecord, nil
+	case "auto":
+		return ModeAuto, nil
+	default:
+		return ModeReplay, fmt.Errorf("unknown cassette mode %q", s)
+	}
+}
+
+// Matcher функция сопоставления запроса r с телом body записанному запросу recorded
+type Matcher func(r *http.Request, body []byte, recorded Request) bool
+
+// DefaultMatcher сопоставляет запросы по методу, пути, параметрам и телу запроса. Адрес сервера не учитывается,
+// конфиденциальные поля тела запроса перед сравнением заменяются так же, как при записи
+func DefaultMatcher(r *http.Request, body []byte, recorded Request) bool {
+	if r.Method != recorded.Method {
+		return false
+	}
+
+	u, err := url.Parse(recorded.URL)
+
+	if err != nil || u.Path != r.URL.Path || u.Query().Encode() != r.URL.Query().Encode() {
+		return false
+	}
+
+	return scrubBody(r.Header.Get("Content-Type"), string(body)) == recorded.Body
+}
+
+// Recorder реализация http.RoundTripper, записывающая обмены с сервером в кассету или воспроизводящая их из нее.
+//
+// При воспроизведении каждому запросу сопоставляется первый еще не воспроизведенный обмен кассеты, поэтому
+// повторяющиеся запросы получают ответы в порядке записи. Запрос, которому не сопоставлен ни один обмен, завершается
+// ошибкой
+type Recorder struct {
+	path     string
+	mode     Mode
+	options  *recorderOptions
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// New возвращает Recorder кассеты path в режиме mode. В режиме воспроизведения кассета загружается из файла, в
+// режиме записи сохраняется в файл вызовом Stop
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	if mode == ModeAuto {
+		_, err := os.Stat(path)
+
+		switch {
+		case err == nil:
+			mode = ModeReplay
+
+		case errors.Is(err, fs.ErrNotExist):
+			mode = ModeRecord
+
+		default:
+			return nil, err
+		}
+	}
+
+	r := &Recorder{
+		path:    path,
+		mode:    mode,
+		options: newRecorderOptions(opts),
+	}
+
+	switch mode {
+	case ModeReplay:
+		c, err := Load(path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+
+	case ModeRecord:
+		r.cassette = &Cassette{
+			Version:    Version,
+			RecordedAt: time.Now().Truncate(time.Second),
+			Meta:       make(map[string]string),
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown cassette mode %v", mode)
+	}
+
+	return r, nil
+}
+
+// Mode возвращает режим работы. Для ModeAuto возвращается выбранный режим
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// RecordedAt возвращает время начала записи кассеты. Сценарии, зависящие от текущего времени, должны использовать
+// его вместо time.Now, чтобы запросы при воспроизведении совпадали с записанными
+func (r *Recorder) RecordedAt() time.Time {
+	return r.cassette.RecordedAt
+}
+
+// Meta возвращает параметр сценария key, сохраненный в кассете
+func (r *Recorder) Meta(key string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cassette.Meta[key]
+}
+
+// SetMeta сохраняет в кассете параметр сценария key. В режиме воспроизведения параметр не сохраняется в файл
+func (r *Recorder) SetMeta(key, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cassette.Meta == nil {
+		r.cassette.Meta = make(map[string]string)
+	}
+
+	r.cassette.Meta[key] = value
+}
+
+// Client возвращает копию client, запросы которой выполняются через Recorder. В режиме записи запросы передаются
+// транспорту client (http.DefaultTransport, если он не указан)
+func (r *Recorder) Client(client *http.Client) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	next := client.Transport
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	c := *client
+	c.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return r.roundTrip(req, next)
+	})
+
+	return &c
+}
+
+// RoundTrip реализация интерфейса http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.roundTrip(req, r.options.transport)
+}
+
+// Stop завершает работу. В режиме записи кассета сохраняется в файл
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cassette.Save(r.path)
+}
+
+func (r *Recorder) roundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	body, err := readRequestBody(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+
+	return r.record(req, body, next)
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !r.options.matcher(req, body, interaction.Request) {
+			continue
+		}
+
+		r.used[i] = true
+
+		return newResponse(req, interaction.Response), nil
+	}
+
+	return nil, fmt.Errorf("no recorded interaction for %s %s", req.Method, req.URL.RequestURI())
+}
+
+func (r *Recorder) record(req *http.Request, body []byte, next http.RoundTripper) (*http.Response, error) {
+	resp, err := next.RoundTrip(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	interaction := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+			Body:   string(body),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(b),
+		},
+	}
+
+	if interaction.Request.Header == nil {
+		interaction.Request.Header = make(http.Header)
+	}
+
+	// длина тела ответа может измениться при удалении конфиденциальных сведений и устанавливается при
+	// воспроизведении
+	interaction.Response.Header.Del("Content-Length")
+
+	scrub(&interaction)
+
+	for _, scrubber := range r.options.scrubbers {
+		scrubber(&interaction)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// readRequestBody читает тело запроса, оставляя его доступным для повторного чтения
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(b))
+
+	return b, nil
+}
+
+func newResponse(req *http.Request, recorded Response) *http.Response {
+	header := recorded.Header.Clone()
+
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
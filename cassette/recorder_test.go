@@ -0,0 +1,198 @@
+package cassette_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cascade "github.com/vitpelekhaty/go-cascade-client/v2"
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/cascadetest"
+	"github.com/vitpelekhaty/go-cascade-client/v2/cassette"
+)
+
+func loadResponse(t *testing.T, name string) []byte {
+	path, err := filepath.Abs(filepath.Join("../testdata/responses", name))
+
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path)
+
+	require.NoError(t, err)
+
+	return data
+}
+
+// scenario выполняет запросы сценария через соединение с сервером rawURL и возвращает ответы
+func scenario(t *testing.T, recorder *cassette.Recorder, rawURL, authURL string) [][]byte {
+	conn, err := cascade.NewConnection(cascade.WithHTTPClient(recorder.Client(nil)))
+
+	require.NoError(t, err)
+
+	ctx := context.TODO()
+
+	err = conn.Open(ctx, rawURL, cascadetest.DefaultUsername, cascadetest.DefaultPassword,
+		cascade.WithAuthURL(authURL))
+
+	require.NoError(t, err)
+
+	gauges, err := conn.Gauges(ctx)
+
+	require.NoError(t, err)
+
+	from := time.Date(2021, 4, 11, 0, 0, 0, 0, time.UTC)
+
+	readings, err := conn.CurrentReadings(ctx, 12032, archive.HourArchive, from, from.Add(24*time.Hour), 1)
+
+	require.NoError(t, err)
+
+	altered, err := conn.AlteredReadings(ctx, 12032, archive.HourArchive, from, from.Add(24*time.Hour), 1)
+
+	require.NoError(t, err)
+
+	_, err = conn.CurrentReadings(ctx, 12032, archive.HourArchive, from, from.Add(30*24*time.Hour))
+
+	require.Error(t, err)
+
+	require.NoError(t, conn.Close(ctx))
+
+	return [][]byte{gauges, readings, altered, []byte(err.Error())}
+}
+
+func TestRecorder(t *testing.T) {
+	server := cascadetest.NewServer(
+		cascadetest.WithGauges(loadResponse(t, "counterHouse.json")),
+		cascadetest.WithReadings(loadResponse(t, "readings200.json")),
+	)
+
+	path := filepath.Join(t.TempDir(), "cassettes", "scenario.json")
+
+	recorder, err := cassette.New(path, cassette.ModeAuto)
+
+	require.NoError(t, err)
+	assert.Equal(t, cassette.ModeRecord, recorder.Mode())
+
+	recorder.SetMeta("url", cassette.ScrubURL(server.URL))
+	recorder.SetMeta("authURL", cassette.ScrubURL(server.AuthURL()))
+
+	recorded := scenario(t, recorder, server.URL, server.AuthURL())
+
+	require.NoError(t, recorder.Stop())
+
+	server.Close()
+
+	b, err := os.ReadFile(path)
+
+	require.NoError(t, err)
+
+	content := string(b)
+
+	assert.NotContains(t, content, "token-1")
+	assert.NotContains(t, content, strings.TrimPrefix(server.URL, "http://"))
+	assert.NotContains(t, content, "Basic ")
+	assert.Contains(t, content, cassette.Redacted)
+
+	recorder, err = cassette.New(path, cassette.ModeAuto)
+
+	require.NoError(t, err)
+	assert.Equal(t, cassette.ModeReplay, recorder.Mode())
+	assert.Equal(t, "https://cascade.invalid/oauth/token", recorder.Meta("authURL"))
+
+	replayed := scenario(t, recorder, recorder.Meta("url"), recorder.Meta("authURL"))
+
+	assert.Equal(t, recorded, replayed)
+
+	_, err = recorder.Client(nil).Get(recorder.Meta("url") + cascadetest.PathGauges)
+
+	assert.Error(t, err)
+}
+
+func TestRecorder_Login(t *testing.T) {
+	login := loadResponse(t, "login.json")
+	gauges := loadResponse(t, "counterHouse.json")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodPost {
+			_, _ = w.Write(login)
+			return
+		}
+
+		_, _ = w.Write(gauges)
+	}))
+
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "login.json")
+
+	recorder, err := cassette.New(path, cassette.ModeRecord)
+
+	require.NoError(t, err)
+
+	conn, err := cascade.NewConnection(cascade.WithHTTPClient(recorder.Client(nil)))
+
+	require.NoError(t, err)
+	require.NoError(t, conn.Open(context.TODO(), server.URL, "USERNAME", "secret"))
+
+	_, err = conn.Gauges(context.TODO())
+
+	require.NoError(t, err)
+	require.NoError(t, recorder.Stop())
+
+	recorded, err := cassette.Load(path)
+
+	require.NoError(t, err)
+	require.Len(t, recorded.Interactions, 2)
+
+	var token map[string]interface{}
+
+	require.NoError(t, json.Unmarshal([]byte(recorded.Interactions[0].Response.Body), &token))
+
+	assert.Equal(t, map[string]interface{}{
+		"access_token": cassette.Redacted,
+		"token_type":   "bearer",
+		"expires_in":   float64(183521),
+		"scope":        "trust",
+		"userid":       float64(0),
+		"login":        cassette.Redacted,
+		"name":         cassette.Redacted,
+		"server_type":  "Development",
+	}, token)
+
+	assert.Equal(t, string(gauges), recorded.Interactions[1].Response.Body)
+
+	recorder, err = cassette.New(path, cassette.ModeReplay)
+
+	require.NoError(t, err)
+
+	conn, err = cascade.NewConnection(cascade.WithHTTPClient(recorder.Client(nil)))
+
+	require.NoError(t, err)
+	require.NoError(t, conn.Open(context.TODO(), server.URL, "USERNAME", "secret"))
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := cassette.ParseMode("Record")
+
+	require.NoError(t, err)
+	assert.Equal(t, cassette.ModeRecord, mode)
+
+	_, err = cassette.ParseMode("rewind")
+
+	assert.Error(t, err)
+
+	_, err = cassette.New(filepath.Join(t.TempDir(), "missing.json"), cassette.ModeReplay)
+
+	assert.Error(t, err)
+}
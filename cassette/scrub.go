@@ -0,0 +1,164 @@
+package cassette
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// Redacted значение, которым заменяются учетные данные и токены
+const Redacted = "REDACTED"
+
+// Host имя узла, которым заменяется адрес сервера в записанных запросах
+const Host = "cascade.invalid"
+
+// Scrubber функция удаления из обмена с сервером конфиденциальных сведений перед сохранением
+type Scrubber func(i *Interaction)
+
+var (
+	// redactedHeaders заголовки, значения которых не сохраняются
+	redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+	// redactedFields поля форм и ответов в формате JSON, значения которых не сохраняются
+	redactedFields = map[string]bool{
+		"access_token":  true,
+		"refresh_token": true,
+		"id_token":      true,
+		"client_secret": true,
+		"password":      true,
+		"username":      true,
+		"login":         true,
+	}
+
+	// tokenFields поля ответа сервера авторизации (объекта JSON с полем access_token), значения которых не
+	// сохраняются. Вне ответа сервера авторизации эти поля не изменяются: например, name - наименование прибора
+	// учета в списке приборов учета
+	tokenFields = map[string]bool{
+		"token":  true,
+		"userid": true,
+		"name":   true,
+	}
+)
+
+// ScrubURL заменяет схему и узел адреса rawURL на https://cascade.invalid и удаляет сведения о пользователе.
+// Некорректный адрес возвращается без изменений
+func ScrubURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	u.Scheme = "https"
+	u.Host = Host
+	u.User = nil
+
+	return u.String()
+}
+
+// scrub удаляет из обмена адрес сервера, учетные данные и токены
+func scrub(i *Interaction) {
+	i.Request.URL = ScrubURL(i.Request.URL)
+
+	for _, name := range redactedHeaders {
+		redactHeader(i.Request.Header, name)
+		redactHeader(i.Response.Header, name)
+	}
+
+	i.Request.Body = scrubBody(i.Request.Header.Get("Content-Type"), i.Request.Body)
+	i.Response.Body = scrubBody(i.Response.Header.Get("Content-Type"), i.Response.Body)
+}
+
+func redactHeader(header map[string][]string, name string) {
+	values, ok := header[name]
+
+	if !ok {
+		return
+	}
+
+	for i := range values {
+		values[i] = Redacted
+	}
+}
+
+// scrubBody заменяет значения конфиденциальных полей формы или объекта JSON
+func scrubBody(contentType, body string) string {
+	if body == "" {
+		return body
+	}
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(body)
+
+		if err != nil {
+			return body
+		}
+
+		for name := range values {
+			if redactedFields[strings.ToLower(name)] {
+				values.Set(name, Redacted)
+			}
+		}
+
+		return values.Encode()
+	}
+
+	var v interface{}
+
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+
+	if decoder.Decode(&v) != nil {
+		return body
+	}
+
+	if !redactJSON(v) {
+		return body
+	}
+
+	b, err := json.Marshal(v)
+
+	if err != nil {
+		return body
+	}
+
+	return string(b)
+}
+
+// redactJSON заменяет значения конфиденциальных полей объектов JSON и возвращает признак замены
+func redactJSON(v interface{}) bool {
+	var changed bool
+
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		_, isToken := vv["access_token"]
+
+		for key, value := range vv {
+			if name := strings.ToLower(key); redactedFields[name] || (isToken && tokenFields[name]) {
+				vv[key] = redacted(value)
+				changed = true
+
+				continue
+			}
+
+			changed = redactJSON(value) || changed
+		}
+
+	case []interface{}:
+		for _, value := range vv {
+			changed = redactJSON(value) || changed
+		}
+	}
+
+	return changed
+}
+
+// redacted возвращает значение, которым заменяется значение поля v. Числа заменяются нулем, чтобы ответ
+// декодировался при воспроизведении
+func redacted(v interface{}) interface{} {
+	if _, ok := v.(json.Number); ok {
+		return json.Number("0")
+	}
+
+	return Redacted
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	cascade "github.com/vitpelekhaty/go-cascade-client/v2"
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/internal/series"
+	"github.com/vitpelekhaty/go-cascade-client/v2/openmetrics"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+// channelKey ключ последнего показания канала
+type channelKey struct {
+	device, input, channel int64
+}
+
+// deviceState состояние опроса прибора учета
+type deviceState struct {
+	// lastSuccess момент последнего успешного опроса
+	lastSuccess time.Time
+
+	// errors количество неудачных опросов
+	errors int64
+}
+
+// collector периодически опрашивает API Каскада и хранит последние часовые показания каналов приборов учета
+type collector struct {
+	conn     cascade.IConnection
+	location *time.Location
+	lookback time.Duration
+	now      func() time.Time
+
+	mu           sync.RWMutex
+	topo         *topology.Topology
+	latest       map[channelKey]parsers.Readings
+	devices      map[int64]*deviceState
+	gaugesOK     time.Time
+	gaugesErrors int64
+	scrapes      int64
+	duration     time.Duration
+}
+
+// newCollector возвращает collector, запрашивающий показания за последний период lookback. Время показаний
+// указано в часовом поясе сервера Каскада loc
+func newCollector(conn cascade.IConnection, loc *time.Location, lookback time.Duration) *collector {
+	return &collector{
+		conn:     conn,
+		location: loc,
+		lookback: lookback,
+		now:      time.Now,
+		latest:   make(map[channelKey]parsers.Readings),
+		devices:  make(map[int64]*deviceState),
+	}
+}
+
+// run опрашивает API Каскада с интервалом interval до отмены ctx
+func (c *collector) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.scrape(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scrape обновляет список приборов учета и последние показания каждого прибора учета
+func (c *collector) scrape(ctx context.Context) {
+	start := c.now()
+
+	topo, err := c.gauges(ctx)
+
+	c.mu.Lock()
+
+	if err != nil {
+		log.Printf("gauges: %v", err)
+
+		c.gaugesErrors++
+		topo = c.topo
+	} else {
+		c.topo = topo
+		c.gaugesOK = start
+		c.prune(topo)
+	}
+
+	c.mu.Unlock()
+
+	if topo != nil {
+		end := start.In(c.location)
+		begin := end.Add(-c.lookback)
+
+		for _, gauge := range topo.Devices() {
+			if ctx.Err() != nil {
+				break
+			}
+
+			readings, err := c.readings(ctx, gauge.ID, begin, end)
+
+			c.update(gauge.ID, readings, err)
+		}
+	}
+
+	c.mu.Lock()
+	c.scrapes++
+	c.duration = c.now().Sub(start)
+	c.mu.Unlock()
+}
+
+// gauges запрашивает список приборов учета
+func (c *collector) gauges(ctx context.Context) (*topology.Topology, error) {
+	data, err := c.conn.Gauges(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := parsers.ParseGaugesList(ctx, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return topology.FromItems(ctx, items)
+}
+
+// readings запрашивает часовые показания прибора учета deviceID за период [begin, end]
+func (c *collector) readings(ctx context.Context, deviceID int64, begin, end time.Time) ([]parsers.Readings, error) {
+	data, err := c.conn.CurrentReadings(ctx, deviceID, archive.HourArchive, begin, end)
+
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := parsers.ParseReadings(ctx, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	readings := make([]parsers.Readings, 0)
+
+	for item := range items {
+		if item.Error() {
+			log.Printf("device %d: %v", deviceID, item.E)
+			continue
+		}
+
+		readings = append(readings, *item.V.(*parsers.Readings))
+	}
+
+	return readings, nil
+}
+
+// update сохраняет результат опроса прибора учета deviceID
+func (c *collector) update(deviceID int64, readings []parsers.Readings, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state, ok := c.devices[deviceID]
+
+	if !ok {
+		state = &deviceState{}
+		c.devices[deviceID] = state
+	}
+
+	if err != nil {
+		log.Printf("device %d: %v", deviceID, err)
+
+		state.errors++
+
+		return
+	}
+
+	state.lastSuccess = c.now()
+
+	for _, r := range readings {
+		if (r.Empty.Valid && r.Empty.Bool) || r.DT.IsZero() {
+			continue
+		}
+
+		key := channelKey{device: deviceID, input: r.Input.Int64, channel: r.ChannelID.Int64}
+
+		if !r.ChannelID.Valid {
+			key.channel = -r.ChannelNum.Int64 - 1
+		}
+
+		if last, ok := c.latest[key]; ok && !r.DT.Time().After(last.DT.Time()) {
+			continue
+		}
+
+		c.latest[key] = r
+	}
+}
+
+// prune удаляет последние показания каналов и состояние опроса приборов учета, отсутствующих в списке приборов
+// учета topo
+func (c *collector) prune(topo *topology.Topology) {
+	for key, r := range c.latest {
+		if channel, ok := topo.ChannelOf(&r); !ok || channel.Device.ID != key.device {
+			delete(c.latest, key)
+		}
+	}
+
+	for id := range c.devices {
+		if _, ok := topo.Device(id); !ok {
+			delete(c.devices, id)
+		}
+	}
+}
+
+// write выводит последние показания и метрики состояния опроса в формате OpenMetrics. Метрики формируются в памяти,
+// чтобы медленный клиент не задерживал опрос API Каскада
+func (c *collector) write(w io.Writer) error {
+	var buf bytes.Buffer
+
+	if err := c.encode(&buf); err != nil {
+		return err
+	}
+
+	_, err := buf.WriteTo(w)
+
+	return err
+}
+
+// encode выводит последние показания и метрики состояния опроса в формате OpenMetrics
+func (c *collector) encode(w io.Writer) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]channelKey, 0, len(c.latest))
+
+	for key := range c.latest {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+
+		if a.device != b.device {
+			return a.device < b.device
+		}
+
+		if a.input != b.input {
+			return a.input < b.input
+		}
+
+		return a.channel < b.channel
+	})
+
+	opts := []openmetrics.Option{openmetrics.WithTopology(c.topo), openmetrics.WithoutTimestamps()}
+
+	e := openmetrics.NewEncoder(w, opts...)
+
+	timestamps := make([]openmetrics.Sample, 0, len(keys))
+
+	for _, key := range keys {
+		r := c.latest[key]
+
+		if err := e.Encode(&r); err != nil {
+			return err
+		}
+
+		if samples := openmetrics.Samples(&r, opts...); len(samples) > 0 {
+			timestamps = append(timestamps, openmetrics.Sample{
+				Labels: samples[0].Labels,
+				Value:  float64(series.Time(&r, c.location).Unix()),
+			})
+		}
+	}
+
+	devices := make([]int64, 0, len(c.devices))
+
+	for id := range c.devices {
+		devices = append(devices, id)
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i] < devices[j]
+	})
+
+	lastSuccess := make([]openmetrics.Sample, 0, len(devices))
+	failures := make([]openmetrics.Sample, 0, len(devices))
+
+	for _, id := range devices {
+		state := c.devices[id]
+		labels := []openmetrics.Label{{Name: "device", Value: strconv.FormatInt(id, 10)}}
+
+		if !state.lastSuccess.IsZero() {
+			lastSuccess = append(lastSuccess, openmetrics.Sample{Labels: labels, Value: unixSeconds(state.lastSuccess)})
+		}
+
+		failures = append(failures, openmetrics.Sample{Labels: labels, Value: float64(state.errors)})
+	}
+
+	var gaugesOK []openmetrics.Sample
+
+	if !c.gaugesOK.IsZero() {
+		gaugesOK = append(gaugesOK, openmetrics.Sample{Value: unixSeconds(c.gaugesOK)})
+	}
+
+	for _, f := range []struct {
+		name    string
+		typ     openmetrics.MetricType
+		help    string
+		samples []openmetrics.Sample
+	}{
+		{"cascade_reading_timestamp_seconds", openmetrics.Gauge, "Time of the latest hourly readings", timestamps},
+		{"cascade_exporter_device_last_success_timestamp_seconds", openmetrics.Gauge,
+			"Time of the last successful readings request per device", lastSuccess},
+		{"cascade_exporter_device_errors", openmetrics.Counter, "Failed readings requests per device", failures},
+		{"cascade_exporter_gauges_last_success_timestamp_seconds", openmetrics.Gauge,
+			"Time of the last successful gauges request", gaugesOK},
+		{"cascade_exporter_gauges_errors", openmetrics.Counter, "Failed gauges requests",
+			[]openmetrics.Sample{{Value: float64(c.gaugesErrors)}}},
+		{"cascade_exporter_scrapes", openmetrics.Counter, "Completed scrapes of the Cascade API",
+			[]openmetrics.Sample{{Value: float64(c.scrapes)}}},
+		{"cascade_exporter_scrape_duration_seconds", openmetrics.Gauge, "Duration of the last scrape",
+			[]openmetrics.Sample{{Value: c.duration.Seconds()}}},
+	} {
+		if err := e.AddFamily(f.name, f.typ, f.help, f.samples...); err != nil {
+			return err
+		}
+	}
+
+	return e.Close()
+}
+
+// unixSeconds возвращает момент времени t в секундах Unix
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixMilli()) / 1000
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cascade "github.com/vitpelekhaty/go-cascade-client/v2"
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+)
+
+const testGauges = `[
+	{"id": 12032, "name": "Test", "modelName": "TMK", "serialNumber": "1", "title": "Test 1", "inputs": [
+		{"number": 1, "channels": [
+			{"id": 19265, "number": 1, "resourceType": "Heat", "type": "inFlow"},
+			{"id": 19266, "number": 2, "resourceType": "Heat", "type": "outFlow"},
+			{"id": 19288, "number": 0, "resourceType": "None"}
+		]}
+	]},
+	{"id": 42, "name": "Broken", "modelName": "TMK", "serialNumber": "2", "title": "Test 2", "inputs": []}
+]`
+
+type fakeConnection struct {
+	gauges   []byte
+	readings []byte
+	begin    time.Time
+	end      time.Time
+}
+
+var _ cascade.IConnection = (*fakeConnection)(nil)
+
+func (conn *fakeConnection) Open(context.Context, string, string, string, ...cascade.OpenOption) error {
+	return nil
+}
+
+func (conn *fakeConnection) Close(context.Context) error {
+	return nil
+}
+
+func (conn *fakeConnection) Connected() bool {
+	return true
+}
+
+func (conn *fakeConnection) Gauges(context.Context) ([]byte, error) {
+	if conn.gauges != nil {
+		return conn.gauges, nil
+	}
+
+	return []byte(testGauges), nil
+}
+
+func (conn *fakeConnection) CurrentReadings(_ context.Context, deviceID int64, _ archive.DataArchive, beginAt,
+	endAt time.Time, _ ...byte) ([]byte, error) {
+	if deviceID != 12032 {
+		return nil, errors.New("device is not available")
+	}
+
+	conn.begin, conn.end = beginAt, endAt
+
+	return conn.readings, nil
+}
+
+func (conn *fakeConnection) AlteredReadings(context.Context, int64, archive.DataArchive, time.Time, time.Time,
+	...byte) ([]byte, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestCollector(t *testing.T) {
+	path, err := filepath.Abs("../../testdata/responses/readings200.json")
+
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path)
+
+	require.NoError(t, err)
+
+	conn := &fakeConnection{readings: data}
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	now := time.Date(2021, 4, 18, 1, 30, 0, 0, time.UTC)
+
+	c := newCollector(conn, loc, 3*time.Hour)
+	c.now = func() time.Time {
+		return now
+	}
+
+	c.scrape(context.TODO())
+
+	assert.Equal(t, time.Date(2021, 4, 18, 6, 30, 0, 0, loc), conn.end)
+	assert.Equal(t, time.Date(2021, 4, 18, 3, 30, 0, 0, loc), conn.begin)
+
+	server := httptest.NewServer(newHandler(c))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	var buf bytes.Buffer
+
+	_, err = buf.ReadFrom(resp.Body)
+
+	require.NoError(t, err)
+
+	out := buf.String()
+
+	assert.Equal(t, "application/openmetrics-text; version=1.0.0; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	for _, line := range []string{
+		`cascade_temperature_celsius{channel="19265",device="12032",flow="inFlow",input="1",resource="Heat"} 67.91999816894531`,
+		`cascade_temperature_celsius{channel="19266",device="12032",flow="outFlow",input="1",resource="Heat"} 46.849998474121094`,
+		`cascade_heat_gcal{channel="19288",device="12032",input="1",resource="None"} 0.05301763489842415`,
+		`cascade_reading_timestamp_seconds{channel="19265",device="12032",flow="inFlow",input="1",resource="Heat"} 1618689600`,
+		`cascade_exporter_device_last_success_timestamp_seconds{device="12032"} 1618709400`,
+		`cascade_exporter_device_errors_total{device="42"} 1`,
+		`cascade_exporter_device_errors_total{device="12032"} 0`,
+		`cascade_exporter_gauges_errors_total 0`,
+		`cascade_exporter_scrapes_total 1`,
+	} {
+		assert.Contains(t, out, line+"\n")
+	}
+
+	assert.NotContains(t, out, `device_last_success_timestamp_seconds{device="42"}`)
+	assert.Equal(t, 1, strings.Count(out, "cascade_temperature_celsius{channel=\"19265\""))
+	assert.True(t, strings.HasSuffix(out, "# EOF\n"))
+}
+
+func TestCollector_Prune(t *testing.T) {
+	path, err := filepath.Abs("../../testdata/responses/readings200.json")
+
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path)
+
+	require.NoError(t, err)
+
+	conn := &fakeConnection{readings: data}
+
+	c := newCollector(conn, time.UTC, 3*time.Hour)
+	c.now = func() time.Time {
+		return time.Date(2021, 4, 18, 1, 30, 0, 0, time.UTC)
+	}
+
+	c.scrape(context.TODO())
+
+	conn.gauges = []byte(`[{"id": 42, "name": "Broken", "modelName": "TMK", "serialNumber": "2", "title": "Test 2"}]`)
+
+	c.scrape(context.TODO())
+
+	var buf bytes.Buffer
+
+	require.NoError(t, c.write(&buf))
+
+	out := buf.String()
+
+	assert.NotContains(t, out, `device="12032"`)
+	assert.Contains(t, out, `cascade_exporter_device_errors_total{device="42"} 2`+"\n")
+}
+
+func TestCollector_ChannelNum(t *testing.T) {
+	conn := &fakeConnection{readings: []byte(`[
+		{"id": 1, "deviceId": 12032, "channelNum": 1, "inputNum": 1, "archiveType": "Hour",
+			"dt": "2021-04-18T05:00:00.000", "t": 70, "isBadRow": false},
+		{"id": 2, "deviceId": 12032, "channelNum": 2, "inputNum": 1, "archiveType": "Hour",
+			"dt": "2021-04-18T05:00:00.000", "t": 45, "isBadRow": false}
+	]`)}
+
+	c := newCollector(conn, time.UTC, 3*time.Hour)
+	c.now = func() time.Time {
+		return time.Date(2021, 4, 18, 6, 30, 0, 0, time.UTC)
+	}
+
+	c.scrape(context.TODO())
+
+	var buf bytes.Buffer
+
+	require.NoError(t, c.write(&buf))
+
+	out := buf.String()
+
+	for _, line := range []string{
+		`cascade_temperature_celsius{channel_num="1",device="12032",flow="inFlow",input="1",resource="Heat"} 70`,
+		`cascade_temperature_celsius{channel_num="2",device="12032",flow="outFlow",input="1",resource="Heat"} 45`,
+	} {
+		assert.Contains(t, out, line+"\n")
+	}
+
+	conn.gauges = []byte(`[
+		{"id": 12032, "name": "Test", "modelName": "TMK", "serialNumber": "1", "title": "Test 1", "inputs": [
+			{"number": 1, "channels": [{"id": 19265, "number": 1, "resourceType": "Heat", "type": "inFlow"}]}
+		]}
+	]`)
+	conn.readings = []byte(`[]`)
+
+	c.scrape(context.TODO())
+
+	buf.Reset()
+
+	require.NoError(t, c.write(&buf))
+
+	out = buf.String()
+
+	assert.Contains(t, out, `cascade_temperature_celsius{channel_num="1",device="12032"`)
+	assert.NotContains(t, out, `channel_num="2"`)
+}
@@ -0,0 +1,120 @@
+// Команда cascade-exporter периодически запрашивает список приборов учета и текущие часовые показания в API
+// Каскада и публикует последние показания каналов в формате OpenMetrics для сбора Prometheus.
+//
+// Использование:
+//
+//	cascade-exporter [-listen :9868] [-interval 5m] [-lookback 3h] [-tz Asia/Yekaterinburg] [параметры соединения]
+//
+// Параметры соединения задаются флагами, переменными окружения CASCADE_* или файлом .env
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/cmd/internal/config"
+	"github.com/vitpelekhaty/go-cascade-client/v2/openmetrics"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	var (
+		settings config.Settings
+
+		listen   = flag.String("listen", ":9868", "address to serve metrics on")
+		interval = flag.Duration("interval", 5*time.Minute, "Cascade API polling interval")
+		lookback = flag.Duration("lookback", 3*time.Hour, "period of hourly readings requested on each poll")
+		tz       = flag.String("tz", "Local", "time zone of the Cascade server")
+	)
+
+	settings.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	if err := settings.Load(); err != nil {
+		return err
+	}
+
+	loc, err := time.LoadLocation(*tz)
+
+	if err != nil {
+		return fmt.Errorf("invalid time zone: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	conn, err := settings.Connect(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close(context.Background())
+
+	c := newCollector(conn, loc, *lookback)
+
+	go c.run(ctx, *interval)
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           newHandler(c),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       time.Minute,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("serving metrics on %s/metrics", *listen)
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// newHandler возвращает обработчик HTTP запросов экспортера
+func newHandler(c *collector) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", openmetrics.ContentType)
+
+		if err := c.write(w); err != nil {
+			log.Printf("metrics: %v", err)
+		}
+	})
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		_, _ = fmt.Fprintln(w, `<html><body><h1>Cascade exporter</h1><a href="/metrics">Metrics</a></body></html>`)
+	})
+
+	return mux
+}
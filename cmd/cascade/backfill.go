@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/backfill"
+	"github.com/vitpelekhaty/go-cascade-client/v2/cmd/internal/config"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/storage/sqlite"
+)
+
+// runBackfill загружает показания приборов учета за период в базу данных SQLite с возможностью возобновления
+func runBackfill(ctx context.Context, args []string, stdout io.Writer) error {
+	var (
+		settings    config.Settings
+		devices     idList
+		a           = archive.HourArchive
+		from, to    timeFlag
+		window      time.Duration
+		concurrency int
+		retries     int
+		checkpoint  string
+		db          string
+	)
+
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	settings.RegisterFlags(fs)
+
+	fs.Var(&devices, "device", "device ids, comma separated or repeated; all gauges by default")
+	fs.Var(&a, "archive", "archive type: Hour or Day")
+	fs.Var(&from, "from", "beginning of the period by the server clock (required)")
+	fs.Var(&to, "to", "end of the period by the server clock; the end saved in the checkpoint or now by default")
+	fs.DurationVar(&window, "window", backfill.MaxWindow, "period of a single request, 168h at most")
+	fs.IntVar(&concurrency, "concurrency", 4, "number of concurrent requests")
+	fs.IntVar(&retries, "retries", 2, "number of retries of a failed request")
+	fs.StringVar(&checkpoint, "checkpoint", "cascade-backfill.jsonl", "checkpoint file to resume from")
+	fs.StringVar(&db, "db", "", "SQLite database file to save gauges and readings to (required)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if from.t.IsZero() {
+		return errors.New("beginning of the period is not specified (-from)")
+	}
+
+	if db == "" {
+		return errors.New("database file is not specified (-db)")
+	}
+
+	if err := settings.Load(); err != nil {
+		return err
+	}
+
+	sink, err := sqlite.Open(ctx, db)
+
+	if err != nil {
+		return err
+	}
+
+	defer sink.Close()
+
+	cp, err := backfill.OpenCheckpoint(checkpoint)
+
+	if err != nil {
+		return err
+	}
+
+	defer cp.Close()
+
+	if to.t.IsZero() {
+		to.t = time.Now()
+
+		if end, ok := cp.End(); ok {
+			to.t = end
+		}
+	}
+
+	if err := cp.SetEnd(to.t); err != nil {
+		return err
+	}
+
+	conn, err := settings.Connect(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close(ctx)
+
+	data, err := conn.Gauges(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	gauges, err := parseGauges(ctx, data)
+
+	if err != nil {
+		return err
+	}
+
+	gauges = filterGauges(gauges, &gaugesFilter{devices: devices})
+
+	if err := sink.SaveGauges(ctx, gauges); err != nil {
+		return err
+	}
+
+	ids := make([]int64, len(gauges))
+
+	for i, gauge := range gauges {
+		ids[i] = gauge.ID
+	}
+
+	units, err := backfill.Plan(ids, a, from.t, to.t, window)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "%d devices, %d units, %d done before\n", len(ids), len(units), cp.Len())
+
+	progress, err := backfill.Run(ctx, conn, units,
+		func(ctx context.Context, _ backfill.Unit, readings []parsers.Readings) error {
+			_, err := sink.SaveReadings(ctx, readings)
+			return err
+		},
+		backfill.WithCheckpoint(cp),
+		backfill.WithConcurrency(concurrency),
+		backfill.WithRetries(retries, time.Second),
+		backfill.WithProgress(func(p backfill.Progress) {
+			if p.Err != nil {
+				fmt.Fprintf(stdout, "[%d/%d] %v\n", p.Done+p.Failed, p.Total, p.Err)
+				return
+			}
+
+			if !p.Resumed {
+				fmt.Fprintf(stdout, "[%d/%d] %s: done\n", p.Done+p.Failed, p.Total, p.Unit)
+			}
+		}))
+
+	if progress != nil {
+		fmt.Fprintf(stdout, "done %d of %d units (%d resumed), %d failed, %d readings\n", progress.Done,
+			progress.Total, progress.Skipped, progress.Failed, progress.Readings)
+	}
+
+	if err != nil && progress != nil && progress.Failed > 0 {
+		return fmt.Errorf("%d units failed, run the command again to retry them", progress.Failed)
+	}
+
+	return err
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/cascadetest"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+func loadResponse(t *testing.T, name string) []byte {
+	path, err := filepath.Abs(filepath.Join("../../testdata/responses", name))
+
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path)
+
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestFilterGauges(t *testing.T) {
+	gauges, err := parseGauges(context.TODO(), loadResponse(t, "counterHouse.json"))
+
+	require.NoError(t, err)
+	require.Len(t, gauges, 38)
+
+	filtered := filterGauges(gauges, &gaugesFilter{search: "ШУМАКОВА"})
+
+	require.Len(t, filtered, 3)
+	assert.Equal(t, int64(8830), filtered[0].ID)
+
+	filtered = filterGauges(gauges, &gaugesFilter{resource: "hotwater"})
+
+	require.Len(t, filtered, 32)
+
+	var channels int
+
+	for _, gauge := range filtered {
+		for _, input := range gauge.Inputs {
+			for _, channel := range input.Channels {
+				assert.Equal(t, parsers.ResourceHotWater, channel.Resource)
+
+				channels++
+			}
+		}
+	}
+
+	assert.Equal(t, 69, channels)
+
+	var devices idList
+
+	require.NoError(t, devices.Set("8830, 767"))
+	assert.Error(t, devices.Set("x"))
+
+	filtered = filterGauges(gauges, &gaugesFilter{devices: devices})
+
+	require.Len(t, filtered, 2)
+}
+
+func TestWriteGauges(t *testing.T) {
+	gauges, err := parseGauges(context.TODO(), loadResponse(t, "counterHouse.json"))
+
+	require.NoError(t, err)
+
+	gauges = filterGauges(gauges, &gaugesFilter{devices: idList{8830}})
+
+	var buf bytes.Buffer
+
+	require.NoError(t, writeGauges(&buf, gauges, formatTable))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	assert.Equal(t, []string{"DEVICE", "TITLE", "MODEL", "SERIAL", "INPUT", "CHANNEL", "NUM", "RESOURCE", "FLOW"},
+		strings.Fields(lines[0]))
+	assert.Equal(t, []string{"8830", "Шумакова,", "32", "ТМК-Н30", "11412", "1", "9246", "1", "Heat", "inFlow"},
+		strings.Fields(lines[1]))
+
+	buf.Reset()
+
+	require.NoError(t, writeGauges(&buf, gauges, formatJSON))
+
+	var decoded []parsers.Gauge
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, gauges, decoded)
+
+	buf.Reset()
+
+	require.NoError(t, writeGauges(&buf, gauges, formatCSV))
+	assert.True(t, strings.HasPrefix(buf.String(), "deviceId,title,"))
+}
+
+func TestWriteReadings(t *testing.T) {
+	readings, err := parseReadings(context.TODO(), loadResponse(t, "readings200.json"))
+
+	require.NoError(t, err)
+	require.Len(t, readings, 507)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, writeReadings(&buf, readings[:1], formatTable))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"2021-04-11", "01:00", "1", "1", "2.251", "2.298", "7.000", "67.090", "0.000", "1.000", "-",
+		"-", "-"}, strings.Fields(lines[1]))
+
+	buf.Reset()
+
+	require.NoError(t, writeReadings(&buf, readings, formatCSV))
+	assert.Equal(t, 508, strings.Count(buf.String(), "\n"))
+}
+
+func TestParseReadings_Skip(t *testing.T) {
+	readings, err := parseReadings(context.TODO(), []byte(`[{"id": 1}, {"id": "x"}, {"id": 3}]`))
+
+	require.NoError(t, err)
+	require.Len(t, readings, 2)
+	assert.Equal(t, int64(3), readings[1].ID.Int64)
+}
+
+func TestReadingsQuery_Check(t *testing.T) {
+	now := time.Date(2021, 4, 18, 12, 0, 0, 0, time.Local)
+
+	q := &readingsQuery{format: formatTable}
+
+	assert.Error(t, q.check(now))
+
+	q.device = 12032
+
+	require.NoError(t, q.check(now))
+	assert.Equal(t, now, q.to.t)
+	assert.Equal(t, now.Add(-24*time.Hour), q.from.t)
+	assert.Nil(t, q.inputNum())
+
+	q.input = 2
+
+	require.NoError(t, q.from.Set("2021-04-19"))
+	assert.Error(t, q.check(now))
+	assert.Equal(t, []byte{2}, q.inputNum())
+
+	assert.Error(t, q.from.Set("19.04.2021"))
+}
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	err := run(context.TODO(), []string{"unknown"}, &stdout, &stderr)
+
+	assert.Error(t, err)
+	assert.Contains(t, stderr.String(), "readings")
+
+	err = run(context.TODO(), []string{"readings", "-format", "xml", "-device", "1"}, &stdout, &stderr)
+
+	assert.EqualError(t, err, `unknown output format "xml"`)
+}
+
+func TestRunLogin(t *testing.T) {
+	server := cascadetest.NewServer(cascadetest.WithGauges(loadResponse(t, "counterHouse.json")),
+		cascadetest.WithTokenTTL(time.Hour))
+
+	defer server.Close()
+
+	var stdout bytes.Buffer
+
+	err := runLogin(context.TODO(), []string{"-env", os.DevNull, "-url", server.URL, "-auth-url", server.AuthURL(),
+		"-username", cascadetest.DefaultUsername, "-password", cascadetest.DefaultPassword}, &stdout)
+
+	require.NoError(t, err)
+
+	out := stdout.String()
+
+	for _, line := range []string{
+		`Connected:\s+true`,
+		`Token type:\s+bearer`,
+		`Expires in:\s+1h0m0s`,
+		`User ID:\s+1`,
+		`Connection:\s+cascadetest`,
+		`Server type:\s+Test`,
+	} {
+		assert.Regexp(t, `(?m)^`+line+`$`, out)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Форматы вывода
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// checkFormat проверяет формат вывода
+func checkFormat(format string) error {
+	switch format {
+	case formatTable, formatJSON, formatCSV:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// idList список идентификаторов, заданный флагом через запятую или повторением флага
+type idList []int64
+
+// String реализация интерфейса flag.Value для типа idList
+func (l *idList) String() string {
+	ids := make([]string, len(*l))
+
+	for i, id := range *l {
+		ids[i] = strconv.FormatInt(id, 10)
+	}
+
+	return strings.Join(ids, ",")
+}
+
+// Set реализация интерфейса flag.Value для типа idList
+func (l *idList) Set(s string) error {
+	for _, part := range strings.Split(s, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+
+		if err != nil {
+			return fmt.Errorf("invalid id %q", part)
+		}
+
+		*l = append(*l, id)
+	}
+
+	return nil
+}
+
+// contains проверяет наличие идентификатора id в списке. Пустой список содержит любой идентификатор
+func (l idList) contains(id int64) bool {
+	if len(l) == 0 {
+		return true
+	}
+
+	for _, item := range l {
+		if item == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// timeLayouts допустимые форматы моментов времени в флагах
+var timeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// timeFlag момент времени, заданный флагом. Момент указывается по часам сервера Каскада
+type timeFlag struct {
+	t time.Time
+}
+
+// String реализация интерфейса flag.Value для типа timeFlag
+func (f *timeFlag) String() string {
+	if f.t.IsZero() {
+		return ""
+	}
+
+	return f.t.Format(timeLayouts[0])
+}
+
+// Set реализация интерфейса flag.Value для типа timeFlag
+func (f *timeFlag) Set(s string) error {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			f.t = t
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid time %q, expected YYYY-MM-DD[THH:MM[:SS]]", s)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/cmd/internal/config"
+	"github.com/vitpelekhaty/go-cascade-client/v2/csvexport"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// gaugesFilter условия отбора приборов учета
+type gaugesFilter struct {
+	// devices идентификаторы приборов учета
+	devices idList
+
+	// resource тип ресурса каналов
+	resource string
+
+	// search строка поиска в наименовании, модели и серийном номере прибора учета
+	search string
+}
+
+// runGauges выводит список приборов учета
+func runGauges(ctx context.Context, args []string, stdout io.Writer) error {
+	var (
+		settings config.Settings
+		filter   gaugesFilter
+		format   string
+	)
+
+	fs := flag.NewFlagSet("gauges", flag.ContinueOnError)
+	settings.RegisterFlags(fs)
+
+	fs.Var(&filter.devices, "device", "device ids, comma separated or repeated")
+	fs.StringVar(&filter.resource, "resource", "", "resource type of channels (Heat, HotWater, None, ...)")
+	fs.StringVar(&filter.search, "search", "", "case-insensitive text in title, name, model or serial number")
+	fs.StringVar(&format, "format", formatTable, "output format: table, json or csv")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+
+	if filter.resource != "" {
+		if _, err := parsers.ParseResource(filter.resource); err != nil {
+			return err
+		}
+	}
+
+	if err := settings.Load(); err != nil {
+		return err
+	}
+
+	conn, err := settings.Connect(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close(ctx)
+
+	data, err := conn.Gauges(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	gauges, err := parseGauges(ctx, data)
+
+	if err != nil {
+		return err
+	}
+
+	return writeGauges(stdout, filterGauges(gauges, &filter), format)
+}
+
+// parseGauges разбирает список приборов учета. Приборы учета с ошибкой разбора пропускаются (см. warn)
+func parseGauges(ctx context.Context, data []byte) ([]parsers.Gauge, error) {
+	items, err := parsers.ParseGaugesList(ctx, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	gauges := make([]parsers.Gauge, 0)
+
+	_, err = parsers.EachGauge(ctx, items, func(gauge *parsers.Gauge) error {
+		gauges = append(gauges, *gauge)
+		return nil
+	}, parsers.WithSkip(warn))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return gauges, nil
+}
+
+// filterGauges возвращает приборы учета, удовлетворяющие условиям filter. При отборе по типу ресурса у приборов
+// учета остаются только каналы этого типа ресурса
+func filterGauges(gauges []parsers.Gauge, filter *gaugesFilter) []parsers.Gauge {
+	search := strings.ToLower(filter.search)
+	result := make([]parsers.Gauge, 0, len(gauges))
+
+	for _, gauge := range gauges {
+		if !filter.devices.contains(gauge.ID) {
+			continue
+		}
+
+		if search != "" && !strings.Contains(strings.ToLower(strings.Join([]string{
+			gauge.Title, gauge.Name, gauge.Model, gauge.SN,
+		}, "\n")), search) {
+			continue
+		}
+
+		if filter.resource != "" {
+			inputs := make([]parsers.Input, 0, len(gauge.Inputs))
+
+			for _, input := range gauge.Inputs {
+				channels := make([]parsers.Channel, 0, len(input.Channels))
+
+				for _, channel := range input.Channels {
+					if strings.EqualFold(channel.Resource.String(), filter.resource) {
+						channels = append(channels, channel)
+					}
+				}
+
+				if len(channels) > 0 {
+					inputs = append(inputs, parsers.Input{Number: input.Number, Channels: channels})
+				}
+			}
+
+			if len(inputs) == 0 {
+				continue
+			}
+
+			gauge.Inputs = inputs
+		}
+
+		result = append(result, gauge)
+	}
+
+	return result
+}
+
+// writeGauges выводит список приборов учета в формате format
+func writeGauges(w io.Writer, gauges []parsers.Gauge, format string) error {
+	switch format {
+	case formatJSON:
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+
+		return e.Encode(gauges)
+
+	case formatCSV:
+		cw := csvexport.NewGaugesWriter(w)
+
+		for i := range gauges {
+			if err := cw.Write(&gauges[i]); err != nil {
+				return err
+			}
+		}
+
+		return cw.Flush()
+
+	default:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+		fmt.Fprintln(tw, "DEVICE\tTITLE\tMODEL\tSERIAL\tINPUT\tCHANNEL\tNUM\tRESOURCE\tFLOW")
+
+		for _, gauge := range gauges {
+			for _, input := range gauge.Inputs {
+				for _, channel := range input.Channels {
+					flow := ""
+
+					if channel.Flow != parsers.FlowUnknown {
+						flow = channel.Flow.String()
+					}
+
+					fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%d\t%d\t%s\t%s\n", gauge.ID, gauge.Title, gauge.Model,
+						gauge.SN, input.Number, channel.ID, channel.Number, channel.Resource, flow)
+				}
+			}
+		}
+
+		return tw.Flush()
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	cascade "github.com/vitpelekhaty/go-cascade-client/v2"
+	"github.com/vitpelekhaty/go-cascade-client/v2/cmd/internal/config"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// runLogin открывает соединение с API Каскада и выводит параметры сеанса
+func runLogin(ctx context.Context, args []string, stdout io.Writer) error {
+	var settings config.Settings
+
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	settings.RegisterFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := settings.Load(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+
+	conn, err := settings.Connect(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close(ctx)
+
+	elapsed := time.Since(start)
+
+	data, err := conn.Gauges(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	items, err := parsers.ParseGaugesList(ctx, data)
+
+	if err != nil {
+		return err
+	}
+
+	var devices int
+
+	for item := range items {
+		if !item.Error() {
+			devices++
+		}
+	}
+
+	authURL := settings.AuthURL
+
+	if authURL == "" {
+		authURL = "(default)"
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "URL:\t%s\n", settings.URL)
+	fmt.Fprintf(w, "Auth URL:\t%s\n", authURL)
+	fmt.Fprintf(w, "User:\t%s\n", settings.Username)
+	fmt.Fprintf(w, "Connected:\t%t\n", conn.Connected())
+	fmt.Fprintf(w, "Login time:\t%s\n", elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "Gauges:\t%d\n", devices)
+
+	if session, ok := cascade.SessionOf(conn); ok {
+		fmt.Fprintf(w, "Token type:\t%s\n", session.Type)
+		fmt.Fprintf(w, "Expires in:\t%s\n", session.ExpiresIn)
+		fmt.Fprintf(w, "User ID:\t%d\n", session.UserID)
+		fmt.Fprintf(w, "Connection:\t%s\n", session.Connection)
+		fmt.Fprintf(w, "Server type:\t%s\n", session.ServerType)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,107 @@
+// Команда cascade выполняет типовые запросы к API Каскада.
+//
+// Использование:
+//
+//	cascade <команда> [флаги]
+//
+// Команды:
+//
+//	login     проверка параметров соединения
+//	gauges    список приборов учета
+//	readings  показания прибора учета за период
+//	altered   показания прибора учета, измененные за период
+//	backfill  загрузка показаний за длительный период в базу данных SQLite с возобновлением
+//
+// Параметры соединения задаются флагами, переменными окружения CASCADE_* или файлом .env
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// command команда cascade
+type command struct {
+	// name наименование команды
+	name string
+
+	// summary краткое описание команды
+	summary string
+
+	// run выполняет команду с аргументами args и выводит результат в stdout
+	run func(ctx context.Context, args []string, stdout io.Writer) error
+}
+
+// commands команды cascade
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{name: "login", summary: "verify connection settings and print session info", run: runLogin},
+		{name: "gauges", summary: "list gauges with inputs and channels", run: runGauges},
+		{name: "readings", summary: "print readings of a device for a period", run: runReadings},
+		{name: "altered", summary: "print readings of a device altered within a period", run: runAltered},
+		{name: "backfill", summary: "load readings for a long period into SQLite, resumable", run: runBackfill},
+	}
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+
+	stop()
+
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "cascade:", err)
+		}
+
+		os.Exit(2)
+	}
+}
+
+// run выполняет команду, указанную первым аргументом args
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage(stderr)
+		return flag.ErrHelp
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(ctx, args[1:], stdout)
+		}
+	}
+
+	usage(stderr)
+
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+// warn выводит в stderr ошибку разбора элемента, пропущенного при разборе ответа
+func warn(err *parsers.ParseError) {
+	fmt.Fprintln(os.Stderr, "cascade: skipped", err)
+}
+
+// usage выводит описание команд
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: cascade <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "cascade <command> -h" for command flags.`)
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/guregu/null"
+
+	cascade "github.com/vitpelekhaty/go-cascade-client/v2"
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/cmd/internal/config"
+	"github.com/vitpelekhaty/go-cascade-client/v2/csvexport"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// readingsQuery параметры запроса показаний
+type readingsQuery struct {
+	settings config.Settings
+	device   int64
+	archive  archive.DataArchive
+	from, to timeFlag
+	input    uint
+	format   string
+}
+
+// runReadings выводит показания прибора учета за период
+func runReadings(ctx context.Context, args []string, stdout io.Writer) error {
+	return runReadingsQuery(ctx, "readings", args, stdout,
+		func(ctx context.Context, conn cascade.IConnection, q *readingsQuery) ([]byte, error) {
+			return conn.CurrentReadings(ctx, q.device, q.archive, q.from.t, q.to.t, q.inputNum()...)
+		})
+}
+
+// runAltered выводит показания прибора учета, измененные за период
+func runAltered(ctx context.Context, args []string, stdout io.Writer) error {
+	return runReadingsQuery(ctx, "altered", args, stdout,
+		func(ctx context.Context, conn cascade.IConnection, q *readingsQuery) ([]byte, error) {
+			return conn.AlteredReadings(ctx, q.device, q.archive, q.from.t, q.to.t, q.inputNum()...)
+		})
+}
+
+// runReadingsQuery разбирает флаги команды name, запрашивает показания функцией fetch и выводит их
+func runReadingsQuery(ctx context.Context, name string, args []string, stdout io.Writer,
+	fetch func(ctx context.Context, conn cascade.IConnection, q *readingsQuery) ([]byte, error)) error {
+	q := &readingsQuery{archive: archive.HourArchive}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	q.settings.RegisterFlags(fs)
+
+	fs.Int64Var(&q.device, "device", 0, "device id (required)")
+	fs.Var(&q.archive, "archive", "archive type: Hour or Day")
+	fs.Var(&q.from, "from", "beginning of the period by the server clock, 24 hours before -to by default")
+	fs.Var(&q.to, "to", "end of the period by the server clock, now by default")
+	fs.UintVar(&q.input, "input", 0, "input number, all inputs by default")
+	fs.StringVar(&q.format, "format", formatTable, "output format: table, json or csv")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := q.check(time.Now()); err != nil {
+		return err
+	}
+
+	if err := q.settings.Load(); err != nil {
+		return err
+	}
+
+	conn, err := q.settings.Connect(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close(ctx)
+
+	data, err := fetch(ctx, conn, q)
+
+	if err != nil {
+		return err
+	}
+
+	readings, err := parseReadings(ctx, data)
+
+	if err != nil {
+		return err
+	}
+
+	return writeReadings(stdout, readings, q.format)
+}
+
+// check проверяет параметры запроса и устанавливает период по умолчанию относительно момента now
+func (q *readingsQuery) check(now time.Time) error {
+	if q.device == 0 {
+		return errors.New("device id is not specified (-device)")
+	}
+
+	if q.input > 255 {
+		return fmt.Errorf("invalid input number %d", q.input)
+	}
+
+	if err := checkFormat(q.format); err != nil {
+		return err
+	}
+
+	if q.to.t.IsZero() {
+		q.to.t = now
+	}
+
+	if q.from.t.IsZero() {
+		q.from.t = q.to.t.Add(-24 * time.Hour)
+	}
+
+	if !q.from.t.Before(q.to.t) {
+		return errors.New("beginning of the period must be before its end")
+	}
+
+	return nil
+}
+
+// inputNum возвращает номер теплового ввода для запроса показаний
+func (q *readingsQuery) inputNum() []byte {
+	if q.input == 0 {
+		return nil
+	}
+
+	return []byte{byte(q.input)}
+}
+
+// parseReadings разбирает показания. Записи архива с ошибкой разбора пропускаются (см. warn)
+func parseReadings(ctx context.Context, data []byte) ([]parsers.Readings, error) {
+	items, err := parsers.ParseReadings(ctx, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	readings := make([]parsers.Readings, 0)
+
+	_, err = parsers.EachReadings(ctx, items, func(r *parsers.Readings) error {
+		readings = append(readings, *r)
+		return nil
+	}, parsers.WithSkip(warn))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return readings, nil
+}
+
+// writeReadings выводит показания в формате format
+func writeReadings(w io.Writer, readings []parsers.Readings, format string) error {
+	switch format {
+	case formatJSON:
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+
+		return e.Encode(readings)
+
+	case formatCSV:
+		cw, err := csvexport.NewReadingsWriter(w)
+
+		if err != nil {
+			return err
+		}
+
+		for i := range readings {
+			if err := cw.Write(&readings[i]); err != nil {
+				return err
+			}
+		}
+
+		return cw.Flush()
+
+	default:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+
+		fmt.Fprintln(tw, "DT\tINPUT\tCHANNEL\tM\tV\tP\tT\tTCW\tTI\tQ\tQ1\tQ2\tBAD\t")
+
+		for _, r := range readings {
+			bad := ""
+
+			if r.IsBadRow {
+				bad = "*"
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+				time.Time(r.DT).Format("2006-01-02 15:04"), integer(r.Input), integer(r.ChannelNum),
+				float(r.M), float(r.V), float(r.P), float(r.T), float(r.TCW), float(r.TI), float(r.Q), float(r.Q1),
+				float(r.Q2), bad)
+		}
+
+		return tw.Flush()
+	}
+}
+
+// integer возвращает строковое представление целого значения показания
+func integer(v null.Int) string {
+	if !v.Valid {
+		return "-"
+	}
+
+	return fmt.Sprint(v.Int64)
+}
+
+// float возвращает строковое представление вещественного значения показания
+func float(v null.Float) string {
+	if !v.Valid {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.3f", v.Float64)
+}
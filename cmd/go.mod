@@ -0,0 +1,28 @@
+module github.com/vitpelekhaty/go-cascade-client/v2/cmd
+
+go 1.24.0
+
+require (
+	github.com/guregu/null v4.0.0+incompatible
+	github.com/joho/godotenv v1.4.0
+	github.com/stretchr/testify v1.7.1
+	github.com/vitpelekhaty/go-cascade-client/v2 v2.1.0
+	github.com/vitpelekhaty/go-cascade-client/v2/storage/sqlite v1.0.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	golang.org/x/exp v0.0.0-20250620022241-b7579e27df2b // indirect
+	golang.org/x/sys v0.36.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+	modernc.org/libc v1.66.10 // indirect
+	modernc.org/mathutil v1.7.1 // indirect
+	modernc.org/memory v1.11.0 // indirect
+	modernc.org/sqlite v1.40.1 // indirect
+)
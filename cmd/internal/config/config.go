@@ -0,0 +1,201 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	cascade "github.com/vitpelekhaty/go-cascade-client/v2"
+)
+
+// Переменные окружения параметров соединения с API Каскада
+const (
+	EnvURL                = "CASCADE_URL"
+	EnvAuthURL            = "CASCADE_AUTH_URL"
+	EnvUsername           = "CASCADE_USERNAME"
+	EnvPassword           = "CASCADE_USER_PASSWD"
+	EnvTimeout            = "CASCADE_TIMEOUT"
+	EnvInsecureSkipVerify = "CASCADE_INSECURE_SKIP_VERIFY"
+
+	// EnvTestTimeout и EnvTestInsecureSkipVerify переменные окружения интеграционных тестов. Используются, если не
+	// заданы переменные EnvTimeout и EnvInsecureSkipVerify, чтобы один файл .env подходил и для тестов, и для
+	// утилит
+	EnvTestTimeout            = "CASCADE_TEST_PARAM_TIMEOUT"
+	EnvTestInsecureSkipVerify = "CASCADE_TEST_PARAM_INSECURE_SKIP_VERIFY"
+)
+
+// defaultTimeout время ожидания ответа API Каскада по умолчанию
+const defaultTimeout = 30 * time.Second
+
+// Settings параметры соединения с API Каскада.
+//
+// Параметры задаются флагами командной строки, переменными окружения CASCADE_* или файлом .env (в порядке
+// убывания приоритета)
+type Settings struct {
+	// EnvFile путь к файлу с переменными окружения. Если не указан, используется файл .env в текущем каталоге,
+	// если он существует
+	EnvFile string
+
+	// URL адрес API Каскада
+	URL string
+
+	// AuthURL альтернативный адрес авторизации в API Каскада
+	AuthURL string
+
+	// Username имя пользователя
+	Username string
+
+	// Password пароль пользователя
+	Password string
+
+	// Timeout время ожидания ответа API Каскада
+	Timeout time.Duration
+
+	// InsecureSkipVerify признак отключения проверки сертификата сервера
+	InsecureSkipVerify bool
+
+	timeout  string
+	insecure string
+}
+
+// RegisterFlags регистрирует флаги параметров соединения в наборе флагов fs
+func (s *Settings) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&s.EnvFile, "env", "", "path to the .env file with CASCADE_* variables")
+	fs.StringVar(&s.URL, "url", "", "Cascade API URL ($"+EnvURL+")")
+	fs.StringVar(&s.AuthURL, "auth-url", "", "alternative Cascade authorization URL ($"+EnvAuthURL+")")
+	fs.StringVar(&s.Username, "username", "", "user name ($"+EnvUsername+")")
+	fs.StringVar(&s.Password, "password", "", "user password ($"+EnvPassword+")")
+	fs.StringVar(&s.timeout, "timeout", "", "API response timeout, 30s by default ($"+EnvTimeout+")")
+	fs.StringVar(&s.insecure, "insecure", "", "skip server certificate verification ($"+EnvInsecureSkipVerify+")")
+}
+
+// Load дополняет параметры, не указанные флагами, значениями переменных окружения и файла .env и проверяет их
+func (s *Settings) Load() error {
+	if err := loadEnv(s.EnvFile); err != nil {
+		return err
+	}
+
+	fromEnv(&s.URL, EnvURL)
+	fromEnv(&s.AuthURL, EnvAuthURL)
+	fromEnv(&s.Username, EnvUsername)
+	fromEnv(&s.Password, EnvPassword)
+	fromEnv(&s.timeout, EnvTimeout, EnvTestTimeout)
+	fromEnv(&s.insecure, EnvInsecureSkipVerify, EnvTestInsecureSkipVerify)
+
+	s.Timeout = defaultTimeout
+
+	if s.timeout != "" {
+		timeout, err := time.ParseDuration(s.timeout)
+
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %w", s.timeout, err)
+		}
+
+		s.Timeout = timeout
+	}
+
+	if s.insecure != "" {
+		insecure, err := strconv.ParseBool(s.insecure)
+
+		if err != nil {
+			return fmt.Errorf("invalid insecure flag %q: %w", s.insecure, err)
+		}
+
+		s.InsecureSkipVerify = insecure
+	}
+
+	return s.validate()
+}
+
+// validate проверяет параметры соединения
+func (s *Settings) validate() error {
+	if s.URL == "" {
+		return fmt.Errorf("cascade URL is not specified (-url or %s)", EnvURL)
+	}
+
+	if _, err := url.Parse(s.URL); err != nil {
+		return fmt.Errorf("invalid cascade URL: %w", err)
+	}
+
+	if s.AuthURL != "" {
+		if _, err := url.Parse(s.AuthURL); err != nil {
+			return fmt.Errorf("invalid authorization URL: %w", err)
+		}
+	}
+
+	if s.Username == "" {
+		return fmt.Errorf("user name is not specified (-username or %s)", EnvUsername)
+	}
+
+	return nil
+}
+
+// HTTPClient возвращает HTTP клиент соединения
+func (s *Settings) HTTPClient() *http.Client {
+	client := &http.Client{
+		Timeout: s.Timeout,
+	}
+
+	if s.InsecureSkipVerify {
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+
+	return client
+}
+
+// Connect открывает соединение с API Каскада. Опции options дополняют опцию HTTP клиента соединения
+func (s *Settings) Connect(ctx context.Context, options ...cascade.Option) (cascade.IConnection, error) {
+	conn, err := cascade.NewConnection(append([]cascade.Option{cascade.WithHTTPClient(s.HTTPClient())},
+		options...)...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var openOptions []cascade.OpenOption
+
+	if s.AuthURL != "" {
+		openOptions = append(openOptions, cascade.WithAuthURL(s.AuthURL))
+	}
+
+	if err := conn.Open(ctx, s.URL, s.Username, s.Password, openOptions...); err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// loadEnv загружает переменные окружения из файла path. Переменные, уже заданные в окружении, не изменяются
+func loadEnv(path string) error {
+	if path != "" {
+		return godotenv.Load(path)
+	}
+
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// fromEnv устанавливает значение value из первой непустой переменной окружения keys, если значение не указано
+func fromEnv(value *string, keys ...string) {
+	for _, key := range keys {
+		if *value != "" {
+			return
+		}
+
+		*value = os.Getenv(key)
+	}
+}
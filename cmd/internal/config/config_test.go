@@ -0,0 +1,79 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func unsetEnv(t *testing.T) {
+	for _, key := range []string{EnvURL, EnvAuthURL, EnvUsername, EnvPassword, EnvTimeout, EnvInsecureSkipVerify,
+		EnvTestTimeout, EnvTestInsecureSkipVerify} {
+		t.Setenv(key, "")
+		require.NoError(t, os.Unsetenv(key))
+	}
+}
+
+func TestSettings_Load(t *testing.T) {
+	env := filepath.Join(t.TempDir(), "test.env")
+
+	err := os.WriteFile(env, []byte("CASCADE_URL=https://cascade.example/api\n"+
+		"CASCADE_USERNAME=file\nCASCADE_USER_PASSWD=secret\nCASCADE_TIMEOUT=1m\n"), 0o600)
+
+	require.NoError(t, err)
+
+	unsetEnv(t)
+
+	t.Setenv(EnvInsecureSkipVerify, "true")
+
+	var settings Settings
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	settings.RegisterFlags(fs)
+
+	err = fs.Parse([]string{"-env", env, "-username", "flag"})
+
+	require.NoError(t, err)
+	require.NoError(t, settings.Load())
+
+	assert.Equal(t, "https://cascade.example/api", settings.URL)
+	assert.Equal(t, "flag", settings.Username)
+	assert.Equal(t, "secret", settings.Password)
+	assert.Equal(t, time.Minute, settings.Timeout)
+	assert.True(t, settings.InsecureSkipVerify)
+
+	settings = Settings{timeout: "soon"}
+
+	assert.Error(t, settings.Load())
+}
+
+func TestSettings_LoadTestParams(t *testing.T) {
+	unsetEnv(t)
+
+	t.Setenv(EnvURL, "https://cascade.example/api")
+	t.Setenv(EnvUsername, "user")
+	t.Setenv(EnvTestTimeout, "2m")
+	t.Setenv(EnvTestInsecureSkipVerify, "true")
+
+	settings := Settings{EnvFile: os.DevNull}
+
+	require.NoError(t, settings.Load())
+
+	assert.Equal(t, 2*time.Minute, settings.Timeout)
+	assert.True(t, settings.InsecureSkipVerify)
+
+	t.Setenv(EnvTimeout, "1m")
+	t.Setenv(EnvInsecureSkipVerify, "false")
+
+	settings = Settings{EnvFile: os.DevNull}
+
+	require.NoError(t, settings.Load())
+
+	assert.Equal(t, time.Minute, settings.Timeout)
+	assert.False(t, settings.InsecureSkipVerify)
+}
@@ -0,0 +1,257 @@
+package completeness
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+// Report отчет о полноте показаний за период
+type Report struct {
+	// Archive тип архива показаний
+	Archive archive.DataArchive
+
+	// From начало периода
+	From time.Time
+
+	// To окончание периода
+	To time.Time
+
+	// Devices отчеты по приборам учета
+	Devices []*DeviceReport
+
+	// Unmatched количество показаний, канал которых не найден в списке приборов учета
+	Unmatched int
+}
+
+// DeviceReport отчет о полноте показаний прибора учета
+type DeviceReport struct {
+	// DeviceID идентификатор прибора учета
+	DeviceID int64
+
+	// Title наименование прибора учета в АИСКУТЭ
+	Title string
+
+	// Channels отчеты по каналам прибора учета
+	Channels []*ChannelReport
+
+	// Expected ожидаемое количество показаний по всем каналам
+	Expected int
+
+	// Present количество полученных показаний по всем каналам
+	Present int
+
+	// BadRows количество "плохих" строк показаний по всем каналам
+	BadRows int
+
+	// EmptyRows количество "пустых" строк показаний по всем каналам
+	EmptyRows int
+}
+
+// Completeness возвращает полноту показаний прибора учета в процентах. Если показания не ожидались, возвращается 100
+func (r *DeviceReport) Completeness() float64 {
+	return percent(r.Present, r.Expected)
+}
+
+// ChannelReport отчет о полноте показаний канала
+type ChannelReport struct {
+	// ChannelID идентификатор канала
+	ChannelID int64
+
+	// Input номер теплового ввода
+	Input int32
+
+	// Number номер канала
+	Number int32
+
+	// Resource тип ресурса
+	Resource parsers.Resource
+
+	// Flow тип подключения
+	Flow parsers.Flow
+
+	// Expected ожидаемое количество показаний
+	Expected int
+
+	// Present количество полученных показаний (без учета "пустых" строк и повторов)
+	Present int
+
+	// BadRows количество "плохих" строк показаний
+	BadRows int
+
+	// EmptyRows количество "пустых" строк показаний
+	EmptyRows int
+
+	// Duplicates количество повторных показаний за один и тот же момент времени
+	Duplicates int
+
+	// Unexpected количество показаний вне периода или не совпадающих с началом интервала архива
+	Unexpected int
+
+	// Missing моменты времени, за которые нет показаний
+	Missing []time.Time
+}
+
+// Completeness возвращает полноту показаний канала в процентах. Если показания не ожидались, возвращается 100
+func (r *ChannelReport) Completeness() float64 {
+	return percent(r.Present, r.Expected)
+}
+
+// Analyze анализирует полноту показаний readings архива a за период с from по to включительно для приборов учета
+// из topo.
+//
+// Ожидаемые моменты показаний - начала интервалов архива, попадающие в период. Время в показаниях Каскада не
+// содержит часового пояса, поэтому моменты сравниваются по показаниям часов: часовой пояс from и to не учитывается.
+// "Пустая" строка считается пропуском показания
+func Analyze(topo *topology.Topology, a archive.DataArchive, from, to time.Time, readings []parsers.Readings,
+	opts ...Option) (*Report, error) {
+	if !a.Periodic() {
+		return nil, fmt.Errorf("cannot analyze completeness of %s archive", a)
+	}
+
+	options := &analyzeOptions{}
+
+	for _, option := range opts {
+		option(options)
+	}
+
+	from, to = parsers.WallClock(from), parsers.WallClock(to)
+
+	expected := make([]time.Time, 0)
+
+	for t := a.Truncate(from); !t.After(to); t = a.Next(t) {
+		if !t.Before(from) {
+			expected = append(expected, t)
+		}
+	}
+
+	report := &Report{Archive: a, From: from, To: to}
+
+	channels := make(map[int64]*channelState)
+
+	for _, device := range topo.Devices() {
+		if !options.includes(device.ID) {
+			continue
+		}
+
+		deviceReport := &DeviceReport{DeviceID: device.ID, Title: device.Title}
+
+		for _, input := range device.Inputs {
+			if !options.includesInput(input.Number) {
+				continue
+			}
+
+			for _, channel := range topo.Channels(device.ID, input.Number) {
+				channelReport := &ChannelReport{
+					ChannelID: channel.Channel.ID,
+					Input:     input.Number,
+					Number:    channel.Channel.Number,
+					Resource:  channel.Resource(),
+					Flow:      channel.Flow(),
+					Expected:  len(expected),
+				}
+
+				deviceReport.Channels = append(deviceReport.Channels, channelReport)
+
+				channels[channelReport.ChannelID] = &channelState{
+					report: channelReport,
+					seen:   make(map[int64]bool, len(expected)),
+				}
+			}
+		}
+
+		report.Devices = append(report.Devices, deviceReport)
+	}
+
+	for i := range readings {
+		r := &readings[i]
+
+		channel, ok := topo.ChannelOf(r)
+
+		if !ok {
+			report.Unmatched++
+			continue
+		}
+
+		state, ok := channels[channel.Channel.ID]
+
+		if !ok {
+			continue
+		}
+
+		state.add(r, a, from, to)
+	}
+
+	for _, device := range report.Devices {
+		for _, channel := range device.Channels {
+			state := channels[channel.ChannelID]
+
+			for _, t := range expected {
+				if !state.seen[t.UnixNano()] {
+					channel.Missing = append(channel.Missing, t)
+				}
+			}
+
+			device.Expected += channel.Expected
+			device.Present += channel.Present
+			device.BadRows += channel.BadRows
+			device.EmptyRows += channel.EmptyRows
+		}
+
+		sort.SliceStable(device.Channels, func(i, j int) bool {
+			a, b := device.Channels[i], device.Channels[j]
+
+			if a.Input != b.Input {
+				return a.Input < b.Input
+			}
+
+			return a.Number < b.Number
+		})
+	}
+
+	return report, nil
+}
+
+// channelState состояние анализа показаний канала
+type channelState struct {
+	report *ChannelReport
+	seen   map[int64]bool
+}
+
+func (s *channelState) add(r *parsers.Readings, a archive.DataArchive, from, to time.Time) {
+	if r.IsBadRow {
+		s.report.BadRows++
+	}
+
+	if r.Empty.Valid && r.Empty.Bool {
+		s.report.EmptyRows++
+		return
+	}
+
+	dt := parsers.WallClock(time.Time(r.DT))
+
+	if dt.Before(from) || dt.After(to) || !a.Truncate(dt).Equal(dt) {
+		s.report.Unexpected++
+		return
+	}
+
+	if s.seen[dt.UnixNano()] {
+		s.report.Duplicates++
+		return
+	}
+
+	s.seen[dt.UnixNano()] = true
+	s.report.Present++
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+
+	return float64(part) * 100 / float64(total)
+}
@@ -0,0 +1,110 @@
+package completeness
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+func testTopology() *topology.Topology {
+	return topology.New([]parsers.Gauge{
+		{
+			ID:    12032,
+			Title: "test",
+			Inputs: []parsers.Input{
+				{
+					Number: 1,
+					Channels: []parsers.Channel{
+						{ID: 19265, Number: 1, Resource: parsers.ResourceHeat, Flow: parsers.FlowDirect},
+						{ID: 19266, Number: 2, Resource: parsers.ResourceHeat, Flow: parsers.FlowReverse},
+						{ID: 19288, Number: 0, Resource: parsers.ResourceNone},
+					},
+				},
+			},
+		},
+		{ID: 1},
+	})
+}
+
+func loadReadings(t *testing.T) []parsers.Readings {
+	path, err := filepath.Abs("../testdata/responses/readings200.json")
+
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path)
+
+	require.NoError(t, err)
+
+	items, err := parsers.ParseReadings(context.TODO(), data)
+
+	require.NoError(t, err)
+
+	readings := make([]parsers.Readings, 0)
+
+	for item := range items {
+		require.NoError(t, item.E)
+
+		readings = append(readings, *item.V.(*parsers.Readings))
+	}
+
+	return readings
+}
+
+func TestAnalyze(t *testing.T) {
+	readings := loadReadings(t)
+
+	// пропуск, повтор, "плохая" и "пустая" строки по каналу 19265
+	readings = append(readings[1:], readings[1], parsers.Readings{ChannelID: null.IntFrom(-1)})
+	readings[2].IsBadRow = true
+	readings[3].Empty = null.BoolFrom(true)
+
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	from := time.Date(2021, 4, 11, 0, 0, 0, 0, loc)
+	to := time.Date(2021, 4, 18, 1, 0, 0, 0, loc)
+
+	report, err := Analyze(testTopology(), archive.HourArchive, from, to, readings, WithDevices(12032))
+
+	require.NoError(t, err)
+	require.Len(t, report.Devices, 1)
+	assert.Equal(t, 1, report.Unmatched)
+
+	device := report.Devices[0]
+
+	require.Len(t, device.Channels, 3)
+	assert.Equal(t, 3*170, device.Expected)
+	assert.Equal(t, 169*3-2, device.Present)
+	assert.Equal(t, 1, device.BadRows)
+	assert.Equal(t, 1, device.EmptyRows)
+	assert.InDelta(t, float64(169*3-2)*100/(3*170), device.Completeness(), 1e-9)
+
+	channel := device.Channels[1]
+
+	assert.Equal(t, int64(19265), channel.ChannelID)
+	assert.Equal(t, 1, channel.Duplicates)
+	assert.Equal(t, []time.Time{
+		time.Date(2021, 4, 11, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 4, 11, 1, 0, 0, 0, time.UTC),
+		time.Date(2021, 4, 11, 5, 0, 0, 0, time.UTC),
+	}, channel.Missing)
+
+	report, err = Analyze(testTopology(), archive.DailyArchive, from, to, nil)
+
+	require.NoError(t, err)
+	require.Len(t, report.Devices, 2)
+	assert.Equal(t, 0.0, report.Devices[0].Completeness())
+	assert.Equal(t, 100.0, report.Devices[1].Completeness())
+
+	_, err = Analyze(testTopology(), archive.TotalArchive, from, to, readings)
+
+	assert.Error(t, err)
+}
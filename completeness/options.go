@@ -0,0 +1,43 @@
+package completeness
+
+type analyzeOptions struct {
+	devices map[int64]bool
+	inputs  map[int32]bool
+}
+
+// Option опция анализа полноты показаний
+type Option func(options *analyzeOptions)
+
+// WithDevices ограничивает анализ указанными приборами учета. По умолчанию анализируются все приборы учета
+func WithDevices(ids ...int64) Option {
+	return func(options *analyzeOptions) {
+		if options.devices == nil {
+			options.devices = make(map[int64]bool, len(ids))
+		}
+
+		for _, id := range ids {
+			options.devices[id] = true
+		}
+	}
+}
+
+// WithInputs ограничивает анализ указанными тепловыми вводами. По умолчанию анализируются все тепловые вводы
+func WithInputs(numbers ...int32) Option {
+	return func(options *analyzeOptions) {
+		if options.inputs == nil {
+			options.inputs = make(map[int32]bool, len(numbers))
+		}
+
+		for _, number := range numbers {
+			options.inputs[number] = true
+		}
+	}
+}
+
+func (o *analyzeOptions) includes(deviceID int64) bool {
+	return o.devices == nil || o.devices[deviceID]
+}
+
+func (o *analyzeOptions) includesInput(number int32) bool {
+	return o.inputs == nil || o.inputs[number]
+}
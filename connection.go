@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
@@ -40,7 +41,8 @@ type IConnection interface {
 		endCreateAt time.Time, inputNum ...byte) ([]byte, error)
 }
 
-// NewConnection возвращает настроенное соединение с Каскадом
+// NewConnection возвращает настроенное соединение с Каскадом. Запросы к методам API открытого соединения
+// допускают одновременный вызов из нескольких горутин
 func NewConnection(options ...Option) (IConnection, error) {
 	opts := &connOptions{}
 
@@ -50,6 +52,7 @@ func NewConnection(options ...Option) (IConnection, error) {
 
 	conn := &connection{
 		client: opts.client,
+		cache:  opts.gaugesCache,
 	}
 
 	if conn.client == nil {
@@ -64,8 +67,16 @@ var _ IConnection = (*connection)(nil)
 type connection struct {
 	rawURL, authURL string
 	secret          string
+	username        string
 	client          *http.Client
-	token           *token
+	cache           *GaugesCache
+
+	// mu защищает token, который обновляется при повторной авторизации во время запросов
+	mu    sync.RWMutex
+	token *token
+
+	// relogging сериализует повторную авторизацию (см. relogin)
+	relogging sync.Mutex
 }
 
 // Open открывает соединение с API Каскада
@@ -90,6 +101,7 @@ func (conn *connection) Open(ctx context.Context, rawURL, username, passwd strin
 	}
 
 	conn.secret = secret(username, passwd)
+	conn.username = username
 
 	return conn.login(ctx, conn.authURL, conn.secret)
 }
@@ -139,28 +151,51 @@ func (conn *connection) login(ctx context.Context, authURL string, secret string
 		return fmt.Errorf("POST %s: %v", authURL, err)
 	}
 
+	conn.mu.Lock()
 	conn.token = &t
+	conn.mu.Unlock()
 
 	return nil
 }
 
+// relogin повторно авторизуется на сервере, если токен сессии, с которым запрос завершился ошибкой 401, - stale.
+// Запросы, одновременно получившие ошибку 401, ожидают одной повторной авторизации и используют ее токен
+func (conn *connection) relogin(ctx context.Context, stale string) error {
+	conn.relogging.Lock()
+	defer conn.relogging.Unlock()
+
+	if conn.authorization() != stale {
+		return nil
+	}
+
+	return conn.login(ctx, conn.authURL, conn.secret)
+}
+
 // Close закрывает соединение с API Каскада
 func (conn *connection) Close(_ context.Context) error {
+	conn.mu.Lock()
 	conn.token = nil
+	conn.mu.Unlock()
+
 	conn.secret = ""
+	conn.username = ""
 
 	return nil
 }
 
 // Connected возвращает признак установленного соединения
 func (conn *connection) Connected() bool {
+	conn.mu.RLock()
+	defer conn.mu.RUnlock()
+
 	return conn.token != nil
 }
 
 // methodGauges метод получения списка приборов учета
 const methodGauges = "/api/cascade/counter-house"
 
-// Gauges возвращает список доступных приборов учета с тепловыми вводами и каналами
+// Gauges возвращает список доступных приборов учета с тепловыми вводами и каналами. Если для соединения задан кэш
+// списка приборов учета (WithGaugesCache), список возвращается из кэша
 func (conn *connection) Gauges(ctx context.Context) ([]byte, error) {
 	if err := conn.checkConnection(); err != nil {
 		return nil, fmt.Errorf("GET %s: %v", methodGauges, err)
@@ -172,21 +207,62 @@ func (conn *connection) Gauges(ctx context.Context) ([]byte, error) {
 		return nil, fmt.Errorf("GET %s: %v", methodGauges, err)
 	}
 
+	if conn.cache != nil {
+		return conn.cache.gauges(ctx, methodURL, conn.username, conn.fetchGauges)
+	}
+
+	resp, err := conn.fetchGauges(ctx, methodURL, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.data, nil
+}
+
+// gaugesResponse ответ метода получения списка приборов учета
+type gaugesResponse struct {
+	// data список приборов учета. Не указывается, если список не изменился
+	data []byte
+
+	// etag, lastModified значения заголовков ETag и Last-Modified ответа
+	etag, lastModified string
+
+	// notModified признак ответа 304 Not Modified на условный запрос
+	notModified bool
+}
+
+// fetchGauges запрашивает список приборов учета. Если указан кэшированный список cached, запрос выполняется как
+// условный по его значениям ETag и Last-Modified
+func (conn *connection) fetchGauges(ctx context.Context, methodURL string, cached *CachedGauges) (*gaugesResponse,
+	error) {
 	var headers = map[string]string{
-		"Authorization": fmt.Sprintf("%s %s", conn.token.Type, conn.token.Value),
+		"Authorization": conn.authorization(),
 	}
 
-	data, statusCode, err := conn.gauges(ctx, methodURL, headers)
+	if cached != nil {
+		if cached.ETag != "" {
+			headers["If-None-Match"] = cached.ETag
+		}
+
+		if cached.LastModified != "" {
+			headers["If-Modified-Since"] = cached.LastModified
+		}
+	}
+
+	resp, statusCode, err := conn.gauges(ctx, methodURL, headers)
 
 	if err != nil {
 		if statusCode == http.StatusUnauthorized {
-			err = conn.login(ctx, conn.authURL, conn.secret)
+			err = conn.relogin(ctx, headers["Authorization"])
 
 			if err != nil {
 				return nil, fmt.Errorf("GET %s: %v", methodGauges, err)
 			}
 
-			data, _, err = conn.gauges(ctx, methodURL, headers)
+			headers["Authorization"] = conn.authorization()
+
+			resp, _, err = conn.gauges(ctx, methodURL, headers)
 
 			if err != nil {
 				return nil, fmt.Errorf("GET %s: %v", methodGauges, err)
@@ -196,10 +272,11 @@ func (conn *connection) Gauges(ctx context.Context) ([]byte, error) {
 		}
 	}
 
-	return data, nil
+	return resp, nil
 }
 
-func (conn *connection) gauges(ctx context.Context, rawURL string, headers map[string]string) ([]byte, int, error) {
+func (conn *connection) gauges(ctx context.Context, rawURL string, headers map[string]string) (*gaugesResponse, int,
+	error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
 
 	if err != nil {
@@ -222,17 +299,27 @@ func (conn *connection) gauges(ctx context.Context, rawURL string, headers map[s
 		_ = resp.Body.Close()
 	}()
 
+	result := &gaugesResponse{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		result.notModified = true
+		return result, resp.StatusCode, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, resp.StatusCode, errors.New(resp.Status)
 	}
 
-	data, err := ioutil.ReadAll(resp.Body)
+	result.data, err = ioutil.ReadAll(resp.Body)
 
 	if err != nil {
 		return nil, resp.StatusCode, err
 	}
 
-	return data, resp.StatusCode, nil
+	return result, resp.StatusCode, nil
 }
 
 // methodCurrentReadings метод чтения архива показаний прибора учета
@@ -270,7 +357,7 @@ func (conn *connection) CurrentReadings(ctx context.Context, deviceID int64, arc
 	}
 
 	var headers = map[string]string{
-		"Authorization": fmt.Sprintf("%s %s", conn.token.Type, conn.token.Value),
+		"Authorization": conn.authorization(),
 		"Content-Type":  "application/json",
 	}
 
@@ -278,12 +365,14 @@ func (conn *connection) CurrentReadings(ctx context.Context, deviceID int64, arc
 
 	if err != nil {
 		if statusCode == http.StatusUnauthorized {
-			err = conn.login(ctx, conn.authURL, conn.secret)
+			err = conn.relogin(ctx, headers["Authorization"])
 
 			if err != nil {
 				return nil, fmt.Errorf("GET %s: %v", methodCurrentReadings, err)
 			}
 
+			headers["Authorization"] = conn.authorization()
+
 			data, statusCode, err = conn.readings(ctx, methodURL, headers, reqData)
 
 			if err != nil {
@@ -385,7 +474,7 @@ func (conn *connection) AlteredReadings(ctx context.Context, deviceID int64, arc
 	}
 
 	var headers = map[string]string{
-		"Authorization": fmt.Sprintf("%s %s", conn.token.Type, conn.token.Value),
+		"Authorization": conn.authorization(),
 		"Content-Type":  "application/json",
 	}
 
@@ -393,12 +482,14 @@ func (conn *connection) AlteredReadings(ctx context.Context, deviceID int64, arc
 
 	if err != nil {
 		if statusCode == http.StatusUnauthorized {
-			err = conn.login(ctx, conn.authURL, conn.secret)
+			err = conn.relogin(ctx, headers["Authorization"])
 
 			if err != nil {
 				return nil, fmt.Errorf("GET %s: %v", methodAlteredReadings, err)
 			}
 
+			headers["Authorization"] = conn.authorization()
+
 			data, statusCode, err = conn.readings(ctx, methodURL, headers, reqData)
 
 			if err != nil {
@@ -428,8 +519,20 @@ func (conn *connection) AlteredReadings(ctx context.Context, deviceID int64, arc
 	return data, nil
 }
 
-func (conn *connection) checkConnection() error {
+// authorization возвращает значение заголовка авторизации запросов к методам API
+func (conn *connection) authorization() string {
+	conn.mu.RLock()
+	defer conn.mu.RUnlock()
+
 	if conn.token == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s %s", conn.token.Type, conn.token.Value)
+}
+
+func (conn *connection) checkConnection() error {
+	if !conn.Connected() {
 		return errors.New("user not authorized")
 	}
 
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -20,6 +21,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/cassette"
 	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
 	"github.com/vitpelekhaty/httptracer"
 )
@@ -37,14 +39,18 @@ var (
 	casesPath             string
 )
 
-var envFile = flag.String("env", "", "параметры теста")
+var (
+	envFile      = flag.String("env", "", "параметры теста")
+	cassetteMode = flag.String("cassette", "", "режим кассет (record, replay или auto), по умолчанию replay без -env")
+)
+
+// cassettesDir каталог кассет интеграционных тестов
+const cassettesDir = "testdata/cassettes"
 
 func TestConnection_Gauges(t *testing.T) {
-	flag.Parse()
+	recorder := setupCassette(t)
 
-	err := godotenv.Load(*envFile)
-
-	require.NoError(t, err)
+	loadEnv(t, recorder)
 
 	cascadeURL = os.Getenv("CASCADE_URL")
 	authURL = os.Getenv("CASCADE_AUTH_URL")
@@ -55,9 +61,11 @@ func TestConnection_Gauges(t *testing.T) {
 	tracePath = os.Getenv("CASCADE_TRACE_PATH")
 	strBodies = os.Getenv("CASCADE_TRACE_PARAM_BODIES")
 
+	restoreEnv(recorder)
+
 	require.NotEmpty(t, cascadeURL)
 
-	_, err = url.Parse(cascadeURL)
+	_, err := url.Parse(cascadeURL)
 
 	require.NoError(t, err)
 
@@ -122,6 +130,8 @@ func TestConnection_Gauges(t *testing.T) {
 		client = setupTracer(client, setupTracerOptions(bodies, callbackFunc)...)
 	}
 
+	client = recordClient(t, recorder, client)
+
 	c, err := NewConnection(WithHTTPClient(client))
 
 	require.NoError(t, err)
@@ -169,16 +179,10 @@ type gaugeTestCase struct {
 	To null.Time `json:"to"`
 }
 
-func loadTestCases(path string) ([]gaugeTestCase, error) {
-	b, err := os.ReadFile(path)
-
-	if err != nil {
-		return nil, err
-	}
-
+func parseTestCases(b []byte) ([]gaugeTestCase, error) {
 	var cases = make([]gaugeTestCase, 0, 10)
 
-	err = json.Unmarshal(b, &cases)
+	err := json.Unmarshal(b, &cases)
 
 	if err != nil {
 		return nil, err
@@ -188,11 +192,9 @@ func loadTestCases(path string) ([]gaugeTestCase, error) {
 }
 
 func TestConnection_CurrentReadings(t *testing.T) {
-	flag.Parse()
+	recorder := setupCassette(t)
 
-	err := godotenv.Load(*envFile)
-
-	require.NoError(t, err)
+	loadEnv(t, recorder)
 
 	cascadeURL = os.Getenv("CASCADE_URL")
 	authURL = os.Getenv("CASCADE_AUTH_URL")
@@ -205,16 +207,13 @@ func TestConnection_CurrentReadings(t *testing.T) {
 	strBodies = os.Getenv("CASCADE_TRACE_PARAM_BODIES")
 	casesPath = os.Getenv("CASCADE_TEST_CASES_PATH")
 
-	require.NotEmpty(t, casesPath)
+	restoreEnv(recorder)
 
-	cases, err := loadTestCases(casesPath)
-
-	require.NoError(t, err)
-	require.NotEmpty(t, cases)
+	cases := setupTestCases(t, recorder)
 
 	require.NotEmpty(t, cascadeURL)
 
-	_, err = url.Parse(cascadeURL)
+	_, err := url.Parse(cascadeURL)
 
 	require.NoError(t, err)
 
@@ -283,6 +282,8 @@ func TestConnection_CurrentReadings(t *testing.T) {
 		client = setupTracer(client, setupTracerOptions(bodies, callbackFunc)...)
 	}
 
+	client = recordClient(t, recorder, client)
+
 	c, err := NewConnection(WithHTTPClient(client))
 
 	require.NoError(t, err)
@@ -299,7 +300,7 @@ func TestConnection_CurrentReadings(t *testing.T) {
 	}()
 
 	for _, test := range cases {
-		var to = time.Now()
+		var to = now(recorder)
 
 		if test.To.Valid {
 			to = test.To.ValueOrZero()
@@ -334,11 +335,9 @@ func TestConnection_CurrentReadings(t *testing.T) {
 }
 
 func TestConnection_AlteredReadings(t *testing.T) {
-	flag.Parse()
-
-	err := godotenv.Load(*envFile)
+	recorder := setupCassette(t)
 
-	require.NoError(t, err)
+	loadEnv(t, recorder)
 
 	cascadeURL = os.Getenv("CASCADE_URL")
 	authURL = os.Getenv("CASCADE_AUTH_URL")
@@ -351,16 +350,13 @@ func TestConnection_AlteredReadings(t *testing.T) {
 	strBodies = os.Getenv("CASCADE_TRACE_PARAM_BODIES")
 	casesPath = os.Getenv("CASCADE_TEST_CASES_PATH")
 
-	require.NotEmpty(t, casesPath)
+	restoreEnv(recorder)
 
-	cases, err := loadTestCases(casesPath)
-
-	require.NoError(t, err)
-	require.NotEmpty(t, cases)
+	cases := setupTestCases(t, recorder)
 
 	require.NotEmpty(t, cascadeURL)
 
-	_, err = url.Parse(cascadeURL)
+	_, err := url.Parse(cascadeURL)
 
 	require.NoError(t, err)
 
@@ -429,6 +425,8 @@ func TestConnection_AlteredReadings(t *testing.T) {
 		client = setupTracer(client, setupTracerOptions(bodies, callbackFunc)...)
 	}
 
+	client = recordClient(t, recorder, client)
+
 	c, err := NewConnection(WithHTTPClient(client))
 
 	require.NoError(t, err)
@@ -445,7 +443,7 @@ func TestConnection_AlteredReadings(t *testing.T) {
 	}()
 
 	for _, test := range cases {
-		var to = time.Now()
+		var to = now(recorder)
 
 		if test.To.Valid {
 			to = test.To.ValueOrZero()
@@ -512,3 +510,122 @@ func setupTracerOptions(withBodies bool, withCallback httptracer.CallbackFunc) [
 
 	return options
 }
+
+// setupCassette возвращает Recorder кассеты теста в режиме, указанном флагом -cassette, или nil, если кассеты не
+// используются. Кассета записывается в файл по завершении теста
+// setupCassette возвращает кассету теста в режиме -cassette. Если режим не указан, тест без параметров сервера
+// (-env или CASCADE_URL) воспроизводит кассету, а с параметрами - обращается к серверу без кассеты
+func setupCassette(t *testing.T) *cassette.Recorder {
+	flag.Parse()
+
+	modeName := *cassetteMode
+
+	if modeName == "" {
+		if *envFile != "" || os.Getenv("CASCADE_URL") != "" {
+			return nil
+		}
+
+		modeName = cassette.ModeReplay.String()
+	}
+
+	mode, err := cassette.ParseMode(modeName)
+
+	require.NoError(t, err)
+
+	recorder, err := cassette.New(filepath.Join(cassettesDir, t.Name()+".json"), mode)
+
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, recorder.Stop())
+	})
+
+	return recorder
+}
+
+func replaying(recorder *cassette.Recorder) bool {
+	return recorder != nil && recorder.Mode() == cassette.ModeReplay
+}
+
+// loadEnv загружает параметры теста. При воспроизведении кассеты файл параметров не обязателен
+func loadEnv(t *testing.T, recorder *cassette.Recorder) {
+	err := godotenv.Load(*envFile)
+
+	if replaying(recorder) {
+		return
+	}
+
+	require.NoError(t, err)
+}
+
+// restoreEnv при записи кассеты сохраняет в ней параметры теста, при воспроизведении - заменяет ими параметры,
+// загруженные из окружения. Учетные данные не сохраняются
+func restoreEnv(recorder *cassette.Recorder) {
+	if recorder == nil {
+		return
+	}
+
+	if recorder.Mode() == cassette.ModeRecord {
+		recorder.SetMeta("url", cassette.ScrubURL(cascadeURL))
+		recorder.SetMeta("authURL", cassette.ScrubURL(authURL))
+		recorder.SetMeta("archive", strDataArchive)
+
+		return
+	}
+
+	cascadeURL = recorder.Meta("url")
+	authURL = recorder.Meta("authURL")
+	username = cassette.Redacted
+	password = cassette.Redacted
+	strDataArchive = recorder.Meta("archive")
+	tracePath = ""
+}
+
+// setupTestCases возвращает сценарии теста из файла CASCADE_TEST_CASES_PATH или, при воспроизведении, из кассеты
+func setupTestCases(t *testing.T, recorder *cassette.Recorder) []gaugeTestCase {
+	var (
+		b   []byte
+		err error
+	)
+
+	if replaying(recorder) {
+		b = []byte(recorder.Meta("cases"))
+	} else {
+		require.NotEmpty(t, casesPath)
+
+		b, err = os.ReadFile(casesPath)
+
+		require.NoError(t, err)
+	}
+
+	cases, err := parseTestCases(b)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, cases)
+
+	if recorder != nil && recorder.Mode() == cassette.ModeRecord {
+		recorder.SetMeta("cases", string(b))
+	}
+
+	return cases
+}
+
+// recordClient возвращает клиента, запросы которого записываются в кассету или воспроизводятся из нее
+func recordClient(t *testing.T, recorder *cassette.Recorder, client *http.Client) *http.Client {
+	if recorder == nil {
+		return client
+	}
+
+	t.Logf("cassette %s: %s", recorder.Mode(), t.Name())
+
+	return recorder.Client(client)
+}
+
+// now возвращает текущее время или, если используются кассеты, время начала записи кассеты
+func now(recorder *cassette.Recorder) time.Time {
+	if recorder == nil {
+		return time.Now()
+	}
+
+	return recorder.RecordedAt()
+}
@@ -0,0 +1,152 @@
+package cascade_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cascade "github.com/vitpelekhaty/go-cascade-client/v2"
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/cascadetest"
+)
+
+// rotatingServer сервер, выдающий при каждой авторизации новый токен и принимающий только последний выданный
+type rotatingServer struct {
+	mu     sync.Mutex
+	issued int
+}
+
+func (s *rotatingServer) token() string {
+	return fmt.Sprintf("token-%d", s.issued)
+}
+
+// expire прекращает действие выданного токена
+func (s *rotatingServer) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.issued++
+}
+
+func (s *rotatingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Method == http.MethodPost && r.URL.Path == "/" {
+		s.issued++
+
+		w.Header().Set("Content-Type", "application/json")
+
+		_, _ = fmt.Fprintf(w, `{"access_token": %q, "token_type": "bearer"}`, s.token())
+
+		return
+	}
+
+	if r.Header.Get("Authorization") != "bearer "+s.token() {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	_, _ = w.Write([]byte("[]"))
+}
+
+func TestConnection_Relogin(t *testing.T) {
+	handler := &rotatingServer{}
+
+	server := httptest.NewServer(handler)
+
+	defer server.Close()
+
+	conn, err := cascade.NewConnection(cascade.WithHTTPClient(server.Client()))
+
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Open(context.TODO(), server.URL, "user", "password"))
+
+	endAt := time.Date(2021, 4, 12, 0, 0, 0, 0, time.UTC)
+	beginAt := endAt.Add(-24 * time.Hour)
+
+	methods := map[string]func() ([]byte, error){
+		"Gauges": func() ([]byte, error) {
+			return conn.Gauges(context.TODO())
+		},
+		"CurrentReadings": func() ([]byte, error) {
+			return conn.CurrentReadings(context.TODO(), 12032, archive.HourArchive, beginAt, endAt)
+		},
+		"AlteredReadings": func() ([]byte, error) {
+			return conn.AlteredReadings(context.TODO(), 12032, archive.HourArchive, beginAt, endAt)
+		},
+	}
+
+	for name, method := range methods {
+		t.Run(name, func(t *testing.T) {
+			handler.expire()
+
+			data, err := method()
+
+			require.NoError(t, err)
+
+			assert.Equal(t, "[]", string(data))
+		})
+	}
+}
+
+func TestConnection_ConcurrentRelogin(t *testing.T) {
+	server := cascadetest.NewServer(cascadetest.WithReadings([]byte(`[]`)))
+
+	defer server.Close()
+
+	conn, err := cascade.NewConnection(cascade.WithHTTPClient(server.Client()))
+
+	require.NoError(t, err)
+
+	err = conn.Open(context.TODO(), server.URL, cascadetest.DefaultUsername, cascadetest.DefaultPassword,
+		cascade.WithAuthURL(server.AuthURL()))
+
+	require.NoError(t, err)
+
+	server.ExpireTokens()
+
+	endAt := time.Date(2021, 4, 12, 0, 0, 0, 0, time.UTC)
+	beginAt := endAt.Add(-24 * time.Hour)
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 8)
+
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := conn.CurrentReadings(context.TODO(), 12032, archive.HourArchive, beginAt, endAt)
+
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	var logins int
+
+	for _, request := range server.Requests() {
+		if request.Path == cascadetest.AuthPath {
+			logins++
+		}
+	}
+
+	assert.Equal(t, 2, logins)
+}
@@ -0,0 +1,134 @@
+package csvexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+func openResponse(t *testing.T, name string) *os.File {
+	path, err := filepath.Abs(filepath.Join("../testdata/responses", name))
+
+	require.NoError(t, err)
+
+	f, err := os.Open(path)
+
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = f.Close()
+	})
+
+	return f
+}
+
+func TestGaugesWriter(t *testing.T) {
+	items, err := parsers.ParseGaugesListFrom(context.TODO(), openResponse(t, "counterHouse.json"))
+
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	w := NewGaugesWriter(&buf, WithComma(';'))
+
+	count, err := w.WriteItems(context.TODO(), items)
+
+	require.NoError(t, err)
+	assert.Equal(t, 38, count)
+
+	require.NoError(t, w.Flush())
+
+	records, err := readCSV(buf.String(), ';')
+
+	require.NoError(t, err)
+	require.Len(t, records, 1+127+50)
+
+	assert.Equal(t, gaugesHeader, records[0])
+	assert.Equal(t, []string{
+		"8830", "Шумакова, 32", "ТМК-Н30 11412", "ТМК-Н30", "11412", "1", "9246", "1", "Heat", "inFlow",
+	}, records[1])
+	assert.Equal(t, []string{
+		"8830", "Шумакова, 32", "ТМК-Н30 11412", "ТМК-Н30", "11412", "1", "16201", "0", "None", "",
+	}, records[5])
+}
+
+func TestReadingsWriter(t *testing.T) {
+	items, err := parsers.ParseReadingsFrom(context.TODO(), openResponse(t, "readings200.json"))
+
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	w, err := NewReadingsWriter(&buf,
+		WithColumns(ColumnID, ColumnDT, ColumnM, ColumnQ, ColumnIsBadRow),
+		WithComma(';'),
+		WithDecimalSeparator(","),
+		WithTimeLayout("02.01.2006 15:04"),
+		WithNull("NULL"),
+	)
+
+	require.NoError(t, err)
+
+	count, err := w.WriteItems(context.TODO(), items)
+
+	require.NoError(t, err)
+	assert.Equal(t, 507, count)
+
+	require.NoError(t, w.Flush())
+
+	records, err := readCSV(buf.String(), ';')
+
+	require.NoError(t, err)
+	require.Len(t, records, 508)
+
+	assert.Equal(t, []string{"id", "dt", "m", "q", "isBadRow"}, records[0])
+	assert.Equal(t, []string{"14042944", "11.04.2021 01:00", "2,2514917850494385", "NULL", "false"}, records[1])
+
+	_, err = NewReadingsWriter(&buf, WithColumns("mass"))
+
+	assert.Error(t, err)
+
+	buf.Reset()
+
+	w, err = NewReadingsWriter(&buf, WithoutHeader())
+
+	require.NoError(t, err)
+
+	items, err = parsers.ParseReadings(context.TODO(), []byte(`[{"id": 1}, {"id": "x"}]`))
+
+	require.NoError(t, err)
+
+	count, err = w.WriteItems(context.TODO(), items)
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestReadingsWriter_DecimalSeparator(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := NewReadingsWriter(&buf, WithColumns(ColumnID, ColumnM), WithDecimalSeparator(""), WithoutHeader())
+
+	require.NoError(t, err)
+	require.NoError(t, w.Write(&parsers.Readings{ID: null.IntFrom(1), M: null.FloatFrom(2.25)}))
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "1,2.25\n", buf.String())
+}
+
+func readCSV(s string, comma rune) ([][]string, error) {
+	r := csv.NewReader(strings.NewReader(s))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+
+	return r.ReadAll()
+}
@@ -0,0 +1,108 @@
+package csvexport
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// gaugesHeader заголовки колонок списка приборов учета
+var gaugesHeader = []string{
+	"deviceId", "title", "name", "modelName", "serialNumber", "inputNum", "channelId", "channelNum", "resourceType",
+	"type",
+}
+
+// GaugesWriter выводит список приборов учета в формате CSV: одна строка на каждый канал прибора учета с колонками
+// прибора учета, теплового ввода и канала. Опции WithColumns, WithDecimalSeparator и WithTimeLayout не применяются
+type GaugesWriter struct {
+	writer  *csv.Writer
+	options *writerOptions
+	started bool
+}
+
+// NewGaugesWriter возвращает GaugesWriter, выводящий список приборов учета в w
+func NewGaugesWriter(w io.Writer, opts ...Option) *GaugesWriter {
+	options := newWriterOptions(opts)
+
+	writer := csv.NewWriter(w)
+	writer.Comma = options.comma
+
+	return &GaugesWriter{writer: writer, options: options}
+}
+
+// Write выводит каналы прибора учета. Прибор учета без каналов не выводится
+func (w *GaugesWriter) Write(gauge *parsers.Gauge) error {
+	if err := w.begin(); err != nil {
+		return err
+	}
+
+	for _, input := range gauge.Inputs {
+		for _, channel := range input.Channels {
+			err := w.writer.Write([]string{
+				strconv.FormatInt(gauge.ID, 10),
+				gauge.Title,
+				gauge.Name,
+				gauge.Model,
+				gauge.SN,
+				strconv.FormatInt(int64(input.Number), 10),
+				strconv.FormatInt(channel.ID, 10),
+				strconv.FormatInt(int64(channel.Number), 10),
+				channel.ResourceValue(),
+				channel.FlowValue(),
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteAll выводит список приборов учета и сбрасывает буфер вывода
+func (w *GaugesWriter) WriteAll(gauges []parsers.Gauge) error {
+	for i := range gauges {
+		if err := w.Write(&gauges[i]); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+// Flush сбрасывает буфер вывода. Если ни один прибор учета не выведен, выводится строка заголовков
+func (w *GaugesWriter) Flush() error {
+	if err := w.begin(); err != nil {
+		return err
+	}
+
+	w.writer.Flush()
+
+	return w.writer.Error()
+}
+
+func (w *GaugesWriter) begin() error {
+	if w.started {
+		return nil
+	}
+
+	w.started = true
+
+	if !w.options.header {
+		return nil
+	}
+
+	return w.writer.Write(gaugesHeader)
+}
+
+// WriteItems выводит приборы учета, полученные от ParseGaugesList или ParseGaugesListFrom, по мере их разбора (см.
+// parsers.Each) и возвращает количество выведенных приборов учета. Как и после Write, вывод завершается вызовом Flush.
+// Опция parsers.WithSkip пропускает приборы учета с ошибкой разбора
+func (w *GaugesWriter) WriteItems(ctx context.Context, items <-chan parsers.Item,
+	options ...parsers.EachOption) (int, error) {
+	return parsers.EachGauge(ctx, items, w.Write, options...)
+}
@@ -0,0 +1,72 @@
+package csvexport
+
+type writerOptions struct {
+	comma            rune
+	header           bool
+	columns          []Column
+	decimalSeparator string
+	timeLayout       string
+	null             string
+}
+
+func newWriterOptions(opts []Option) *writerOptions {
+	options := &writerOptions{
+		comma:            ',',
+		header:           true,
+		columns:          DefaultColumns,
+		decimalSeparator: ".",
+		timeLayout:       DefaultTimeLayout,
+	}
+
+	for _, option := range opts {
+		option(options)
+	}
+
+	return options
+}
+
+// Option опция вывода в формате CSV
+type Option func(options *writerOptions)
+
+// WithComma устанавливает разделитель полей. По умолчанию ','
+func WithComma(comma rune) Option {
+	return func(options *writerOptions) {
+		options.comma = comma
+	}
+}
+
+// WithoutHeader отключает вывод строки заголовков колонок
+func WithoutHeader() Option {
+	return func(options *writerOptions) {
+		options.header = false
+	}
+}
+
+// WithColumns устанавливает состав и порядок колонок показаний. По умолчанию DefaultColumns
+func WithColumns(columns ...Column) Option {
+	return func(options *writerOptions) {
+		options.columns = columns
+	}
+}
+
+// WithDecimalSeparator устанавливает десятичный разделитель вещественных чисел. По умолчанию, как и для пустого
+// separator, '.'
+func WithDecimalSeparator(separator string) Option {
+	return func(options *writerOptions) {
+		options.decimalSeparator = separator
+	}
+}
+
+// WithTimeLayout устанавливает формат вывода времени (см. time.Layout). По умолчанию DefaultTimeLayout
+func WithTimeLayout(layout string) Option {
+	return func(options *writerOptions) {
+		options.timeLayout = layout
+	}
+}
+
+// WithNull устанавливает представление значения null. По умолчанию пустая строка
+func WithNull(null string) Option {
+	return func(options *writerOptions) {
+		options.null = null
+	}
+}
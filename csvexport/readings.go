@@ -0,0 +1,259 @@
+package csvexport
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// Column колонка показаний. Наименования колонок совпадают с наименованиями полей в ответе API Каскада
+type Column string
+
+const (
+	// ColumnID идентификатор показания
+	ColumnID Column = "id"
+
+	// ColumnDeviceID идентификатор прибора учета
+	ColumnDeviceID Column = "deviceId"
+
+	// ColumnChannelID идентификатор канала
+	ColumnChannelID Column = "channelId"
+
+	// ColumnChannelNum номер канала
+	ColumnChannelNum Column = "channelNum"
+
+	// ColumnInput номер теплового ввода
+	ColumnInput Column = "inputNum"
+
+	// ColumnArchive тип архива
+	ColumnArchive Column = "archiveType"
+
+	// ColumnDT момент показания
+	ColumnDT Column = "dt"
+
+	// ColumnCreateAt момент чтения показания
+	ColumnCreateAt Column = "createAt"
+
+	// ColumnIsBadRow признак "плохой" строки
+	ColumnIsBadRow Column = "isBadRow"
+
+	// ColumnEmpty признак "пустой" строки
+	ColumnEmpty Column = "isEmpty"
+
+	// ColumnM масса теплоносителя
+	ColumnM Column = "m"
+
+	// ColumnV объем теплоносителя
+	ColumnV Column = "v"
+
+	// ColumnP давление
+	ColumnP Column = "p"
+
+	// ColumnT температура теплоносителя
+	ColumnT Column = "t"
+
+	// ColumnTCW температура холодной воды
+	ColumnTCW Column = "tcw"
+
+	// ColumnTI время штатной работы
+	ColumnTI Column = "ti"
+
+	// ColumnQ тепловая энергия по вводу
+	ColumnQ Column = "q"
+
+	// ColumnQ1 тепловая энергия по отоплению
+	ColumnQ1 Column = "q1"
+
+	// ColumnQ2 тепловая энергия по ГВС
+	ColumnQ2 Column = "q2"
+)
+
+// DefaultColumns колонки показаний по умолчанию
+var DefaultColumns = []Column{
+	ColumnID, ColumnDeviceID, ColumnInput, ColumnChannelID, ColumnChannelNum, ColumnArchive, ColumnDT, ColumnCreateAt,
+	ColumnIsBadRow, ColumnM, ColumnV, ColumnP, ColumnT, ColumnTCW, ColumnTI, ColumnQ, ColumnQ1, ColumnQ2,
+}
+
+// DefaultTimeLayout формат вывода времени по умолчанию
+const DefaultTimeLayout = "2006-01-02 15:04:05"
+
+// ReadingsWriter выводит показания в формате CSV
+type ReadingsWriter struct {
+	writer  *csv.Writer
+	options *writerOptions
+	values  []func(r *parsers.Readings) string
+	record  []string
+	started bool
+}
+
+// NewReadingsWriter возвращает ReadingsWriter, выводящий показания в w. Возвращает ошибку, если указана
+// неизвестная колонка
+func NewReadingsWriter(w io.Writer, opts ...Option) (*ReadingsWriter, error) {
+	options := newWriterOptions(opts)
+
+	writer := csv.NewWriter(w)
+	writer.Comma = options.comma
+
+	rw := &ReadingsWriter{
+		writer:  writer,
+		options: options,
+		values:  make([]func(r *parsers.Readings) string, 0, len(options.columns)),
+		record:  make([]string, len(options.columns)),
+	}
+
+	for _, column := range options.columns {
+		value, err := rw.column(column)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rw.values = append(rw.values, value)
+	}
+
+	return rw, nil
+}
+
+// Write выводит показание
+func (w *ReadingsWriter) Write(r *parsers.Readings) error {
+	if err := w.begin(); err != nil {
+		return err
+	}
+
+	for i, value := range w.values {
+		w.record[i] = value(r)
+	}
+
+	return w.writer.Write(w.record)
+}
+
+// WriteItems выводит показания, полученные от ParseReadings или ParseReadingsFrom, по мере их разбора (см.
+// parsers.Each) и возвращает количество выведенных показаний. Как и после Write, вывод завершается вызовом Flush.
+// Некорректные элементы списка пропускаются с опцией parsers.WithSkip
+func (w *ReadingsWriter) WriteItems(ctx context.Context, items <-chan parsers.Item,
+	options ...parsers.EachOption) (int, error) {
+	return parsers.EachReadings(ctx, items, w.Write, options...)
+}
+
+// Flush сбрасывает буфер вывода. Если ни одно показание не выведено, выводится строка заголовков
+func (w *ReadingsWriter) Flush() error {
+	if err := w.begin(); err != nil {
+		return err
+	}
+
+	w.writer.Flush()
+
+	return w.writer.Error()
+}
+
+func (w *ReadingsWriter) begin() error {
+	if w.started {
+		return nil
+	}
+
+	w.started = true
+
+	if !w.options.header {
+		return nil
+	}
+
+	header := make([]string, 0, len(w.options.columns))
+
+	for _, column := range w.options.columns {
+		header = append(header, string(column))
+	}
+
+	return w.writer.Write(header)
+}
+
+// column возвращает функцию получения значения колонки
+func (w *ReadingsWriter) column(column Column) (func(r *parsers.Readings) string, error) {
+	switch column {
+	case ColumnID:
+		return func(r *parsers.Readings) string { return w.integer(r.ID) }, nil
+	case ColumnDeviceID:
+		return func(r *parsers.Readings) string { return w.integer(r.DeviceID) }, nil
+	case ColumnChannelID:
+		return func(r *parsers.Readings) string { return w.integer(r.ChannelID) }, nil
+	case ColumnChannelNum:
+		return func(r *parsers.Readings) string { return w.integer(r.ChannelNum) }, nil
+	case ColumnInput:
+		return func(r *parsers.Readings) string { return w.integer(r.Input) }, nil
+	case ColumnArchive:
+		return func(r *parsers.Readings) string { return r.Archive.String() }, nil
+	case ColumnDT:
+		return func(r *parsers.Readings) string { return w.time(r.DT) }, nil
+	case ColumnCreateAt:
+		return func(r *parsers.Readings) string { return w.time(r.CreateAt) }, nil
+	case ColumnIsBadRow:
+		return func(r *parsers.Readings) string { return strconv.FormatBool(r.IsBadRow) }, nil
+	case ColumnEmpty:
+		return func(r *parsers.Readings) string { return w.boolean(r.Empty) }, nil
+	case ColumnM:
+		return func(r *parsers.Readings) string { return w.float(r.M) }, nil
+	case ColumnV:
+		return func(r *parsers.Readings) string { return w.float(r.V) }, nil
+	case ColumnP:
+		return func(r *parsers.Readings) string { return w.float(r.P) }, nil
+	case ColumnT:
+		return func(r *parsers.Readings) string { return w.float(r.T) }, nil
+	case ColumnTCW:
+		return func(r *parsers.Readings) string { return w.float(r.TCW) }, nil
+	case ColumnTI:
+		return func(r *parsers.Readings) string { return w.float(r.TI) }, nil
+	case ColumnQ:
+		return func(r *parsers.Readings) string { return w.float(r.Q) }, nil
+	case ColumnQ1:
+		return func(r *parsers.Readings) string { return w.float(r.Q1) }, nil
+	case ColumnQ2:
+		return func(r *parsers.Readings) string { return w.float(r.Q2) }, nil
+	default:
+		return nil, fmt.Errorf("unknown column %s", column)
+	}
+}
+
+func (w *ReadingsWriter) integer(v null.Int) string {
+	if !v.Valid {
+		return w.options.null
+	}
+
+	return strconv.FormatInt(v.Int64, 10)
+}
+
+func (w *ReadingsWriter) float(v null.Float) string {
+	if !v.Valid {
+		return w.options.null
+	}
+
+	s := strconv.FormatFloat(v.Float64, 'f', -1, 64)
+
+	if w.options.decimalSeparator != "" && w.options.decimalSeparator != "." {
+		s = strings.Replace(s, ".", w.options.decimalSeparator, 1)
+	}
+
+	return s
+}
+
+func (w *ReadingsWriter) boolean(v null.Bool) string {
+	if !v.Valid {
+		return w.options.null
+	}
+
+	return strconv.FormatBool(v.Bool)
+}
+
+func (w *ReadingsWriter) time(v parsers.ReadingTime) string {
+	if v.IsZero() {
+		return w.options.null
+	}
+
+	return time.Time(v).Format(w.options.timeLayout)
+}
@@ -0,0 +1,56 @@
+package cascade
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func FuzzRequestTime(f *testing.F) {
+	f.Add([]byte(`"11.04.2021 01:00:00"`))
+	f.Add([]byte(`"18.04.2021 00:59:59"`))
+	f.Add([]byte(`"31.02.2021 00:00:00"`))
+	f.Add([]byte(`11.04.2021 01:00:00`))
+	f.Add([]byte(`null`))
+	f.Add([]byte(`""`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var rt RequestTime
+
+		err := rt.UnmarshalJSON(data)
+
+		var s string
+
+		if json.Unmarshal(data, &s) != nil {
+			if err == nil {
+				t.Fatalf("invalid JSON string %q accepted", data)
+			}
+
+			return
+		}
+
+		if _, wantErr := time.Parse(requestTimeLayout, s); (err == nil) != (wantErr == nil) {
+			t.Fatalf("%q: got error %v, want %v", data, err, wantErr)
+		}
+
+		if err != nil {
+			return
+		}
+
+		b, err := rt.MarshalJSON()
+
+		if err != nil {
+			t.Fatalf("marshal %v: %v", rt.String(), err)
+		}
+
+		var again RequestTime
+
+		if err = json.Unmarshal(b, &again); err != nil {
+			t.Fatalf("unmarshal %s: %v", b, err)
+		}
+
+		if !time.Time(again).Equal(time.Time(rt)) {
+			t.Fatalf("round trip %s: got %s, want %s", data, again.String(), rt.String())
+		}
+	})
+}
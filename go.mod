@@ -1,6 +1,6 @@
 module github.com/vitpelekhaty/go-cascade-client/v2
 
-go 1.17
+go 1.20
 
 require (
 	github.com/guregu/null v4.0.0+incompatible
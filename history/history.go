@@ -0,0 +1,256 @@
+package history
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// Change изменение значения поля показания
+type Change struct {
+	// Field наименование поля parsers.Readings
+	Field string
+
+	// Old прежнее значение поля
+	Old interface{}
+
+	// New новое значение поля
+	New interface{}
+}
+
+// Version версия показания
+type Version struct {
+	// Readings показание
+	Readings parsers.Readings
+
+	// CreateAt момент чтения показания в Каскаде
+	CreateAt time.Time
+
+	// AppliedAt момент добавления версии в хранилище
+	AppliedAt time.Time
+
+	// Changes изменения относительно предыдущей версии. Для первой версии показания не заполняется
+	Changes []Change
+}
+
+// Stats результат применения показаний к хранилищу
+type Stats struct {
+	// Added количество новых показаний
+	Added int
+
+	// Updated количество показаний, получивших новую версию
+	Updated int
+
+	// Unchanged количество показаний, совпавших с уже известной версией
+	Unchanged int
+
+	// Rejected количество показаний без идентификатора
+	Rejected int
+}
+
+// Store хранилище показаний с историей изменений. Показания идентифицируются по полю ID; версии показания
+// упорядочиваются по моменту чтения CreateAt. Хранилище безопасно для одновременного использования из нескольких
+// горутин
+type Store struct {
+	mu       sync.RWMutex
+	now      func() time.Time
+	versions map[int64][]*Version
+}
+
+// NewStore возвращает пустое хранилище показаний
+func NewStore(opts ...Option) *Store {
+	options := &storeOptions{now: time.Now}
+
+	for _, option := range opts {
+		option(options)
+	}
+
+	return &Store{
+		now:      options.now,
+		versions: make(map[int64][]*Version),
+	}
+}
+
+// Apply добавляет показания в хранилище. Используется как для исходного набора показаний (CurrentReadings), так и
+// для измененных показаний (AlteredReadings): показание с уже известным ID и отличающимися значениями становится
+// новой версией. Показание с более ранним CreateAt, чем у известных версий, встраивается в историю по порядку
+func (s *Store) Apply(readings []parsers.Readings) Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stats Stats
+
+	appliedAt := s.now()
+
+	for i := range readings {
+		r := readings[i]
+
+		if !r.ID.Valid {
+			stats.Rejected++
+			continue
+		}
+
+		versions := s.versions[r.ID.Int64]
+		createAt := time.Time(r.CreateAt)
+
+		if len(versions) == 0 {
+			s.versions[r.ID.Int64] = []*Version{{Readings: r, CreateAt: createAt, AppliedAt: appliedAt}}
+			stats.Added++
+			continue
+		}
+
+		// позиция новой версии в истории
+		pos := sort.Search(len(versions), func(i int) bool {
+			return versions[i].CreateAt.After(createAt)
+		})
+
+		// показание, совпадающее с соседней версией, в том числе повторно полученное показание с более ранним
+		// CreateAt, чем у всех известных версий, новой версией не является
+		if pos > 0 && len(diff(&versions[pos-1].Readings, &r)) == 0 ||
+			pos < len(versions) && len(diff(&versions[pos].Readings, &r)) == 0 {
+			stats.Unchanged++
+			continue
+		}
+
+		version := &Version{Readings: r, CreateAt: createAt, AppliedAt: appliedAt}
+
+		if pos > 0 {
+			version.Changes = diff(&versions[pos-1].Readings, &r)
+		}
+
+		versions = append(versions, nil)
+		copy(versions[pos+1:], versions[pos:])
+		versions[pos] = version
+
+		if pos+1 < len(versions) {
+			versions[pos+1].Changes = diff(&r, &versions[pos+1].Readings)
+		}
+
+		s.versions[r.ID.Int64] = versions
+		stats.Updated++
+	}
+
+	return stats
+}
+
+// Current возвращает последние версии всех показаний
+func (s *Store) Current() []parsers.Readings {
+	return s.latest(func(v *Version) bool {
+		return true
+	})
+}
+
+// AsOf возвращает показания в том виде, в каком они были в Каскаде на момент t: для каждого показания выбирается
+// последняя версия с моментом чтения CreateAt не позднее t
+func (s *Store) AsOf(t time.Time) []parsers.Readings {
+	return s.latest(func(v *Version) bool {
+		return !v.CreateAt.After(t)
+	})
+}
+
+// KnownAt возвращает показания, известные на момент t: для каждого показания выбирается последняя из версий,
+// добавленных в хранилище не позднее t
+func (s *Store) KnownAt(t time.Time) []parsers.Readings {
+	return s.latest(func(v *Version) bool {
+		return !v.AppliedAt.After(t)
+	})
+}
+
+// History возвращает копию истории версий показания в порядке CreateAt
+func (s *Store) History(id int64) []Version {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions := s.versions[id]
+	history := make([]Version, 0, len(versions))
+
+	for _, v := range versions {
+		version := *v
+		version.Changes = append([]Change(nil), v.Changes...)
+
+		history = append(history, version)
+	}
+
+	return history
+}
+
+// latest возвращает для каждого показания последнюю по CreateAt версию, удовлетворяющую условию accept. Показания
+// упорядочены по прибору учета, каналу и моменту показания
+func (s *Store) latest(accept func(v *Version) bool) []parsers.Readings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]parsers.Readings, 0, len(s.versions))
+
+	for _, versions := range s.versions {
+		var last *Version
+
+		for _, v := range versions {
+			if accept(v) {
+				last = v
+			}
+		}
+
+		if last != nil {
+			result = append(result, last.Readings)
+		}
+	}
+
+	sortReadings(result)
+
+	return result
+}
+
+// Merge применяет измененные показания altered к исходным показаниям base и возвращает актуальные показания
+func Merge(base, altered []parsers.Readings) []parsers.Readings {
+	store := NewStore()
+
+	store.Apply(base)
+	store.Apply(altered)
+
+	return store.Current()
+}
+
+// diff возвращает изменения полей показания b относительно a без учета полей ID и CreateAt
+func diff(a, b *parsers.Readings) []Change {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	t := va.Type()
+
+	var changes []Change
+
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+
+		if name == "ID" || name == "CreateAt" {
+			continue
+		}
+
+		fa, fb := va.Field(i).Interface(), vb.Field(i).Interface()
+
+		if !reflect.DeepEqual(fa, fb) {
+			changes = append(changes, Change{Field: name, Old: fa, New: fb})
+		}
+	}
+
+	return changes
+}
+
+func sortReadings(readings []parsers.Readings) {
+	sort.Slice(readings, func(i, j int) bool {
+		a, b := &readings[i], &readings[j]
+
+		switch {
+		case a.DeviceID.Int64 != b.DeviceID.Int64:
+			return a.DeviceID.Int64 < b.DeviceID.Int64
+		case a.ChannelID.Int64 != b.ChannelID.Int64:
+			return a.ChannelID.Int64 < b.ChannelID.Int64
+		case !time.Time(a.DT).Equal(time.Time(b.DT)):
+			return time.Time(a.DT).Before(time.Time(b.DT))
+		default:
+			return a.ID.Int64 < b.ID.Int64
+		}
+	})
+}
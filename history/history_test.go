@@ -0,0 +1,120 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+func reading(id int64, createAt time.Time, m float64) parsers.Readings {
+	return parsers.Readings{
+		ID:        null.IntFrom(id),
+		DeviceID:  null.IntFrom(1),
+		ChannelID: null.IntFrom(10),
+		DT:        parsers.ReadingTime(time.Date(2021, 4, 11, int(id), 0, 0, 0, time.UTC)),
+		CreateAt:  parsers.ReadingTime(createAt),
+		M:         null.FloatFrom(m),
+	}
+}
+
+func TestStore(t *testing.T) {
+	day := time.Date(2021, 4, 12, 0, 0, 0, 0, time.UTC)
+	clock := day.Add(48 * time.Hour)
+
+	store := NewStore(WithClock(func() time.Time {
+		return clock
+	}))
+
+	stats := store.Apply([]parsers.Readings{
+		reading(1, day, 1.0),
+		reading(2, day, 2.0),
+		{M: null.FloatFrom(3)},
+	})
+
+	assert.Equal(t, Stats{Added: 2, Rejected: 1}, stats)
+
+	clock = clock.Add(24 * time.Hour)
+
+	altered := reading(2, day.Add(24*time.Hour), 2.5)
+	altered.IsBadRow = true
+
+	stats = store.Apply([]parsers.Readings{
+		reading(1, day.Add(time.Hour), 1.0),
+		altered,
+	})
+
+	assert.Equal(t, Stats{Updated: 1, Unchanged: 1}, stats)
+
+	current := store.Current()
+
+	require.Len(t, current, 2)
+	assert.Equal(t, 2.5, current[1].M.Float64)
+
+	history := store.History(2)
+
+	require.Len(t, history, 2)
+	assert.Nil(t, history[0].Changes)
+	assert.Equal(t, []Change{
+		{Field: "IsBadRow", Old: false, New: true},
+		{Field: "M", Old: null.FloatFrom(2), New: null.FloatFrom(2.5)},
+	}, history[1].Changes)
+	assert.True(t, history[1].AppliedAt.Equal(clock))
+
+	asOf := store.AsOf(day.Add(time.Hour))
+
+	require.Len(t, asOf, 2)
+	assert.Equal(t, 2.0, asOf[1].M.Float64)
+
+	known := store.KnownAt(clock.Add(-time.Hour))
+
+	require.Len(t, known, 2)
+	assert.Equal(t, 2.0, known[1].M.Float64)
+
+	assert.Empty(t, store.AsOf(day.Add(-time.Hour)))
+
+	// версия, полученная позже, но прочитанная в Каскаде раньше известных версий
+	stats = store.Apply([]parsers.Readings{reading(2, day.Add(12*time.Hour), 2.2)})
+
+	assert.Equal(t, 1, stats.Updated)
+
+	history = store.History(2)
+
+	require.Len(t, history, 3)
+	assert.Equal(t, 2.2, history[1].Readings.M.Float64)
+	assert.Equal(t, null.FloatFrom(2.2), history[2].Changes[1].Old)
+	assert.Equal(t, 2.5, store.Current()[1].M.Float64)
+
+	// повторно полученная версия, прочитанная в Каскаде раньше всех известных версий
+	stats = store.Apply([]parsers.Readings{reading(2, day.Add(-time.Hour), 2.0)})
+
+	assert.Equal(t, Stats{Unchanged: 1}, stats)
+
+	history = store.History(2)
+
+	require.Len(t, history, 3)
+	assert.Len(t, history[1].Changes, 1)
+
+	history[2].Changes[0].New = nil
+
+	assert.Equal(t, null.FloatFrom(2.5), store.History(2)[2].Changes[1].New)
+	assert.NotNil(t, store.History(2)[2].Changes[0].New)
+}
+
+func TestMerge(t *testing.T) {
+	day := time.Date(2021, 4, 12, 0, 0, 0, 0, time.UTC)
+
+	merged := Merge(
+		[]parsers.Readings{reading(1, day, 1), reading(2, day, 2)},
+		[]parsers.Readings{reading(2, day.Add(time.Hour), 3), reading(3, day.Add(time.Hour), 4)},
+	)
+
+	require.Len(t, merged, 3)
+	assert.Equal(t, 1.0, merged[0].M.Float64)
+	assert.Equal(t, 3.0, merged[1].M.Float64)
+	assert.Equal(t, 4.0, merged[2].M.Float64)
+}
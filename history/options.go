@@ -0,0 +1,19 @@
+package history
+
+import (
+	"time"
+)
+
+type storeOptions struct {
+	now func() time.Time
+}
+
+// Option опция хранилища показаний
+type Option func(options *storeOptions)
+
+// WithClock устанавливает источник текущего времени, используемый для отметки момента добавления версий
+func WithClock(now func() time.Time) Option {
+	return func(options *storeOptions) {
+		options.now = now
+	}
+}
@@ -0,0 +1,43 @@
+package jsontext
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// Unquote возвращает значение строки JSON b. Значение null возвращается как пустая строка, значения других типов и
+// некорректный JSON считаются ошибкой
+func Unquote(b []byte) (string, error) {
+	if s, ok := unquoteSimple(b); ok {
+		return s, nil
+	}
+
+	var s string
+
+	if err := json.Unmarshal(b, &s); err != nil {
+		return "", err
+	}
+
+	return s, nil
+}
+
+// unquoteSimple возвращает значение строки JSON без управляющих последовательностей, не разбирая ее полностью
+func unquoteSimple(b []byte) (string, bool) {
+	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
+		return "", false
+	}
+
+	b = b[1 : len(b)-1]
+
+	for _, c := range b {
+		if c < 0x20 || c == '"' || c == '\\' {
+			return "", false
+		}
+	}
+
+	if !utf8.Valid(b) {
+		return "", false
+	}
+
+	return string(b), true
+}
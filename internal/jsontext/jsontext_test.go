@@ -0,0 +1,35 @@
+package jsontext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnquote(t *testing.T) {
+	var cases = []struct {
+		json string
+		want string
+	}{
+		{json: `"Hour"`, want: "Hour"},
+		{json: `"\u0048our"`, want: "Hour"},
+		{json: `"Шумакова, 32"`, want: "Шумакова, 32"},
+		{json: `"a\"b"`, want: `a"b`},
+		{json: `null`, want: ""},
+		{json: `""`, want: ""},
+	}
+
+	for _, test := range cases {
+		s, err := Unquote([]byte(test.json))
+
+		require.NoError(t, err, test.json)
+		assert.Equal(t, test.want, s, test.json)
+	}
+
+	for _, invalid := range []string{`Hour`, `"Hour`, `""Hour""`, `1`, `"a` + "\n" + `b"`, ``} {
+		_, err := Unquote([]byte(invalid))
+
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,129 @@
+package series
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+// Label метка временного ряда
+type Label struct {
+	// Name наименование метки
+	Name string
+
+	// Value значение метки
+	Value string
+}
+
+// Field измеряемая величина показания
+type Field struct {
+	// Name наименование величины (наименование поля в ответе API Каскада)
+	Name string
+
+	// Value значение величины
+	Value null.Float
+}
+
+// Labels возвращает метки показания в порядке возрастания наименования: прибор учета, канал (номер канала, если
+// идентификатор канала не указан), тепловой ввод и, если указан список приборов учета topo, тип подключения и тип
+// ресурса канала. Метки без значения не возвращаются
+func Labels(r *parsers.Readings, topo *topology.Topology) []Label {
+	labels := make([]Label, 0, 5)
+
+	if r.ChannelID.Valid {
+		labels = append(labels, Label{Name: "channel", Value: strconv.FormatInt(r.ChannelID.Int64, 10)})
+	} else if r.ChannelNum.Valid {
+		labels = append(labels, Label{Name: "channel_num", Value: strconv.FormatInt(r.ChannelNum.Int64, 10)})
+	}
+
+	if r.DeviceID.Valid {
+		labels = append(labels, Label{Name: "device", Value: strconv.FormatInt(r.DeviceID.Int64, 10)})
+	}
+
+	if topo != nil {
+		if channel, ok := topo.ChannelOf(r); ok && channel.Flow() != parsers.FlowUnknown {
+			labels = append(labels, Label{Name: "flow", Value: channel.Flow().String()})
+		}
+	}
+
+	if r.Input.Valid {
+		labels = append(labels, Label{Name: "input", Value: strconv.FormatInt(r.Input.Int64, 10)})
+	}
+
+	if topo != nil {
+		if channel, ok := topo.ChannelOf(r); ok {
+			labels = append(labels, Label{Name: "resource", Value: channel.Resource().String()})
+		}
+	}
+
+	return labels
+}
+
+// Fields возвращает измеряемые величины показания в порядке полей parsers.Readings. Значения null не
+// возвращаются
+func Fields(r *parsers.Readings) []Field {
+	all := [...]Field{
+		{Name: "m", Value: r.M},
+		{Name: "p", Value: r.P},
+		{Name: "q", Value: r.Q},
+		{Name: "q1", Value: r.Q1},
+		{Name: "q2", Value: r.Q2},
+		{Name: "t", Value: r.T},
+		{Name: "tcw", Value: r.TCW},
+		{Name: "ti", Value: r.TI},
+		{Name: "v", Value: r.V},
+	}
+
+	fields := make([]Field, 0, len(all))
+
+	for _, field := range all {
+		if field.Value.Valid {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// Time возвращает момент показания в часовом поясе loc. Время в показаниях Каскада не содержит часового пояса и
+// соответствует показаниям часов сервера
+func Time(r *parsers.Readings, loc *time.Location) time.Time {
+	t := time.Time(r.DT)
+
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// Merge возвращает метки labels, дополненные метками extra, в порядке возрастания наименования. Метки labels
+// имеют приоритет над одноименными метками extra
+func Merge(labels []Label, extra map[string]string) []Label {
+	merged := make([]Label, 0, len(labels)+len(extra))
+	merged = append(merged, labels...)
+
+	for name, value := range extra {
+		if !has(labels, name) {
+			merged = append(merged, Label{Name: name, Value: value})
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Name < merged[j].Name
+	})
+
+	return merged
+}
+
+// has проверяет наличие метки с наименованием name
+func has(labels []Label, name string) bool {
+	for _, label := range labels {
+		if label.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
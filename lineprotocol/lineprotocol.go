@@ -0,0 +1,114 @@
+package lineprotocol
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/internal/series"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// DefaultMeasurement наименование измерения по умолчанию
+const DefaultMeasurement = "cascade_readings"
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+)
+
+// Encoder кодирует показания в строки протокола InfluxDB (line protocol).
+//
+// Каждое показание кодируется одной строкой: теги device, input, channel (channel_num для показаний без
+// идентификатора канала) и, если указан список приборов учета, resource и flow; поля - измеряемые величины
+// показания (m, v, p, t, tcw, ti, q, q1, q2) и признак "плохой" строки is_bad_row. Значения null пропускаются.
+// "Пустые" строки показаний и показания без момента времени не кодируются
+type Encoder struct {
+	w       *bufio.Writer
+	options *encoderOptions
+	line    []byte
+}
+
+// NewEncoder возвращает Encoder, выводящий строки протокола в w. Вывод буферизуется до вызова Flush
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	return &Encoder{
+		w:       bufio.NewWriter(w),
+		options: newEncoderOptions(opts),
+		line:    make([]byte, 0, 256),
+	}
+}
+
+// Encode кодирует показание
+func (e *Encoder) Encode(r *parsers.Readings) error {
+	if (r.Empty.Valid && r.Empty.Bool) || r.DT.IsZero() {
+		return nil
+	}
+
+	line := append(e.line[:0], measurementEscaper.Replace(e.options.measurement)...)
+
+	for _, label := range e.tags(r) {
+		line = append(line, ',')
+		line = append(line, tagEscaper.Replace(label.Name)...)
+		line = append(line, '=')
+		line = append(line, tagEscaper.Replace(label.Value)...)
+	}
+
+	line = append(line, ' ')
+
+	for _, field := range series.Fields(r) {
+		line = append(line, field.Name...)
+		line = append(line, '=')
+		line = strconv.AppendFloat(line, field.Value.Float64, 'g', -1, 64)
+		line = append(line, ',')
+	}
+
+	line = append(line, "is_bad_row="...)
+	line = strconv.AppendBool(line, r.IsBadRow)
+
+	line = append(line, ' ')
+	line = strconv.AppendInt(line, series.Time(r, e.options.location).UnixNano()/int64(e.options.precision), 10)
+	line = append(line, '\n')
+
+	e.line = line
+
+	_, err := e.w.Write(line)
+
+	return err
+}
+
+// EncodeItems кодирует показания, полученные от ParseReadings или ParseReadingsFrom, по мере их разбора (см.
+// parsers.Each) и возвращает количество закодированных показаний. Как и после Encode, вывод завершается вызовом
+// Flush. Некорректные записи архива пропускаются с опцией parsers.WithSkip
+func (e *Encoder) EncodeItems(ctx context.Context, items <-chan parsers.Item,
+	options ...parsers.EachOption) (int, error) {
+	return parsers.EachReadings(ctx, items, e.Encode, options...)
+}
+
+// Flush выводит буферизованные строки
+func (e *Encoder) Flush() error {
+	return e.w.Flush()
+}
+
+// tags возвращает теги показания, включая дополнительные теги, в порядке возрастания наименования
+func (e *Encoder) tags(r *parsers.Readings) []series.Label {
+	labels := series.Labels(r, e.options.topology)
+
+	if len(e.options.tags) == 0 {
+		return labels
+	}
+
+	return series.Merge(labels, e.options.tags)
+}
+
+// unixPrecision проверяет точность меток времени
+func unixPrecision(precision time.Duration) bool {
+	switch precision {
+	case time.Nanosecond, time.Microsecond, time.Millisecond, time.Second:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,112 @@
+package lineprotocol
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+func openResponse(t *testing.T, name string) *os.File {
+	path, err := filepath.Abs(filepath.Join("../testdata/responses", name))
+
+	require.NoError(t, err)
+
+	f, err := os.Open(path)
+
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = f.Close()
+	})
+
+	return f
+}
+
+func testTopology() *topology.Topology {
+	return topology.New([]parsers.Gauge{
+		{
+			ID: 12032,
+			Inputs: []parsers.Input{
+				{
+					Number: 1,
+					Channels: []parsers.Channel{
+						{ID: 19265, Number: 1, Resource: parsers.ResourceHeat, Flow: parsers.FlowDirect},
+						{ID: 19288, Number: 0, Resource: parsers.ResourceNone},
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestEncoder_EncodeItems(t *testing.T) {
+	items, err := parsers.ParseReadingsFrom(context.TODO(), openResponse(t, "readings200.json"))
+
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	e := NewEncoder(&buf, WithPrecision(time.Second), WithTopology(testTopology()))
+
+	count, err := e.EncodeItems(context.TODO(), items)
+
+	require.NoError(t, err)
+	assert.Equal(t, 507, count)
+
+	require.NoError(t, e.Flush())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	require.Len(t, lines, 507)
+
+	assert.Equal(t, "cascade_readings,channel=19265,device=12032,flow=inFlow,input=1,resource=Heat "+
+		"m=2.2514917850494385,p=7,t=67.08999633789062,tcw=0,ti=1,v=2.2979884147644043,is_bad_row=false 1618102800",
+		lines[0])
+
+	for _, line := range lines {
+		assert.NotContains(t, line, "null")
+	}
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+
+	r := &parsers.Readings{
+		DeviceID: null.IntFrom(1),
+		DT:       parsers.ReadingTime(time.Date(2021, 4, 11, 5, 0, 0, 0, time.UTC)),
+		Q:        null.FloatFrom(0.5),
+		IsBadRow: true,
+	}
+
+	empty := &parsers.Readings{
+		DeviceID: null.IntFrom(1),
+		DT:       parsers.ReadingTime(time.Date(2021, 4, 11, 6, 0, 0, 0, time.UTC)),
+		Empty:    null.BoolFrom(true),
+	}
+
+	var buf bytes.Buffer
+
+	e := NewEncoder(&buf,
+		WithMeasurement("heat meter"),
+		WithPrecision(time.Millisecond),
+		WithLocation(loc),
+		WithTags(map[string]string{"site": "Main, 1", "device": "ignored"}),
+	)
+
+	require.NoError(t, e.Encode(r))
+	require.NoError(t, e.Encode(empty))
+	require.NoError(t, e.Flush())
+
+	assert.Equal(t, `heat\ meter,device=1,site=Main\,\ 1 q=0.5,is_bad_row=true 1618099200000`+"\n", buf.String())
+}
@@ -0,0 +1,77 @@
+package lineprotocol
+
+import (
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+type encoderOptions struct {
+	measurement string
+	precision   time.Duration
+	location    *time.Location
+	topology    *topology.Topology
+	tags        map[string]string
+}
+
+func newEncoderOptions(opts []Option) *encoderOptions {
+	options := &encoderOptions{
+		measurement: DefaultMeasurement,
+		precision:   time.Nanosecond,
+		location:    time.UTC,
+	}
+
+	for _, option := range opts {
+		option(options)
+	}
+
+	if !unixPrecision(options.precision) {
+		options.precision = time.Nanosecond
+	}
+
+	if options.location == nil {
+		options.location = time.UTC
+	}
+
+	return options
+}
+
+// Option опция кодирования показаний
+type Option func(options *encoderOptions)
+
+// WithMeasurement устанавливает наименование измерения. По умолчанию DefaultMeasurement
+func WithMeasurement(measurement string) Option {
+	return func(options *encoderOptions) {
+		options.measurement = measurement
+	}
+}
+
+// WithPrecision устанавливает точность меток времени: time.Nanosecond (по умолчанию), time.Microsecond,
+// time.Millisecond или time.Second. Точность должна совпадать с параметром precision запроса записи в InfluxDB
+func WithPrecision(precision time.Duration) Option {
+	return func(options *encoderOptions) {
+		options.precision = precision
+	}
+}
+
+// WithLocation устанавливает часовой пояс сервера Каскад, в котором указано время показаний. По умолчанию UTC
+func WithLocation(loc *time.Location) Option {
+	return func(options *encoderOptions) {
+		options.location = loc
+	}
+}
+
+// WithTopology устанавливает список приборов учета для определения тегов resource и flow
+func WithTopology(topo *topology.Topology) Option {
+	return func(options *encoderOptions) {
+		options.topology = topo
+	}
+}
+
+// WithTags устанавливает дополнительные теги всех строк. Теги показания имеют приоритет над одноименными
+// дополнительными тегами
+func WithTags(tags map[string]string) Option {
+	return func(options *encoderOptions) {
+		options.tags = tags
+	}
+}
@@ -0,0 +1,190 @@
+package openmetrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// ContentType тип содержимого текстового формата OpenMetrics
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// MetricType тип метрики
+type MetricType string
+
+const (
+	// Gauge метрика, значение которой может как увеличиваться, так и уменьшаться
+	Gauge MetricType = "gauge"
+	// Counter монотонно возрастающий счетчик
+	Counter MetricType = "counter"
+)
+
+// family семейство метрик, добавленное вызовом AddFamily
+type family struct {
+	name    string
+	typ     MetricType
+	help    string
+	samples []Sample
+}
+
+// Encoder кодирует показания в текстовый формат OpenMetrics.
+//
+// Формат требует вывода отсчетов одного семейства метрик подряд, поэтому отсчеты накапливаются до вызова Close,
+// который выводит их по семействам в порядке Families, затем семейства, добавленные AddFamily, и завершает вывод
+// строкой "# EOF"
+type Encoder struct {
+	w        io.Writer
+	options  *encoderOptions
+	samples  map[string][]Sample
+	families []family
+	closed   bool
+}
+
+// NewEncoder возвращает Encoder, выводящий отсчеты в w. Вывод выполняется вызовом Close
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	return &Encoder{
+		w:       w,
+		options: newEncoderOptions(opts),
+		samples: make(map[string][]Sample),
+	}
+}
+
+// Encode кодирует показание
+func (e *Encoder) Encode(r *parsers.Readings) error {
+	if e.closed {
+		return fmt.Errorf("encoder is closed")
+	}
+
+	for _, sample := range samples(r, e.options) {
+		e.samples[sample.Name] = append(e.samples[sample.Name], sample)
+	}
+
+	return nil
+}
+
+// AddFamily добавляет к выводу семейство метрик name типа typ с описанием help и отсчетами samples. Наименования
+// отсчетов устанавливаются по наименованию семейства (для счетчиков - с суффиксом _total), метки отсчетов
+// выводятся в указанном порядке
+func (e *Encoder) AddFamily(name string, typ MetricType, help string, samples ...Sample) error {
+	if e.closed {
+		return fmt.Errorf("encoder is closed")
+	}
+
+	sampleName := name
+
+	if typ == Counter {
+		sampleName += "_total"
+	}
+
+	f := family{name: name, typ: typ, help: help, samples: make([]Sample, len(samples))}
+
+	for i, sample := range samples {
+		sample.Name = sampleName
+		f.samples[i] = sample
+	}
+
+	e.families = append(e.families, f)
+
+	return nil
+}
+
+// EncodeItems кодирует показания, полученные от ParseReadings или ParseReadingsFrom, по мере их разбора (см.
+// parsers.Each) и возвращает количество закодированных показаний. Как и после Encode, вывод завершается вызовом
+// Close. Некорректные записи архива пропускаются с опцией parsers.WithSkip
+func (e *Encoder) EncodeItems(ctx context.Context, items <-chan parsers.Item,
+	options ...parsers.EachOption) (int, error) {
+	return parsers.EachReadings(ctx, items, e.Encode, options...)
+}
+
+// Close выводит накопленные отсчеты и завершает вывод
+func (e *Encoder) Close() error {
+	if e.closed {
+		return nil
+	}
+
+	e.closed = true
+
+	w := bufio.NewWriter(e.w)
+
+	for _, f := range Families {
+		name := metricName(e.options.prefix, f.Name)
+		writeFamily(w, name, Gauge, f.Unit, f.Help, e.samples[name])
+	}
+
+	for _, f := range e.families {
+		writeFamily(w, f.name, f.typ, "", f.help, f.samples)
+	}
+
+	w.WriteString("# EOF\n")
+
+	return w.Flush()
+}
+
+// writeFamily выводит описание и отсчеты семейства метрик. Семейства без отсчетов не выводятся
+func writeFamily(w *bufio.Writer, name string, typ MetricType, unit, help string, samples []Sample) {
+	if len(samples) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+
+	if unit != "" {
+		fmt.Fprintf(w, "# UNIT %s %s\n", name, unit)
+	}
+
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+
+	for _, sample := range samples {
+		writeSample(w, sample)
+	}
+}
+
+// writeSample выводит строку отсчета
+func writeSample(w *bufio.Writer, sample Sample) {
+	w.WriteString(sample.Name)
+
+	if len(sample.Labels) > 0 {
+		w.WriteByte('{')
+
+		for i, label := range sample.Labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+
+			w.WriteString(label.Name)
+			w.WriteString(`="`)
+			w.WriteString(labelEscaper.Replace(label.Value))
+			w.WriteByte('"')
+		}
+
+		w.WriteByte('}')
+	}
+
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(sample.Value))
+
+	if !sample.Timestamp.IsZero() {
+		w.WriteByte(' ')
+		w.WriteString(strconv.FormatFloat(float64(sample.Timestamp.UnixMilli())/1000, 'f', -1, 64))
+	}
+
+	w.WriteByte('\n')
+}
+
+// formatFloat возвращает строковое представление значения отсчета. Целые значения (в том числе метки времени в
+// секундах) выводятся без экспоненты
+func formatFloat(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,133 @@
+package openmetrics
+
+import (
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/internal/series"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// Family семейство метрик, соответствующее измеряемой величине показания
+type Family struct {
+	// Name наименование метрики без префикса
+	Name string
+
+	// Unit единица измерения метрики. Наименование метрики оканчивается единицей измерения
+	Unit string
+
+	// Help описание метрики
+	Help string
+
+	// Field наименование величины в ответе API Каскада
+	Field string
+}
+
+// Families семейства метрик в порядке вывода
+var Families = []Family{
+	{Name: "mass_tonnes", Unit: "tonnes", Help: "Mass of the heat carrier", Field: "m"},
+	{Name: "volume_cubic_meters", Unit: "cubic_meters", Help: "Volume of the heat carrier", Field: "v"},
+	{Name: "pressure", Help: "Pressure of the heat carrier, kgf/cm2", Field: "p"},
+	{Name: "temperature_celsius", Unit: "celsius", Help: "Temperature of the heat carrier", Field: "t"},
+	{Name: "cold_water_temperature_celsius", Unit: "celsius", Help: "Cold water temperature", Field: "tcw"},
+	{Name: "operating_time_hours", Unit: "hours", Help: "Operating time of the meter", Field: "ti"},
+	{Name: "heat_gcal", Unit: "gcal", Help: "Heat energy", Field: "q"},
+	{Name: "heating_heat_gcal", Unit: "gcal", Help: "Heat energy for heating", Field: "q1"},
+	{Name: "hot_water_heat_gcal", Unit: "gcal", Help: "Heat energy for hot water supply", Field: "q2"},
+	{Name: "bad_row", Help: "Bad row flag of the readings", Field: isBadRow},
+}
+
+// isBadRow наименование признака "плохой" строки показаний
+const isBadRow = "is_bad_row"
+
+// Label метка отсчета
+type Label struct {
+	// Name наименование метки
+	Name string
+
+	// Value значение метки
+	Value string
+}
+
+// Sample отсчет временного ряда
+type Sample struct {
+	// Name наименование метрики
+	Name string
+
+	// Labels метки отсчета в порядке возрастания наименования
+	Labels []Label
+
+	// Value значение отсчета
+	Value float64
+
+	// Timestamp время отсчета. Нулевое значение, если метки времени отключены опцией WithoutTimestamps
+	Timestamp time.Time
+}
+
+// Samples возвращает отсчеты показания: по одному отсчету на каждую измеряемую величину, отличную от null, и
+// отсчет признака "плохой" строки. Метки отсчетов - device, input, channel (channel_num для показаний без
+// идентификатора канала) и, если указан список приборов учета, resource и flow. Для "пустых" строк показаний и
+// показаний без момента времени возвращается nil. Отсчеты пригодны для передачи по протоколу Prometheus remote
+// write
+func Samples(r *parsers.Readings, opts ...Option) []Sample {
+	return samples(r, newEncoderOptions(opts))
+}
+
+func samples(r *parsers.Readings, options *encoderOptions) []Sample {
+	if (r.Empty.Valid && r.Empty.Bool) || r.DT.IsZero() {
+		return nil
+	}
+
+	labels := series.Labels(r, options.topology)
+
+	if len(options.labels) > 0 {
+		labels = series.Merge(labels, options.labels)
+	}
+
+	sampleLabels := make([]Label, len(labels))
+
+	for i, label := range labels {
+		sampleLabels[i] = Label{Name: label.Name, Value: label.Value}
+	}
+
+	var ts time.Time
+
+	if options.timestamps {
+		ts = series.Time(r, options.location)
+	}
+
+	fields := make(map[string]float64)
+
+	for _, field := range series.Fields(r) {
+		fields[field.Name] = field.Value.Float64
+	}
+
+	fields[isBadRow] = 0
+
+	if r.IsBadRow {
+		fields[isBadRow] = 1
+	}
+
+	result := make([]Sample, 0, len(fields))
+
+	for _, family := range Families {
+		if value, ok := fields[family.Field]; ok {
+			result = append(result, Sample{
+				Name:      metricName(options.prefix, family.Name),
+				Labels:    sampleLabels,
+				Value:     value,
+				Timestamp: ts,
+			})
+		}
+	}
+
+	return result
+}
+
+// metricName возвращает наименование метрики с префиксом
+func metricName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "_" + name
+}
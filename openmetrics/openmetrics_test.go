@@ -0,0 +1,87 @@
+package openmetrics
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+func TestSamples(t *testing.T) {
+	r := &parsers.Readings{
+		DeviceID:  null.IntFrom(12032),
+		ChannelID: null.IntFrom(19265),
+		Input:     null.IntFrom(1),
+		DT:        parsers.ReadingTime(time.Date(2021, 4, 11, 1, 0, 0, 0, time.UTC)),
+		M:         null.FloatFrom(2.25),
+		T:         null.FloatFrom(67.09),
+	}
+
+	samples := Samples(r, WithLabels(map[string]string{"site": "main"}))
+
+	require.Len(t, samples, 3)
+
+	labels := []Label{
+		{Name: "channel", Value: "19265"},
+		{Name: "device", Value: "12032"},
+		{Name: "input", Value: "1"},
+		{Name: "site", Value: "main"},
+	}
+
+	ts := time.Date(2021, 4, 11, 1, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, Sample{Name: "cascade_mass_tonnes", Labels: labels, Value: 2.25, Timestamp: ts}, samples[0])
+	assert.Equal(t, Sample{Name: "cascade_temperature_celsius", Labels: labels, Value: 67.09, Timestamp: ts}, samples[1])
+	assert.Equal(t, Sample{Name: "cascade_bad_row", Labels: labels, Value: 0, Timestamp: ts}, samples[2])
+
+	r.Empty = null.BoolFrom(true)
+
+	assert.Nil(t, Samples(r))
+}
+
+func TestEncoder(t *testing.T) {
+	path, err := filepath.Abs("../testdata/responses/readings200.json")
+
+	require.NoError(t, err)
+
+	f, err := os.Open(path)
+
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	items, err := parsers.ParseReadingsFrom(context.TODO(), f)
+
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	e := NewEncoder(&buf, WithPrefix("heat"))
+
+	count, err := e.EncodeItems(context.TODO(), items)
+
+	require.NoError(t, err)
+	assert.Equal(t, 507, count)
+
+	require.NoError(t, e.Close())
+
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "# TYPE heat_mass_tonnes gauge\n# UNIT heat_mass_tonnes tonnes\n"+
+		"# HELP heat_mass_tonnes Mass of the heat carrier\n"+
+		`heat_mass_tonnes{channel="19265",device="12032",input="1"} 2.2514917850494385 1618102800`+"\n"))
+	assert.True(t, strings.HasSuffix(out, "# EOF\n"))
+	assert.Equal(t, 1, strings.Count(out, "# TYPE heat_mass_tonnes gauge"))
+	assert.Equal(t, 507, strings.Count(out, "heat_bad_row{"))
+
+	assert.Error(t, e.Encode(&parsers.Readings{}))
+}
@@ -0,0 +1,76 @@
+package openmetrics
+
+import (
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+// DefaultPrefix префикс наименований метрик по умолчанию
+const DefaultPrefix = "cascade"
+
+type encoderOptions struct {
+	prefix     string
+	location   *time.Location
+	topology   *topology.Topology
+	labels     map[string]string
+	timestamps bool
+}
+
+func newEncoderOptions(opts []Option) *encoderOptions {
+	options := &encoderOptions{
+		prefix:     DefaultPrefix,
+		location:   time.UTC,
+		timestamps: true,
+	}
+
+	for _, option := range opts {
+		option(options)
+	}
+
+	if options.location == nil {
+		options.location = time.UTC
+	}
+
+	return options
+}
+
+// Option опция кодирования показаний
+type Option func(options *encoderOptions)
+
+// WithPrefix устанавливает префикс наименований метрик. По умолчанию DefaultPrefix
+func WithPrefix(prefix string) Option {
+	return func(options *encoderOptions) {
+		options.prefix = prefix
+	}
+}
+
+// WithLocation устанавливает часовой пояс сервера Каскад, в котором указано время показаний. По умолчанию UTC
+func WithLocation(loc *time.Location) Option {
+	return func(options *encoderOptions) {
+		options.location = loc
+	}
+}
+
+// WithTopology устанавливает список приборов учета для определения меток resource и flow
+func WithTopology(topo *topology.Topology) Option {
+	return func(options *encoderOptions) {
+		options.topology = topo
+	}
+}
+
+// WithLabels устанавливает дополнительные метки всех отсчетов. Метки показания имеют приоритет над одноименными
+// дополнительными метками
+func WithLabels(labels map[string]string) Option {
+	return func(options *encoderOptions) {
+		options.labels = labels
+	}
+}
+
+// WithoutTimestamps отключает вывод меток времени отсчетов. Используется при публикации последних показаний для
+// сбора Prometheus, который назначает отсчетам время опроса
+func WithoutTimestamps() Option {
+	return func(options *encoderOptions) {
+		options.timestamps = false
+	}
+}
@@ -1,11 +1,14 @@
 package cascade
 
 import (
+	"context"
 	"net/http"
+	"time"
 )
 
 type connOptions struct {
-	client *http.Client
+	client      *http.Client
+	gaugesCache *GaugesCache
 }
 
 type openOptions struct {
@@ -22,6 +25,13 @@ func WithHTTPClient(client *http.Client) Option {
 	}
 }
 
+// WithGaugesCache устанавливает кэш списка приборов учета, возвращаемого методом Gauges
+func WithGaugesCache(cache *GaugesCache) Option {
+	return func(options *connOptions) {
+		options.gaugesCache = cache
+	}
+}
+
 // OpenOption опция открытия соединения с API Каскад
 type OpenOption func(options *openOptions)
 
@@ -31,3 +41,28 @@ func WithAuthURL(authURL string) OpenOption {
 		options.authURL = authURL
 	}
 }
+
+type cacheOptions struct {
+	ttl       time.Duration
+	onChanged func(ctx context.Context, previous, current []byte)
+}
+
+// CacheOption опция кэша списка приборов учета
+type CacheOption func(options *cacheOptions)
+
+// WithCacheTTL устанавливает срок актуальности кэшированного списка приборов учета (по умолчанию DefaultCacheTTL).
+// По истечении срока список запрашивается повторно. Если ttl не положителен, список проверяется при каждом вызове
+// Gauges
+func WithCacheTTL(ttl time.Duration) CacheOption {
+	return func(options *cacheOptions) {
+		options.ttl = ttl
+	}
+}
+
+// WithGaugesChanged устанавливает функцию, вызываемую, если повторно полученный список приборов учета отличается от
+// кэшированного. Функция вызывается синхронно из Gauges до возврата нового списка
+func WithGaugesChanged(fn func(ctx context.Context, previous, current []byte)) CacheOption {
+	return func(options *cacheOptions) {
+		options.onChanged = fn
+	}
+}
@@ -0,0 +1,52 @@
+package pairing
+
+import (
+	"math"
+)
+
+// enthalpyTable удельная энтальпия воды на линии насыщения (кДж/кг) для температур от 0 до 200 °C с шагом 10 °C
+var enthalpyTable = [...]float64{
+	0.00, 42.02, 83.91, 125.7, 167.5, 209.3, 251.1, 293.0, 334.9, 376.9, 419.1,
+	461.3, 503.7, 546.3, 589.1, 632.2, 675.5, 719.2, 763.2, 807.5, 852.4,
+}
+
+// enthalpyStep шаг таблицы энтальпии, °C
+const enthalpyStep = 10.0
+
+// kJPerGcal количество кДж в одной Гкал
+const kJPerGcal = 4186800.0
+
+// WaterEnthalpy возвращает удельную энтальпию воды (кДж/кг) при температуре t (°C). Значение определяется линейной
+// интерполяцией табличных значений на линии насыщения; влияние давления в тепловых сетях на энтальпию воды мало и
+// не учитывается. За пределами таблицы значение экстраполируется по крайним интервалам. Для NaN и бесконечных
+// значений температуры возвращается NaN
+func WaterEnthalpy(t float64) float64 {
+	if math.IsNaN(t) || math.IsInf(t, 0) {
+		return math.NaN()
+	}
+
+	// индекс интервала определяется до преобразования в int, чтобы большие значения t не переполняли его
+	i := len(enthalpyTable) - 2
+
+	switch x := t / enthalpyStep; {
+	case x < 0:
+		i = 0
+	case x < float64(i):
+		i = int(x)
+	}
+
+	t0 := float64(i) * enthalpyStep
+	h0, h1 := enthalpyTable[i], enthalpyTable[i+1]
+
+	return h0 + (h1-h0)*(t-t0)/enthalpyStep
+}
+
+// heat возвращает тепловую энергию (Гкал), переданную с массой теплоносителя m1 (т) по подающему трубопроводу при
+// температуре t1 и возвращенную с массой m2 по обратному трубопроводу при температуре t2, относительно холодной воды
+// с температурой tcw
+func heat(m1, t1, m2, t2, tcw float64) float64 {
+	hcw := WaterEnthalpy(tcw)
+
+	// масса в тоннах переводится в кг, энтальпия в кДж/кг
+	return (m1*(WaterEnthalpy(t1)-hcw) - m2*(WaterEnthalpy(t2)-hcw)) * 1000 / kJPerGcal
+}
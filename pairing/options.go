@@ -0,0 +1,19 @@
+package pairing
+
+type pairOptions struct {
+	coldWaterTemperature float64
+}
+
+// defaultColdWaterTemperature температура холодной воды по умолчанию, °C
+const defaultColdWaterTemperature = 5.0
+
+// Option опция сопоставления показаний
+type Option func(options *pairOptions)
+
+// WithColdWaterTemperature устанавливает температуру холодной воды (°C), используемую при расчете тепловой энергии,
+// если температура холодной воды в показаниях не указана. По умолчанию 5 °C
+func WithColdWaterTemperature(t float64) Option {
+	return func(options *pairOptions) {
+		options.coldWaterTemperature = t
+	}
+}
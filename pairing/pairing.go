@@ -0,0 +1,216 @@
+package pairing
+
+import (
+	"sort"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+// Pair показания подающего и обратного трубопроводов теплового ввода по одному ресурсу за один момент времени с
+// расчетными величинами
+type Pair struct {
+	// DeviceID идентификатор прибора учета
+	DeviceID int64
+
+	// Input номер теплового ввода
+	Input int32
+
+	// Resource тип ресурса
+	Resource parsers.Resource
+
+	// DT момент показания
+	DT time.Time
+
+	// Supply показание подающего трубопровода. Если показание отсутствует, имеет значение nil
+	Supply *parsers.Readings
+
+	// Return показание обратного трубопровода. Если показание отсутствует, имеет значение nil
+	Return *parsers.Readings
+
+	// DeltaT разность температур подающего и обратного трубопроводов (T1 - T2), °C
+	DeltaT null.Float
+
+	// MassImbalance небаланс масс подающего и обратного трубопроводов (M1 - M2), т
+	MassImbalance null.Float
+
+	// Heat тепловая энергия, рассчитанная по массам и температурам теплоносителя, Гкал.
+	//
+	// Если масса обратного трубопровода не указана, система считается закрытой (M2 = M1)
+	Heat null.Float
+
+	// MeterHeat тепловая энергия по данным прибора учета, Гкал
+	MeterHeat null.Float
+}
+
+// Complete возвращает признак наличия показаний обоих трубопроводов
+func (p *Pair) Complete() bool {
+	return p.Supply != nil && p.Return != nil
+}
+
+// HeatDiscrepancy возвращает разность тепловой энергии по данным прибора учета и расчетной тепловой энергии, Гкал
+func (p *Pair) HeatDiscrepancy() null.Float {
+	if !p.MeterHeat.Valid || !p.Heat.Valid {
+		return null.Float{}
+	}
+
+	return null.FloatFrom(p.MeterHeat.Float64 - p.Heat.Float64)
+}
+
+// pairKey ключ сопоставления показаний
+type pairKey struct {
+	device   int64
+	input    int32
+	resource parsers.Resource
+	dt       int64
+}
+
+// inputKey ключ поиска показаний теплового ввода без указания ресурса
+type inputKey struct {
+	device int64
+	input  int32
+	dt     int64
+}
+
+// Pairs сопоставляет показания подающих и обратных трубопроводов одного теплового ввода, ресурса и момента времени
+// и рассчитывает производные величины. Тип ресурса и тип подключения каналов определяются по topo. Показания
+// каналов других типов подключения используются только как источник тепловой энергии по данным прибора учета.
+//
+// Пары упорядочены по прибору учета, тепловому вводу, ресурсу и моменту показания. Если для показания не нашлось
+// пары, возвращается неполная пара
+func Pairs(topo *topology.Topology, readings []parsers.Readings, opts ...Option) []*Pair {
+	options := &pairOptions{coldWaterTemperature: defaultColdWaterTemperature}
+
+	for _, option := range opts {
+		option(options)
+	}
+
+	pairs := make(map[pairKey]*Pair)
+	totals := make(map[inputKey]*parsers.Readings)
+
+	for i := range readings {
+		r := &readings[i]
+
+		channel, ok := topo.ChannelOf(r)
+
+		if !ok {
+			continue
+		}
+
+		dt := time.Time(r.DT)
+
+		if channel.Resource() == parsers.ResourceNone {
+			totals[inputKey{device: channel.Device.ID, input: channel.Input.Number, dt: dt.UnixNano()}] = r
+			continue
+		}
+
+		flow := channel.Flow()
+
+		if flow != parsers.FlowDirect && flow != parsers.FlowReverse {
+			continue
+		}
+
+		key := pairKey{
+			device:   channel.Device.ID,
+			input:    channel.Input.Number,
+			resource: channel.Resource(),
+			dt:       dt.UnixNano(),
+		}
+
+		pair, ok := pairs[key]
+
+		if !ok {
+			pair = &Pair{DeviceID: key.device, Input: key.input, Resource: key.resource, DT: dt}
+			pairs[key] = pair
+		}
+
+		if flow == parsers.FlowDirect {
+			pair.Supply = r
+		} else {
+			pair.Return = r
+		}
+	}
+
+	result := make([]*Pair, 0, len(pairs))
+
+	for key, pair := range pairs {
+		pair.derive(options, totals[inputKey{device: key.device, input: key.input, dt: key.dt}])
+		result = append(result, pair)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+
+		switch {
+		case a.DeviceID != b.DeviceID:
+			return a.DeviceID < b.DeviceID
+		case a.Input != b.Input:
+			return a.Input < b.Input
+		case a.Resource != b.Resource:
+			return a.Resource < b.Resource
+		default:
+			return a.DT.Before(b.DT)
+		}
+	})
+
+	return result
+}
+
+// derive рассчитывает производные величины пары. total - показание теплового ввода в целом, если оно есть
+func (p *Pair) derive(options *pairOptions, total *parsers.Readings) {
+	if p.Supply != nil && p.Supply.Q.Valid {
+		p.MeterHeat = p.Supply.Q
+	} else if total != nil {
+		p.MeterHeat = meterHeat(p.Resource, total)
+	}
+
+	if !p.Complete() {
+		return
+	}
+
+	t1, t2 := p.Supply.T, p.Return.T
+	m1, m2 := p.Supply.M, p.Return.M
+
+	if t1.Valid && t2.Valid {
+		p.DeltaT = null.FloatFrom(t1.Float64 - t2.Float64)
+	}
+
+	if m1.Valid && m2.Valid {
+		p.MassImbalance = null.FloatFrom(m1.Float64 - m2.Float64)
+	}
+
+	if !m1.Valid || !t1.Valid || !t2.Valid {
+		return
+	}
+
+	if !m2.Valid {
+		m2 = m1
+	}
+
+	tcw := options.coldWaterTemperature
+
+	switch {
+	case p.Supply.TCW.Valid:
+		tcw = p.Supply.TCW.Float64
+	case p.Return.TCW.Valid:
+		tcw = p.Return.TCW.Float64
+	}
+
+	p.Heat = null.FloatFrom(heat(m1.Float64, t1.Float64, m2.Float64, t2.Float64, tcw))
+}
+
+// meterHeat возвращает тепловую энергию по ресурсу из показания теплового ввода в целом. Тепловая энергия по
+// вводу в целом (Q) к ресурсу не относится и не возвращается
+func meterHeat(resource parsers.Resource, total *parsers.Readings) null.Float {
+	switch {
+	case resource == parsers.ResourceHeat && total.Q1.Valid:
+		return total.Q1
+	case resource == parsers.ResourceHotWater && total.Q2.Valid:
+		return total.Q2
+	default:
+		return null.Float{}
+	}
+}
@@ -0,0 +1,119 @@
+package pairing
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+func testTopology() *topology.Topology {
+	return topology.New([]parsers.Gauge{
+		{
+			ID: 1,
+			Inputs: []parsers.Input{
+				{
+					Number: 1,
+					Channels: []parsers.Channel{
+						{ID: 10, Number: 1, Resource: parsers.ResourceHeat, Flow: parsers.FlowDirect},
+						{ID: 11, Number: 2, Resource: parsers.ResourceHeat, Flow: parsers.FlowReverse},
+						{ID: 12, Number: 3, Resource: parsers.ResourceHotWater, Flow: parsers.FlowDirect},
+						{ID: 13, Number: 0, Resource: parsers.ResourceNone},
+					},
+				},
+			},
+		},
+	})
+}
+
+func reading(channelID int64, dt time.Time, m, t null.Float) parsers.Readings {
+	return parsers.Readings{
+		DeviceID:  null.IntFrom(1),
+		Input:     null.IntFrom(1),
+		ChannelID: null.IntFrom(channelID),
+		DT:        parsers.ReadingTime(dt),
+		M:         m,
+		T:         t,
+	}
+}
+
+func TestPairs(t *testing.T) {
+	dt := time.Date(2021, 4, 11, 1, 0, 0, 0, time.UTC)
+
+	total := reading(13, dt, null.Float{}, null.Float{})
+	total.Q1 = null.FloatFrom(0.3)
+
+	readings := []parsers.Readings{
+		reading(10, dt, null.FloatFrom(10), null.FloatFrom(70)),
+		reading(11, dt, null.FloatFrom(9.8), null.FloatFrom(40)),
+		reading(10, dt.Add(time.Hour), null.FloatFrom(10), null.FloatFrom(70)),
+		reading(11, dt.Add(time.Hour), null.Float{}, null.FloatFrom(40)),
+		reading(12, dt, null.FloatFrom(1), null.FloatFrom(60)),
+		reading(99, dt, null.FloatFrom(1), null.FloatFrom(60)),
+		total,
+	}
+
+	pairs := Pairs(testTopology(), readings, WithColdWaterTemperature(5))
+
+	require.Len(t, pairs, 3)
+
+	pair := pairs[0]
+
+	assert.Equal(t, parsers.ResourceHeat, pair.Resource)
+	assert.True(t, pair.DT.Equal(dt))
+	require.True(t, pair.Complete())
+	assert.InDelta(t, 30, pair.DeltaT.Float64, 1e-9)
+	assert.InDelta(t, 0.2, pair.MassImbalance.Float64, 1e-9)
+
+	expected := (10*(293.0-21.01) - 9.8*(167.5-21.01)) / 4186.8
+
+	assert.InDelta(t, expected, pair.Heat.Float64, 1e-9)
+	assert.InDelta(t, 0.3, pair.MeterHeat.Float64, 1e-9)
+	assert.InDelta(t, 0.3-expected, pair.HeatDiscrepancy().Float64, 1e-9)
+
+	pair = pairs[1]
+
+	require.True(t, pair.Complete())
+	assert.False(t, pair.MassImbalance.Valid)
+	assert.InDelta(t, 10*(293.0-167.5)/4186.8, pair.Heat.Float64, 1e-9)
+	assert.False(t, pair.MeterHeat.Valid)
+
+	pair = pairs[2]
+
+	assert.Equal(t, parsers.ResourceHotWater, pair.Resource)
+	assert.False(t, pair.Complete())
+	assert.Nil(t, pair.Return)
+	assert.False(t, pair.Heat.Valid)
+}
+
+func TestWaterEnthalpy(t *testing.T) {
+	var cases = []struct {
+		t, h float64
+	}{
+		{t: 0, h: 0},
+		{t: 5, h: 21.01},
+		{t: 70, h: 293.0},
+		{t: 95, h: 398.0},
+		{t: 200, h: 852.4},
+		{t: 250, h: 1076.9},
+		{t: -5, h: -21.01},
+	}
+
+	for _, test := range cases {
+		assert.InDelta(t, test.h, WaterEnthalpy(test.t), 1e-9, test.t)
+	}
+
+	for _, temperature := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		assert.True(t, math.IsNaN(WaterEnthalpy(temperature)), temperature)
+	}
+
+	for _, temperature := range []float64{1e300, math.MaxFloat64, -1e300} {
+		assert.False(t, math.IsNaN(WaterEnthalpy(temperature)), temperature)
+	}
+}
@@ -0,0 +1,25 @@
+module github.com/vitpelekhaty/go-cascade-client/v2/parquetexport
+
+go 1.24.9
+
+require (
+	github.com/guregu/null v4.0.0+incompatible
+	github.com/parquet-go/parquet-go v0.32.0
+	github.com/stretchr/testify v1.7.1
+	github.com/vitpelekhaty/go-cascade-client/v2 v2.1.0
+)
+
+require (
+	github.com/andybalholm/brotli v1.1.1 // indirect
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/parquet-go/bitpack v1.0.0 // indirect
+	github.com/parquet-go/jsonlite v1.0.0 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/twpayne/go-geom v1.6.1 // indirect
+	golang.org/x/sys v0.38.0 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
@@ -0,0 +1,61 @@
+package parquetexport
+
+import (
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+// defaultBatchSize количество показаний, передаваемых в файл за одну операцию записи
+const defaultBatchSize = 1024
+
+// DefaultMaxOpenPartitions наибольшее количество одновременно открытых файлов секций по умолчанию
+const DefaultMaxOpenPartitions = 32
+
+type writerOptions struct {
+	topology *topology.Topology
+	codec    compress.Codec
+	maxOpen  int
+}
+
+func newWriterOptions(opts []Option) *writerOptions {
+	options := &writerOptions{
+		codec:   &parquet.Snappy,
+		maxOpen: DefaultMaxOpenPartitions,
+	}
+
+	for _, option := range opts {
+		option(options)
+	}
+
+	return options
+}
+
+// Option опция вывода показаний в файл Parquet
+type Option func(options *writerOptions)
+
+// WithTopology дополняет показания типом ресурса, типом подключения канала и наименованием прибора учета из
+// списка приборов учета topo
+func WithTopology(topo *topology.Topology) Option {
+	return func(options *writerOptions) {
+		options.topology = topo
+	}
+}
+
+// WithCompression устанавливает алгоритм сжатия колонок. По умолчанию Snappy
+func WithCompression(codec compress.Codec) Option {
+	return func(options *writerOptions) {
+		options.codec = codec
+	}
+}
+
+// WithMaxOpenPartitions устанавливает наибольшее количество одновременно открытых файлов секций PartitionedWriter
+// (по умолчанию DefaultMaxOpenPartitions). Значения меньше 1 не учитываются
+func WithMaxOpenPartitions(n int) Option {
+	return func(options *writerOptions) {
+		if n > 0 {
+			options.maxOpen = n
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package parquetexport
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+func testTopology() *topology.Topology {
+	return topology.New([]parsers.Gauge{
+		{
+			ID:    12032,
+			Title: "test",
+			Inputs: []parsers.Input{
+				{
+					Number: 1,
+					Channels: []parsers.Channel{
+						{ID: 19265, Number: 1, Resource: parsers.ResourceHeat, Flow: parsers.FlowDirect},
+						{ID: 19288, Number: 0, Resource: parsers.ResourceNone},
+					},
+				},
+			},
+		},
+	})
+}
+
+func parseReadings(t *testing.T) <-chan parsers.Item {
+	path, err := filepath.Abs("../testdata/responses/readings200.json")
+
+	require.NoError(t, err)
+
+	f, err := os.Open(path)
+
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = f.Close()
+	})
+
+	items, err := parsers.ParseReadingsFrom(context.TODO(), f)
+
+	require.NoError(t, err)
+
+	return items
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := NewWriter(&buf, WithTopology(testTopology()))
+
+	count, err := w.WriteItems(context.TODO(), parseReadings(t))
+
+	require.NoError(t, err)
+	assert.Equal(t, 507, count)
+
+	err = w.Close()
+
+	require.NoError(t, err)
+
+	rows, err := parquet.Read[Row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+
+	require.NoError(t, err)
+	require.Len(t, rows, 507)
+
+	row := rows[0]
+
+	require.NotNil(t, row.ID)
+	assert.Equal(t, int64(14042944), *row.ID)
+	assert.Equal(t, "Hour", row.Archive)
+	require.NotNil(t, row.DT)
+	assert.True(t, row.DT.Equal(time.Date(2021, 4, 11, 1, 0, 0, 0, time.UTC)))
+	require.NotNil(t, row.M)
+	assert.Equal(t, 2.2514917850494385, *row.M)
+	assert.Nil(t, row.Q)
+	require.NotNil(t, row.Resource)
+	assert.Equal(t, "Heat", *row.Resource)
+	require.NotNil(t, row.Flow)
+	assert.Equal(t, "inFlow", *row.Flow)
+	require.NotNil(t, row.DeviceTitle)
+	assert.Equal(t, "test", *row.DeviceTitle)
+
+	var enriched, unknown int
+
+	for _, row := range rows {
+		switch {
+		case row.Resource == nil:
+			unknown++
+		case *row.Resource == "None":
+			assert.Nil(t, row.Flow)
+			assert.Nil(t, row.M)
+			assert.NotNil(t, row.Q)
+			enriched++
+		default:
+			enriched++
+		}
+	}
+
+	assert.Equal(t, 169*2, enriched)
+	assert.Equal(t, 169, unknown)
+
+	schema := parquet.SchemaOf(Row{})
+
+	for _, name := range []string{"m", "dt", "resource"} {
+		field, ok := schema.Lookup(name)
+
+		require.True(t, ok, name)
+		assert.True(t, field.Node.Optional(), name)
+	}
+}
+
+func TestPartitionedWriter(t *testing.T) {
+	dir := t.TempDir()
+
+	w := NewPartitionedWriter(dir)
+
+	count, err := w.WriteItems(context.TODO(), parseReadings(t))
+
+	require.NoError(t, err)
+	assert.Equal(t, 507, count)
+
+	err = w.Close()
+
+	require.NoError(t, err)
+
+	files := w.Files()
+
+	require.Equal(t, []string{filepath.Join(dir, "device_id=12032", "month=2021-04", "part-00000.parquet")}, files)
+
+	rows, err := parquet.ReadFile[Row](files[0])
+
+	require.NoError(t, err)
+	assert.Len(t, rows, 507)
+}
+
+func TestPartitionedWriter_Stale(t *testing.T) {
+	dir := t.TempDir()
+	partition := filepath.Join(dir, "device_id=1", "month=2021-04")
+	other := filepath.Join(dir, "device_id=2", "month=2021-04")
+
+	for _, stale := range []string{
+		filepath.Join(partition, "part-00000.parquet"),
+		filepath.Join(partition, "part-00001.parquet"),
+		filepath.Join(other, "part-00000.parquet"),
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(stale), 0o755))
+		require.NoError(t, os.WriteFile(stale, []byte("stale"), 0o644))
+	}
+
+	w := NewPartitionedWriter(dir)
+
+	err := w.Write(&parsers.Readings{
+		ID:       null.IntFrom(1),
+		DeviceID: null.IntFrom(1),
+		DT:       parsers.ReadingTime(time.Date(2021, 4, 11, 1, 0, 0, 0, time.UTC)),
+	})
+
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	parts, err := filepath.Glob(filepath.Join(partition, "*.parquet"))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(partition, "part-00000.parquet")}, parts)
+
+	rows, err := parquet.ReadFile[Row](parts[0])
+
+	require.NoError(t, err)
+	assert.Len(t, rows, 1)
+
+	assert.FileExists(t, filepath.Join(other, "part-00000.parquet"))
+}
+
+func TestPartitionedWriter_MaxOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	w := NewPartitionedWriter(dir, WithMaxOpenPartitions(1))
+
+	for _, deviceID := range []int64{1, 2, 1} {
+		err := w.Write(&parsers.Readings{
+			ID:       null.IntFrom(deviceID),
+			DeviceID: null.IntFrom(deviceID),
+			DT:       parsers.ReadingTime(time.Date(2021, 4, 11, 1, 0, 0, 0, time.UTC)),
+		})
+
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, w.Close())
+
+	files := w.Files()
+
+	require.Equal(t, []string{
+		filepath.Join(dir, "device_id=1", "month=2021-04", "part-00000.parquet"),
+		filepath.Join(dir, "device_id=1", "month=2021-04", "part-00001.parquet"),
+		filepath.Join(dir, "device_id=2", "month=2021-04", "part-00000.parquet"),
+	}, files)
+
+	for _, file := range files {
+		rows, err := parquet.ReadFile[Row](file)
+
+		require.NoError(t, err)
+		assert.Len(t, rows, 1)
+	}
+}
@@ -0,0 +1,185 @@
+package parquetexport
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// unknownPartition значение ключа секции для показаний без прибора учета или момента показания
+const unknownPartition = "unknown"
+
+// partitionKey ключ секции файлов
+type partitionKey struct {
+	device string
+	month  string
+}
+
+// partition открытый файл секции
+type partition struct {
+	key  partitionKey
+	f    *os.File
+	w    *Writer
+	elem *list.Element
+}
+
+// PartitionedWriter выводит показания в набор файлов Parquet, разделенный по приборам учета и месяцам показаний.
+// Файлы размещаются в каталогах вида dir/device_id=<идентификатор>/month=<ГГГГ-ММ>/part-00000.parquet
+// (секционирование в стиле Hive).
+//
+// Количество одновременно открытых файлов ограничено (см. WithMaxOpenPartitions): при превышении ограничения
+// завершается файл секции, в которую показания не выводились дольше других. Если после этого в секцию поступают
+// новые показания, они выводятся в следующий файл секции (part-00001.parquet и т.д.)
+type PartitionedWriter struct {
+	dir     string
+	opts    []Option
+	maxOpen int
+
+	open  map[partitionKey]*partition
+	lru   *list.List
+	parts map[partitionKey]int
+	files []string
+}
+
+// NewPartitionedWriter возвращает PartitionedWriter, выводящий показания в каталог dir. Файлы секции, оставшиеся
+// от предыдущей выгрузки, удаляются перед выводом первого показания секции, поэтому секция содержит только файлы
+// этой выгрузки. Секции, в которые показания не выводятся, не изменяются
+func NewPartitionedWriter(dir string, opts ...Option) *PartitionedWriter {
+	return &PartitionedWriter{
+		dir:     dir,
+		opts:    opts,
+		maxOpen: newWriterOptions(opts).maxOpen,
+		open:    make(map[partitionKey]*partition),
+		lru:     list.New(),
+		parts:   make(map[partitionKey]int),
+	}
+}
+
+// Write выводит показание в файл его секции
+func (w *PartitionedWriter) Write(r *parsers.Readings) error {
+	key := partitionKey{device: unknownPartition, month: unknownPartition}
+
+	if r.DeviceID.Valid {
+		key.device = fmt.Sprint(r.DeviceID.Int64)
+	}
+
+	if !r.DT.IsZero() {
+		key.month = time.Time(r.DT).Format("2006-01")
+	}
+
+	p, ok := w.open[key]
+
+	if ok {
+		w.lru.MoveToFront(p.elem)
+	} else {
+		if len(w.open) >= w.maxOpen {
+			if err := w.closePartition(w.lru.Back().Value.(*partition)); err != nil {
+				return err
+			}
+		}
+
+		var err error
+
+		if p, err = w.openPartition(key); err != nil {
+			return err
+		}
+	}
+
+	return p.w.Write(r)
+}
+
+// WriteItems выводит показания, полученные от ParseReadings или ParseReadingsFrom, по мере их разбора (см.
+// parsers.Each) и возвращает количество выведенных показаний. Как и после Write, файлы завершаются вызовом Close.
+// Опция parsers.WithSkip пропускает записи архива с ошибкой разбора
+func (w *PartitionedWriter) WriteItems(ctx context.Context, items <-chan parsers.Item,
+	options ...parsers.EachOption) (int, error) {
+	return parsers.EachReadings(ctx, items, w.Write, options...)
+}
+
+// Files возвращает пути файлов секций в алфавитном порядке
+func (w *PartitionedWriter) Files() []string {
+	files := append([]string(nil), w.files...)
+
+	sort.Strings(files)
+
+	return files
+}
+
+// Close завершает и закрывает открытые файлы секций. Возвращает первую возникшую ошибку
+func (w *PartitionedWriter) Close() error {
+	var result error
+
+	for w.lru.Len() > 0 {
+		if err := w.closePartition(w.lru.Back().Value.(*partition)); result == nil {
+			result = err
+		}
+	}
+
+	return result
+}
+
+func (w *PartitionedWriter) openPartition(key partitionKey) (*partition, error) {
+	dir := filepath.Join(w.dir, "device_id="+key.device, "month="+key.month)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	if w.parts[key] == 0 {
+		if err := removeParts(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.Create(filepath.Join(dir, fmt.Sprintf("part-%05d.parquet", w.parts[key])))
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := &partition{key: key, f: f, w: NewWriter(f, w.opts...)}
+	p.elem = w.lru.PushFront(p)
+
+	w.open[key] = p
+	w.parts[key]++
+	w.files = append(w.files, f.Name())
+
+	return p, nil
+}
+
+// removeParts удаляет файлы секции в каталоге dir
+func removeParts(dir string) error {
+	parts, err := filepath.Glob(filepath.Join(dir, "part-*.parquet"))
+
+	if err != nil {
+		return err
+	}
+
+	for _, part := range parts {
+		if err := os.Remove(part); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closePartition завершает и закрывает файл секции p
+func (w *PartitionedWriter) closePartition(p *partition) error {
+	w.lru.Remove(p.elem)
+	delete(w.open, p.key)
+
+	err := p.w.Close()
+
+	if closeErr := p.f.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
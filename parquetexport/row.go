@@ -0,0 +1,143 @@
+package parquetexport
+
+import (
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+// Row строка показаний в файле Parquet. Структура определяет схему файла; значения null хранятся в необязательных
+// (optional) колонках. Время показаний в Каскаде не содержит часового пояса и записывается по показаниям часов
+// как время UTC
+type Row struct {
+	// ID идентификатор показания
+	ID *int64 `parquet:"id,optional"`
+
+	// DeviceID идентификатор прибора учета
+	DeviceID *int64 `parquet:"device_id,optional"`
+
+	// Input номер теплового ввода
+	Input *int64 `parquet:"input_num,optional"`
+
+	// ChannelID идентификатор канала
+	ChannelID *int64 `parquet:"channel_id,optional"`
+
+	// ChannelNum номер канала
+	ChannelNum *int64 `parquet:"channel_num,optional"`
+
+	// Archive тип архива
+	Archive string `parquet:"archive_type,dict"`
+
+	// DT момент показания
+	DT *time.Time `parquet:"dt,optional,timestamp(millisecond)"`
+
+	// CreateAt момент чтения показания
+	CreateAt *time.Time `parquet:"create_at,optional,timestamp(millisecond)"`
+
+	// IsBadRow признак "плохой" строки показания
+	IsBadRow bool `parquet:"is_bad_row"`
+
+	// Empty признак "пустой" строки показания
+	Empty *bool `parquet:"is_empty,optional"`
+
+	// M расход теплоносителя, т
+	M *float64 `parquet:"m,optional"`
+
+	// V расход теплоносителя, м3
+	V *float64 `parquet:"v,optional"`
+
+	// P давление
+	P *float64 `parquet:"p,optional"`
+
+	// T температура теплоносителя
+	T *float64 `parquet:"t,optional"`
+
+	// TCW температура холодной воды
+	TCW *float64 `parquet:"tcw,optional"`
+
+	// TI время штатной работы прибора учета
+	TI *float64 `parquet:"ti,optional"`
+
+	// Q тепловая энергия по всему вводу, Гкал
+	Q *float64 `parquet:"q,optional"`
+
+	// Q1 тепловая энергия по отоплению, Гкал
+	Q1 *float64 `parquet:"q1,optional"`
+
+	// Q2 тепловая энергия по ГВС, Гкал
+	Q2 *float64 `parquet:"q2,optional"`
+
+	// Resource тип ресурса канала. Заполняется, если указан список приборов учета
+	Resource *string `parquet:"resource,optional,dict"`
+
+	// Flow тип подключения канала. Заполняется, если указан список приборов учета
+	Flow *string `parquet:"flow,optional,dict"`
+
+	// DeviceTitle наименование прибора учета в АИСКУТЭ. Заполняется, если указан список приборов учета
+	DeviceTitle *string `parquet:"device_title,optional,dict"`
+}
+
+// NewRow возвращает строку файла Parquet для показания r. Если указан список приборов учета topo, строка
+// дополняется типом ресурса, типом подключения канала и наименованием прибора учета
+func NewRow(r *parsers.Readings, topo *topology.Topology) Row {
+	row := Row{
+		ID:         intPtr(r.ID),
+		DeviceID:   intPtr(r.DeviceID),
+		Input:      intPtr(r.Input),
+		ChannelID:  intPtr(r.ChannelID),
+		ChannelNum: intPtr(r.ChannelNum),
+		Archive:    r.Archive.String(),
+		DT:         timePtr(r.DT),
+		CreateAt:   timePtr(r.CreateAt),
+		IsBadRow:   r.IsBadRow,
+		Empty:      r.Empty.Ptr(),
+		M:          r.M.Ptr(),
+		V:          r.V.Ptr(),
+		P:          r.P.Ptr(),
+		T:          r.T.Ptr(),
+		TCW:        r.TCW.Ptr(),
+		TI:         r.TI.Ptr(),
+		Q:          r.Q.Ptr(),
+		Q1:         r.Q1.Ptr(),
+		Q2:         r.Q2.Ptr(),
+	}
+
+	if topo == nil {
+		return row
+	}
+
+	reading, ok := topo.Enrich(r)
+
+	if reading.Device != nil {
+		row.DeviceTitle = &reading.Device.Title
+	}
+
+	if ok {
+		resource, flow := reading.Resource.String(), reading.Flow.String()
+
+		row.Resource = &resource
+
+		if reading.Flow != parsers.FlowUnknown {
+			row.Flow = &flow
+		}
+	}
+
+	return row
+}
+
+func intPtr(v null.Int) *int64 {
+	return v.Ptr()
+}
+
+func timePtr(v parsers.ReadingTime) *time.Time {
+	if v.IsZero() {
+		return nil
+	}
+
+	t := parsers.WallClock(time.Time(v))
+
+	return &t
+}
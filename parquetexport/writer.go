@@ -0,0 +1,68 @@
+package parquetexport
+
+import (
+	"context"
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// Writer выводит показания в файл Parquet
+type Writer struct {
+	writer  *parquet.GenericWriter[Row]
+	options *writerOptions
+	buf     []Row
+}
+
+// NewWriter возвращает Writer, выводящий показания в w. Файл завершается вызовом Close
+func NewWriter(w io.Writer, opts ...Option) *Writer {
+	options := newWriterOptions(opts)
+
+	return &Writer{
+		writer:  parquet.NewGenericWriter[Row](w, parquet.Compression(options.codec)),
+		options: options,
+		buf:     make([]Row, 0, defaultBatchSize),
+	}
+}
+
+// Write выводит показание
+func (w *Writer) Write(r *parsers.Readings) error {
+	w.buf = append(w.buf, NewRow(r, w.options.topology))
+
+	if len(w.buf) < cap(w.buf) {
+		return nil
+	}
+
+	return w.flushRows()
+}
+
+// WriteItems выводит показания, полученные от ParseReadings или ParseReadingsFrom, по мере их разбора (см.
+// parsers.Each) и возвращает количество выведенных показаний. Как и после Write, файл завершается вызовом Close.
+// Опция parsers.WithSkip пропускает записи архива с ошибкой разбора
+func (w *Writer) WriteItems(ctx context.Context, items <-chan parsers.Item,
+	options ...parsers.EachOption) (int, error) {
+	return parsers.EachReadings(ctx, items, w.Write, options...)
+}
+
+// Close выводит накопленные показания и завершает файл
+func (w *Writer) Close() error {
+	if err := w.flushRows(); err != nil {
+		return err
+	}
+
+	return w.writer.Close()
+}
+
+func (w *Writer) flushRows() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	_, err := w.writer.Write(w.buf)
+
+	w.buf = w.buf[:0]
+
+	return err
+}
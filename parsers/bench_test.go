@@ -0,0 +1,109 @@
+package parsers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func loadBenchmarkData(b *testing.B) []byte {
+	path, err := filepath.Abs("../testdata/responses/readings200.json")
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return data
+}
+
+func BenchmarkUnmarshalReadings(b *testing.B) {
+	data := loadBenchmarkData(b)
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var readings []Readings
+
+		if err := json.Unmarshal(data, &readings); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseReadings(b *testing.B) {
+	data := loadBenchmarkData(b)
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		items, err := ParseReadingsFrom(context.TODO(), bytes.NewReader(data))
+
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for item := range items {
+			if item.Error() {
+				b.Fatal(item.E)
+			}
+		}
+	}
+}
+
+func BenchmarkReadingsDecoder(b *testing.B) {
+	data := loadBenchmarkData(b)
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	batch := make([]Readings, DefaultBatchSize)
+
+	for i := 0; i < b.N; i++ {
+		d := NewReadingsDecoder(bytes.NewReader(data))
+
+		for {
+			_, err := d.Decode(batch)
+
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkParseReadingsBatches(b *testing.B) {
+	data := loadBenchmarkData(b)
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		batches, err := ParseReadingsBatches(context.TODO(), bytes.NewReader(data), DefaultBatchSize)
+
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for batch := range batches {
+			if batch.Error() {
+				b.Fatal(batch.E)
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// channel псевдоним типа Channel без собственных методов кодирования
+type channel Channel
+
+// channelJSON представление канала в ответе API Каскада с необработанными значениями перечислений
+type channelJSON struct {
+	*channel
+
+	// Resource тип ресурса
+	Resource json.RawMessage `json:"resourceType,omitempty"`
+
+	// Flow тип подключения
+	Flow json.RawMessage `json:"type,omitempty"`
+}
+
+// UnmarshalJSON реализация интерфейса Unmarshaler для типа Channel.
+//
+// Неизвестные тип ресурса и тип подключения не считаются ошибкой: поля Resource и Flow получают значения
+// ResourceUnknown и FlowUnknown, а исходные значения сохраняются в полях RawResource и RawFlow
+func (c *Channel) UnmarshalJSON(b []byte) error {
+	*c = Channel{}
+
+	v := channelJSON{channel: (*channel)(c)}
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	if s, ok := rawEnum(v.Resource); ok {
+		resource, err := ParseResource(s)
+
+		if err != nil {
+			c.RawResource = s
+		}
+
+		c.Resource = resource
+	}
+
+	if s, ok := rawEnum(v.Flow); ok {
+		flow, err := ParseFlow(s)
+
+		if err != nil {
+			c.RawFlow = s
+		}
+
+		c.Flow = flow
+	}
+
+	return nil
+}
+
+// MarshalJSON реализация интерфейса Marshaler для типа Channel. Неизвестные тип ресурса и тип подключения
+// кодируются исходными значениями из полей RawResource и RawFlow, а при их отсутствии не выводятся
+func (c Channel) MarshalJSON() ([]byte, error) {
+	v := channelJSON{channel: (*channel)(&c)}
+
+	var err error
+
+	if s := c.ResourceValue(); s != "" {
+		if v.Resource, err = json.Marshal(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s := c.FlowValue(); s != "" {
+		if v.Flow, err = json.Marshal(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(v)
+}
+
+// ResourceValue возвращает значение типа ресурса канала, принятое в API Каскада. Для неизвестного типа ресурса
+// возвращается исходное значение из поля RawResource или пустая строка, если оно отсутствует
+func (c *Channel) ResourceValue() string {
+	if c.Resource == ResourceUnknown {
+		return c.RawResource
+	}
+
+	return c.Resource.String()
+}
+
+// FlowValue возвращает значение типа подключения канала, принятое в API Каскада. Для неизвестного типа подключения
+// возвращается исходное значение из поля RawFlow или пустая строка, если оно отсутствует
+func (c *Channel) FlowValue() string {
+	if c.Flow == FlowUnknown {
+		return c.RawFlow
+	}
+
+	return c.Flow.String()
+}
+
+// checkEnums возвращает ошибку, если тип ресурса или тип подключения канала неизвестен
+func (c *Channel) checkEnums() error {
+	if c.RawResource != "" {
+		return fmt.Errorf("channel %d: unknown resource %s", c.ID, c.RawResource)
+	}
+
+	if c.RawFlow != "" {
+		return fmt.Errorf("channel %d: unknown flow %s", c.ID, c.RawFlow)
+	}
+
+	return nil
+}
+
+// validate реализация интерфейса validator для типа Gauge
+func (g *Gauge) validate(options *parseOptions) error {
+	if !options.strictEnums {
+		return nil
+	}
+
+	for _, input := range g.Inputs {
+		for i := range input.Channels {
+			if err := input.Channels[i].checkEnums(); err != nil {
+				return fmt.Errorf("gauge %d, input %d: %v", g.ID, input.Number, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// rawEnum возвращает строковое значение перечисления из JSON. Значения null и отсутствующие значения не
+// возвращаются, значения других типов возвращаются как есть
+func rawEnum(raw json.RawMessage) (string, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", false
+	}
+
+	var s string
+
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return string(raw), true
+	}
+
+	return s, true
+}
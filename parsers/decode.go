@@ -0,0 +1,429 @@
+package parsers
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/guregu/null"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+)
+
+// archives типы архивов, значения которых разбираются без обращения к encoding/json
+var archives = [...]archive.DataArchive{
+	archive.HourArchive,
+	archive.DailyArchive,
+	archive.MonthlyArchive,
+	archive.CurrentArchive,
+	archive.TotalArchive,
+}
+
+// readingsFields наименования полей показания в ответе API Каскада
+var readingsFields = [...]string{
+	"archiveType", "channelId", "channelNum", "createAt", "deviceId", "inputNum", "dt", "id", "isBadRow", "m", "p", "q",
+	"q1", "q2", "t", "tcw", "ti", "v", "isEmpty",
+}
+
+// decodeReadings разбирает элемент архива показаний raw в r.
+//
+// Элементы обычного вида разбираются без рефлексии. Если элемент содержит конструкции, которые быстрый разбор не
+// поддерживает (управляющие последовательности в строках, числа в виде строк, наименования полей в другом регистре
+// и т.п.), или содержит ошибку, элемент разбирается encoding/json, поэтому результат и ошибки разбора не отличаются
+// от json.Unmarshal
+func decodeReadings(raw []byte, r *Readings) error {
+	*r = Readings{}
+
+	d := readingsDecoder{b: raw}
+
+	if d.decode(r) {
+		return nil
+	}
+
+	*r = Readings{}
+
+	return json.Unmarshal(raw, r)
+}
+
+// readingsDecoder быстрый разбор элемента архива показаний. Методы возвращают false, если элемент не может быть
+// разобран без encoding/json
+type readingsDecoder struct {
+	b   []byte
+	pos int
+}
+
+func (d *readingsDecoder) decode(r *Readings) bool {
+	d.skipSpaces()
+
+	if !d.consume('{') {
+		return false
+	}
+
+	d.skipSpaces()
+
+	if !d.consume('}') {
+		for {
+			key, ok := d.simpleString()
+
+			if !ok {
+				return false
+			}
+
+			d.skipSpaces()
+
+			if !d.consume(':') {
+				return false
+			}
+
+			d.skipSpaces()
+
+			if !d.field(key, r) {
+				return false
+			}
+
+			d.skipSpaces()
+
+			if d.consume(',') {
+				d.skipSpaces()
+				continue
+			}
+
+			if d.consume('}') {
+				break
+			}
+
+			return false
+		}
+	}
+
+	d.skipSpaces()
+
+	return d.pos == len(d.b)
+}
+
+// field разбирает значение поля key
+func (d *readingsDecoder) field(key []byte, r *Readings) bool {
+	switch string(key) {
+	case "archiveType":
+		return d.archive(&r.Archive)
+	case "channelId":
+		return d.int(&r.ChannelID)
+	case "channelNum":
+		return d.int(&r.ChannelNum)
+	case "createAt":
+		return d.time(&r.CreateAt)
+	case "deviceId":
+		return d.int(&r.DeviceID)
+	case "inputNum":
+		return d.int(&r.Input)
+	case "dt":
+		return d.time(&r.DT)
+	case "id":
+		return d.int(&r.ID)
+	case "isBadRow":
+		return d.bool(&r.IsBadRow)
+	case "m":
+		return d.float(&r.M)
+	case "p":
+		return d.float(&r.P)
+	case "q":
+		return d.float(&r.Q)
+	case "q1":
+		return d.float(&r.Q1)
+	case "q2":
+		return d.float(&r.Q2)
+	case "t":
+		return d.float(&r.T)
+	case "tcw":
+		return d.float(&r.TCW)
+	case "ti":
+		return d.float(&r.TI)
+	case "v":
+		return d.float(&r.V)
+	case "isEmpty":
+		return d.nullBool(&r.Empty)
+	}
+
+	// encoding/json сопоставляет наименования полей без учета регистра
+	for _, name := range readingsFields {
+		if matchFold(key, name) {
+			return false
+		}
+	}
+
+	return d.skipValue()
+}
+
+func (d *readingsDecoder) int(v *null.Int) bool {
+	if d.literal("null") {
+		v.Valid = false
+		return true
+	}
+
+	number, ok := d.number()
+
+	if !ok {
+		return false
+	}
+
+	n, err := strconv.ParseInt(string(number), 10, 64)
+
+	if err != nil {
+		return false
+	}
+
+	v.Int64, v.Valid = n, true
+
+	return true
+}
+
+func (d *readingsDecoder) float(v *null.Float) bool {
+	if d.literal("null") {
+		v.Valid = false
+		return true
+	}
+
+	number, ok := d.number()
+
+	if !ok {
+		return false
+	}
+
+	f, err := strconv.ParseFloat(string(number), 64)
+
+	if err != nil {
+		return false
+	}
+
+	v.Float64, v.Valid = f, true
+
+	return true
+}
+
+func (d *readingsDecoder) bool(v *bool) bool {
+	switch {
+	case d.literal("true"):
+		*v = true
+	case d.literal("false"):
+		*v = false
+	case d.literal("null"):
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (d *readingsDecoder) nullBool(v *null.Bool) bool {
+	switch {
+	case d.literal("true"):
+		v.Bool, v.Valid = true, true
+	case d.literal("false"):
+		v.Bool, v.Valid = false, true
+	case d.literal("null"):
+		v.Valid = false
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (d *readingsDecoder) time(v *ReadingTime) bool {
+	if d.literal("null") {
+		*v = ReadingTime{}
+		return true
+	}
+
+	s, ok := d.simpleString()
+
+	if !ok {
+		return false
+	}
+
+	t, err := ParseReadingTime(string(s))
+
+	if err != nil {
+		return false
+	}
+
+	*v = t
+
+	return true
+}
+
+func (d *readingsDecoder) archive(v *archive.DataArchive) bool {
+	s, ok := d.simpleString()
+
+	if !ok {
+		return false
+	}
+
+	for _, a := range archives {
+		if string(s) == a.String() {
+			*v = a
+			return true
+		}
+	}
+
+	return false
+}
+
+// skipValue пропускает значение поля, не входящего в показание. Поддерживаются только простые значения
+func (d *readingsDecoder) skipValue() bool {
+	if d.literal("null") || d.literal("true") || d.literal("false") {
+		return true
+	}
+
+	if _, ok := d.number(); ok {
+		return true
+	}
+
+	_, ok := d.simpleString()
+
+	return ok
+}
+
+func (d *readingsDecoder) skipSpaces() {
+	for d.pos < len(d.b) {
+		switch d.b[d.pos] {
+		case ' ', '\t', '\r', '\n':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (d *readingsDecoder) consume(c byte) bool {
+	if d.pos < len(d.b) && d.b[d.pos] == c {
+		d.pos++
+		return true
+	}
+
+	return false
+}
+
+// literal разбирает литерал JSON s. Окончание литерала проверяется разбором следующего за ним символа
+func (d *readingsDecoder) literal(s string) bool {
+	if len(d.b)-d.pos >= len(s) && string(d.b[d.pos:d.pos+len(s)]) == s {
+		d.pos += len(s)
+		return true
+	}
+
+	return false
+}
+
+// simpleString разбирает строку из печатных символов ASCII без управляющих последовательностей и возвращает ее
+// содержимое
+func (d *readingsDecoder) simpleString() ([]byte, bool) {
+	if !d.consume('"') {
+		return nil, false
+	}
+
+	start := d.pos
+
+	for ; d.pos < len(d.b); d.pos++ {
+		switch c := d.b[d.pos]; {
+		case c == '"':
+			d.pos++
+			return d.b[start : d.pos-1], true
+
+		case c < 0x20 || c == '\\' || c >= 0x80:
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
+
+// number разбирает число по грамматике JSON: -?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?. Если число
+// некорректно, позиция разбора не изменяется
+func (d *readingsDecoder) number() ([]byte, bool) {
+	start := d.pos
+
+	if d.scanNumber() {
+		return d.b[start:d.pos], true
+	}
+
+	d.pos = start
+
+	return nil, false
+}
+
+func (d *readingsDecoder) scanNumber() bool {
+	d.consume('-')
+
+	switch {
+	case d.consume('0'):
+	case d.digit():
+		d.digits()
+	default:
+		return false
+	}
+
+	if d.consume('.') {
+		if !d.digit() {
+			return false
+		}
+
+		d.digits()
+	}
+
+	if d.consume('e') || d.consume('E') {
+		if !d.consume('+') {
+			d.consume('-')
+		}
+
+		if !d.digit() {
+			return false
+		}
+
+		d.digits()
+	}
+
+	return true
+}
+
+func (d *readingsDecoder) digit() bool {
+	return d.pos < len(d.b) && d.b[d.pos] >= '0' && d.b[d.pos] <= '9'
+}
+
+func (d *readingsDecoder) digits() {
+	for d.digit() {
+		d.pos++
+	}
+}
+
+// matchFold сравнивает наименование поля key с name без учета регистра символов ASCII, пропуская в key символы
+// '_' и '-'. Наименования с другими символами не совпадают
+func matchFold(key []byte, name string) bool {
+	var i int
+
+	for _, c := range key {
+		if c == '_' || c == '-' {
+			continue
+		}
+
+		if i == len(name) {
+			return false
+		}
+
+		t := name[i]
+
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+
+		if 'A' <= t && t <= 'Z' {
+			t += 'a' - 'A'
+		}
+
+		if c != t {
+			return false
+		}
+
+		i++
+	}
+
+	return i == len(name)
+}
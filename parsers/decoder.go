@@ -0,0 +1,163 @@
+package parsers
+
+import (
+	"context"
+	"io"
+)
+
+// DefaultBatchSize размер пакета показаний по умолчанию
+const DefaultBatchSize = 256
+
+// ReadingsDecoder разбирает ответ метода /api/cascade/counter-house/reading, читая его из потока, и возвращает
+// показания пакетами в буфер, предоставленный вызывающей стороной. В отличие от ParseReadingsFrom, не создает
+// горутину и не выделяет память под каждое показание
+type ReadingsDecoder struct {
+	scanner *elementScanner
+	options *parseOptions
+	index   int
+	begun   bool
+	err     error
+}
+
+// NewReadingsDecoder возвращает ReadingsDecoder, читающий ответ из r
+func NewReadingsDecoder(r io.Reader, options ...ParseOption) *ReadingsDecoder {
+	opts := &parseOptions{}
+
+	for _, option := range options {
+		option(opts)
+	}
+
+	return &ReadingsDecoder{
+		scanner: newElementScanner(r),
+		options: opts,
+	}
+}
+
+// Decode разбирает очередные показания в batch и возвращает их количество. Разбор прекращается при заполнении
+// batch, по окончании архива или на ошибке разбора элемента, поэтому показания batch[:n] следует обработать до
+// проверки ошибки. По окончании архива возвращается 0 и io.EOF.
+//
+// Ошибки разбора возвращаются в виде *ParseError. В нестрогом режиме после ошибки разбора элемента следующий вызов
+// продолжает разбор со следующего элемента. Ошибки чтения, преждевременный конец потока и ошибки в строгом режиме
+// возвращаются и при всех последующих вызовах
+func (d *ReadingsDecoder) Decode(batch []Readings) (int, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	if !d.begun {
+		if err := d.begin(); err != nil {
+			return 0, err
+		}
+	}
+
+	var n int
+
+	for n < len(batch) {
+		raw, start, err := d.scanner.next()
+
+		if err == io.EOF {
+			if n == 0 {
+				d.err = io.EOF
+				return 0, io.EOF
+			}
+
+			return n, nil
+		}
+
+		if err != nil {
+			d.err = &ParseError{Index: d.index, Offset: d.scanner.offset, Err: err}
+			return n, d.err
+		}
+
+		index := d.index
+		d.index++
+
+		if err = decodeReadings(raw, &batch[n]); err != nil {
+			perr := newElementError(index, start, err)
+
+			if d.options.strict {
+				d.err = perr
+			}
+
+			return n, perr
+		}
+
+		n++
+	}
+
+	return n, nil
+}
+
+// begin читает начало массива
+func (d *ReadingsDecoder) begin() error {
+	d.begun = true
+
+	if err := d.scanner.begin(); err != nil {
+		d.err = err
+		return err
+	}
+
+	return nil
+}
+
+// Batch пакет показаний, полученный от ParseReadingsBatches
+type Batch struct {
+	// Readings показания пакета. Срез передается получателю и далее не используется
+	Readings []Readings
+
+	// E ошибка разбора элемента архива, следующего за показаниями пакета
+	E error
+}
+
+// Error возвращает признак ошибки разбора
+func (b Batch) Error() bool {
+	return b.E != nil
+}
+
+// ParseReadingsBatches разбирает ответ метода /api/cascade/counter-house/reading, читая его из r, и передает
+// показания пакетами не более size показаний (DefaultBatchSize, если size не положителен). Результаты разбора
+// совпадают с результатами ParseReadingsFrom, но передаются по каналу пакетами, а не по одному.
+//
+// Ошибка разбора элемента передается в поле E пакета вместе с показаниями, разобранными до нее. Горутина разбора
+// завершается при отмене ctx, даже если результаты никто не читает
+func ParseReadingsBatches(ctx context.Context, r io.Reader, size int, options ...ParseOption) (<-chan Batch, error) {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+
+	d := NewReadingsDecoder(r, options...)
+
+	if err := d.begin(); err != nil {
+		return nil, err
+	}
+
+	out := make(chan Batch)
+
+	go func(d *ReadingsDecoder) {
+		defer close(out)
+
+		for ctx.Err() == nil {
+			batch := make([]Readings, size)
+
+			n, err := d.Decode(batch)
+
+			if err == io.EOF {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+
+			case out <- Batch{Readings: batch[:n:n], E: err}:
+			}
+
+			if err != nil && d.err != nil {
+				return
+			}
+		}
+	}(d)
+
+	return out, nil
+}
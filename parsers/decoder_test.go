@@ -0,0 +1,126 @@
+package parsers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadingsDecoder(t *testing.T) {
+	path, err := filepath.Abs("../testdata/responses/readings200.json")
+
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+
+	require.NoError(t, err)
+
+	var want []Readings
+
+	err = json.Unmarshal(data, &want)
+
+	require.NoError(t, err)
+
+	d := NewReadingsDecoder(bytes.NewReader(data), WithStrict())
+
+	batch := make([]Readings, 100)
+	got := make([]Readings, 0, len(want))
+
+	for {
+		n, err := d.Decode(batch)
+
+		got = append(got, batch[:n]...)
+
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+	}
+
+	assert.Len(t, got, 507)
+	assert.Equal(t, want, got)
+
+	batches, err := ParseReadingsBatches(context.TODO(), bytes.NewReader(data), 200)
+
+	require.NoError(t, err)
+
+	got = got[:0]
+
+	for b := range batches {
+		require.NoError(t, b.E)
+		assert.LessOrEqual(t, len(b.Readings), 200)
+
+		got = append(got, b.Readings...)
+	}
+
+	assert.Equal(t, want, got)
+}
+
+func TestReadingsDecoder_Errors(t *testing.T) {
+	const data = `[{"id": 1}, {"id": "three"}, {"id": 3, "m": 1.5}]`
+
+	d := NewReadingsDecoder(bytes.NewReader([]byte(data)))
+
+	batch := make([]Readings, 10)
+
+	n, err := d.Decode(batch)
+
+	var parseError *ParseError
+
+	require.True(t, errors.As(err, &parseError), err)
+	assert.Equal(t, 1, parseError.Index)
+	require.Equal(t, 1, n)
+	assert.Equal(t, int64(1), batch[0].ID.ValueOrZero())
+
+	n, err = d.Decode(batch)
+
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	assert.Equal(t, 1.5, batch[0].M.ValueOrZero())
+
+	_, err = d.Decode(batch)
+
+	assert.Equal(t, io.EOF, err)
+
+	d = NewReadingsDecoder(bytes.NewReader([]byte(data)), WithStrict())
+
+	_, err = d.Decode(batch)
+
+	assert.Error(t, err)
+
+	n, err2 := d.Decode(batch)
+
+	assert.Equal(t, 0, n)
+	assert.Equal(t, err, err2)
+
+	batches, err := ParseReadingsBatches(context.TODO(), bytes.NewReader([]byte(`[{"id": 1}, {"id": 2, "m": 1`)), 1)
+
+	require.NoError(t, err)
+
+	var ids, errs int
+
+	for b := range batches {
+		ids += len(b.Readings)
+
+		if b.Error() {
+			assert.ErrorIs(t, b.E, io.ErrUnexpectedEOF)
+			errs++
+		}
+	}
+
+	assert.Equal(t, 1, ids)
+	assert.Equal(t, 1, errs)
+
+	_, err = ParseReadingsBatches(context.TODO(), bytes.NewReader([]byte(`{}`)), 0)
+
+	assert.Error(t, err)
+}
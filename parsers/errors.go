@@ -0,0 +1,57 @@
+package parsers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ParseError ошибка разбора элемента списка
+type ParseError struct {
+	// Index порядковый номер элемента списка, начиная с 0. Для ошибок вне элементов списка (например, если ответ не
+	// является массивом) имеет значение -1
+	Index int
+
+	// Offset смещение в байтах от начала ответа, на котором обнаружена ошибка
+	Offset int64
+
+	// Err причина ошибки
+	Err error
+
+	// skippable разбор продолжен после ошибки со следующего элемента списка
+	skippable bool
+}
+
+// Error реализация интерфейса error
+func (e *ParseError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("offset %d: %v", e.Offset, e.Err)
+	}
+
+	return fmt.Sprintf("element %d at offset %d: %v", e.Index, e.Offset, e.Err)
+}
+
+// Unwrap возвращает причину ошибки
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// newElementError возвращает ошибку разбора элемента index, начинающегося со смещения start
+func newElementError(index int, start int64, err error) *ParseError {
+	offset := start
+
+	var (
+		syntaxError *json.SyntaxError
+		typeError   *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &syntaxError):
+		offset += syntaxError.Offset
+
+	case errors.As(err, &typeError):
+		offset += typeError.Offset
+	}
+
+	return &ParseError{Index: index, Offset: offset, Err: err}
+}
@@ -1,8 +1,10 @@
 package parsers
 
 import (
+	"encoding"
 	"fmt"
-	"strings"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/internal/jsontext"
 )
 
 // Flow тип подключения
@@ -17,6 +19,12 @@ const (
 
 	// FlowReverse обратное подключение
 	FlowReverse
+
+	// FlowCirculation циркуляционный трубопровод
+	FlowCirculation
+
+	// FlowMakeUp трубопровод подпитки
+	FlowMakeUp
 )
 
 const (
@@ -25,23 +33,85 @@ const (
 
 	// outFlow тип подключения - обратное
 	outFlow = "outFlow"
+
+	// circulationFlow тип подключения - циркуляция
+	circulationFlow = "circulationFlow"
+
+	// makeUpFlow тип подключения - подпитка
+	makeUpFlow = "makeUpFlow"
 )
 
+var (
+	_ encoding.TextMarshaler   = Flow(0)
+	_ encoding.TextUnmarshaler = (*Flow)(nil)
+)
+
+// String возвращает строковое представление типа подключения, принятое в API Каскада
+func (f Flow) String() string {
+	switch f {
+	case FlowDirect:
+		return inFlow
+
+	case FlowReverse:
+		return outFlow
+
+	case FlowCirculation:
+		return circulationFlow
+
+	case FlowMakeUp:
+		return makeUpFlow
+
+	default:
+		return unknown
+	}
+}
+
 // UnmarshalJSON реализация интерфейса Unmarshaler для типа Flow
 func (f *Flow) UnmarshalJSON(b []byte) (err error) {
-	s := strings.Trim(string(b), `"`)
+	s, err := jsontext.Unquote(b)
+
+	if err != nil {
+		return err
+	}
 
+	*f, err = ParseFlow(s)
+
+	return
+}
+
+// MarshalJSON реализация интерфейса Marshaler для типа Flow
+func (f Flow) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"%s"`, f.String())), nil
+}
+
+// UnmarshalText реализация интерфейса encoding.TextUnmarshaler для типа Flow
+func (f *Flow) UnmarshalText(text []byte) (err error) {
+	*f, err = ParseFlow(string(text))
+
+	return
+}
+
+// MarshalText реализация интерфейса encoding.TextMarshaler для типа Flow
+func (f Flow) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// ParseFlow преобразование строки в значение Flow
+func ParseFlow(s string) (Flow, error) {
 	switch s {
 	case inFlow:
-		*f = FlowDirect
+		return FlowDirect, nil
 
 	case outFlow:
-		*f = FlowReverse
+		return FlowReverse, nil
+
+	case circulationFlow:
+		return FlowCirculation, nil
+
+	case makeUpFlow:
+		return FlowMakeUp, nil
 
 	default:
-		*f = FlowUnknown
-		err = fmt.Errorf("unknown flow %s", s)
+		return FlowUnknown, fmt.Errorf("unknown flow %s", s)
 	}
-
-	return
 }
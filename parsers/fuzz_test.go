@@ -0,0 +1,332 @@
+package parsers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// loadSeed возвращает содержимое файла ответа из testdata/responses
+func loadSeed(f *testing.F, name string) []byte {
+	path, err := filepath.Abs(filepath.Join("../testdata/responses", name))
+
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	return data
+}
+
+// seedValues возвращает исходные JSON представления различных значений полей keys из файлов ответов names
+func seedValues(f *testing.F, keys []string, names ...string) [][]byte {
+	found := make(map[string]bool)
+
+	var walk func(v interface{})
+
+	walk = func(v interface{}) {
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			for key, value := range vv {
+				for _, k := range keys {
+					if key != k {
+						continue
+					}
+
+					if b, err := json.Marshal(value); err == nil {
+						found[string(b)] = true
+					}
+				}
+
+				walk(value)
+			}
+
+		case []interface{}:
+			for _, value := range vv {
+				walk(value)
+			}
+		}
+	}
+
+	for _, name := range names {
+		var v interface{}
+
+		if err := json.Unmarshal(loadSeed(f, name), &v); err != nil {
+			f.Fatal(err)
+		}
+
+		walk(v)
+	}
+
+	values := make([]string, 0, len(found))
+
+	for value := range found {
+		values = append(values, value)
+	}
+
+	sort.Strings(values)
+
+	seeds := make([][]byte, len(values))
+
+	for i, value := range values {
+		seeds[i] = []byte(value)
+	}
+
+	return seeds
+}
+
+// checkJSONString проверяет, что результат разбора JSON представления data совпадает с результатом разбора parse
+// строки, полученной декодированием data стандартной библиотекой
+func checkJSONString(t *testing.T, data []byte, err error, parse func(s string) error) {
+	var s string
+
+	if json.Unmarshal(data, &s) != nil {
+		if err == nil {
+			t.Fatalf("invalid JSON string %q accepted", data)
+		}
+
+		return
+	}
+
+	if want := parse(s); (err == nil) != (want == nil) {
+		t.Fatalf("%q: got error %v, want %v", data, err, want)
+	}
+}
+
+func FuzzReadingTime(f *testing.F) {
+	for _, seed := range seedValues(f, []string{"dt", "createAt"}, "readings200.json") {
+		f.Add(seed)
+	}
+
+	f.Add([]byte(`null`))
+	f.Add([]byte(`""`))
+	f.Add([]byte(`"2021-04-11T01:00:00.1234"`))
+	f.Add([]byte(`"2021-04-11T01:00:00.000"`))
+	f.Add([]byte(`"`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var rt ReadingTime
+
+		err := rt.UnmarshalJSON(data)
+
+		checkJSONString(t, data, err, func(s string) error {
+			_, err := ParseReadingTime(s)
+			return err
+		})
+
+		if err != nil {
+			return
+		}
+
+		b, err := rt.MarshalJSON()
+
+		if err != nil {
+			t.Fatalf("marshal %v: %v", rt, err)
+		}
+
+		var again ReadingTime
+
+		if err = json.Unmarshal(b, &again); err != nil {
+			t.Fatalf("unmarshal %s: %v", b, err)
+		}
+
+		if !again.Time().Equal(rt.Time().Truncate(time.Millisecond)) {
+			t.Fatalf("round trip %s: got %v, want %v", data, again, rt)
+		}
+
+		text, err := rt.MarshalText()
+
+		if err != nil {
+			t.Fatalf("marshal text %v: %v", rt, err)
+		}
+
+		if err = again.UnmarshalText(text); err != nil {
+			t.Fatalf("unmarshal text %s: %v", text, err)
+		}
+	})
+}
+
+func FuzzResource(f *testing.F) {
+	for _, seed := range seedValues(f, []string{"resourceType"}, "counterHouse.json") {
+		f.Add(seed)
+	}
+
+	f.Add([]byte(`null`))
+	f.Add([]byte(`"Heat"`))
+	f.Add([]byte(`1`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var r Resource
+
+		err := r.UnmarshalJSON(data)
+
+		checkJSONString(t, data, err, func(s string) error {
+			_, err := ParseResource(s)
+			return err
+		})
+
+		if err != nil {
+			return
+		}
+
+		b, err := r.MarshalJSON()
+
+		if err != nil {
+			t.Fatalf("marshal %v: %v", r, err)
+		}
+
+		var again Resource
+
+		if err = json.Unmarshal(b, &again); err != nil || again != r {
+			t.Fatalf("round trip %s: got %v (%v), want %v", data, again, err, r)
+		}
+	})
+}
+
+func FuzzFlow(f *testing.F) {
+	for _, seed := range seedValues(f, []string{"type"}, "counterHouse.json") {
+		f.Add(seed)
+	}
+
+	f.Add([]byte(`null`))
+	f.Add([]byte(`"inFlow"`))
+	f.Add([]byte(`""`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var fl Flow
+
+		err := fl.UnmarshalJSON(data)
+
+		checkJSONString(t, data, err, func(s string) error {
+			_, err := ParseFlow(s)
+			return err
+		})
+
+		if err != nil {
+			return
+		}
+
+		b, err := fl.MarshalJSON()
+
+		if err != nil {
+			t.Fatalf("marshal %v: %v", fl, err)
+		}
+
+		var again Flow
+
+		if err = json.Unmarshal(b, &again); err != nil || again != fl {
+			t.Fatalf("round trip %s: got %v (%v), want %v", data, again, err, fl)
+		}
+	})
+}
+
+// fuzzParse проверяет, что разбор data завершается, а каждый элемент результата содержит либо значение, либо ошибку
+func fuzzParse(t *testing.T, data []byte, parse func(ctx context.Context, b []byte,
+	options ...ParseOption) (<-chan Item, error)) {
+	for _, options := range [][]ParseOption{nil, {WithStrict(), WithStrictEnums()}} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+
+		items, err := parse(ctx, data, options...)
+
+		if err != nil {
+			cancel()
+			continue
+		}
+
+		for item := range items {
+			if (item.V == nil) == (item.E == nil) {
+				t.Fatalf("item has both value and error or neither: %+v", item)
+			}
+		}
+
+		if ctx.Err() != nil {
+			t.Fatalf("parsing of %d bytes did not finish", len(data))
+		}
+
+		cancel()
+	}
+}
+
+func FuzzParseGaugesList(f *testing.F) {
+	f.Add(loadSeed(f, "counterHouse.json"))
+	f.Add([]byte(`[{"id": 1, "inputs": [{"number": 1, "channels": [{"id": 2, "resourceType": 3}]}]}, 1, ]`))
+	f.Add([]byte(`[{"id": "x"}, {"inputs": [{"channels": "]"}]}]`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fuzzParse(t, data, ParseGaugesList)
+	})
+}
+
+func FuzzParseReadings(f *testing.F) {
+	f.Add(loadSeed(f, "readings200.json"))
+	f.Add(loadSeed(f, "readings422.json"))
+	f.Add([]byte(`[{"id": 1, "dt": "2021-04-11T01:00:00.000"}, {"id": "x"}, {"dt": null}]`))
+	f.Add([]byte(`[{"id": 1,`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fuzzParse(t, data, ParseReadings)
+	})
+}
+
+func FuzzDecodeReadings(f *testing.F) {
+	scanner := newElementScanner(bytes.NewReader(loadSeed(f, "readings200.json")))
+
+	if err := scanner.begin(); err != nil {
+		f.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		raw, _, err := scanner.next()
+
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(bytes.Clone(raw))
+	}
+
+	f.Add([]byte(`{"id": "14042944", "DT": "2021-04-11T01:00:00.000", "m": 1e400}`))
+	f.Add([]byte(`{"id": 1.0, "device_id": 2, "comment": "A", "isEmpty": null, "isBadRow": null}`))
+	f.Add([]byte(`{"id": 1, "id": null, "archiveType": null}`))
+	f.Add([]byte(`{"dt": "null", "createAt": "", "p": -0.5E+2, "x": [1]} `))
+	f.Add([]byte(`null`))
+	f.Add([]byte(`{"id": 1,}`))
+	f.Add([]byte(`{"x": -"a", "p": 01}`))
+
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		var want Readings
+
+		wantErr := json.Unmarshal(raw, &want)
+
+		var got Readings
+
+		err := decodeReadings(raw, &got)
+
+		if (err == nil) != (wantErr == nil) {
+			t.Fatalf("%q: got error %v, want %v", raw, err, wantErr)
+		}
+
+		if err != nil {
+			if err.Error() != wantErr.Error() {
+				t.Fatalf("%q: got error %v, want %v", raw, err, wantErr)
+			}
+
+			return
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%q: got %+v, want %+v", raw, got, want)
+		}
+	})
+}
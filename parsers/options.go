@@ -0,0 +1,63 @@
+package parsers
+
+type parseOptions struct {
+	strict      bool
+	strictEnums bool
+}
+
+// ParseOption опция разбора ответа метода API Каскада
+type ParseOption func(options *parseOptions)
+
+// WithStrict включает строгий режим разбора: разбор прекращается после первой ошибки.
+//
+// По умолчанию разбор ведется в нестрогом режиме: для некорректного элемента списка возвращается ошибка, после чего
+// разбор продолжается со следующего элемента
+func WithStrict() ParseOption {
+	return func(options *parseOptions) {
+		options.strict = true
+	}
+}
+
+// WithStrictEnums включает строгий разбор перечислений: прибор учета, у канала которого указан неизвестный тип
+// ресурса или тип подключения, считается ошибкой разбора.
+//
+// По умолчанию неизвестные значения сохраняются в полях Channel.RawResource и Channel.RawFlow
+func WithStrictEnums() ParseOption {
+	return func(options *parseOptions) {
+		options.strictEnums = true
+	}
+}
+
+type eachOptions struct {
+	skipped func(err *ParseError)
+	skips   bool
+}
+
+// skip возвращает true, если ошибка разбора err пропускается
+func (options *eachOptions) skip(err error) bool {
+	pe, ok := err.(*ParseError)
+
+	if !options.skips || !ok || !pe.skippable {
+		return false
+	}
+
+	if options.skipped != nil {
+		options.skipped(pe)
+	}
+
+	return true
+}
+
+// EachOption опция обработки элементов списка функцией Each
+type EachOption func(options *eachOptions)
+
+// WithSkip включает пропуск элементов списка с ошибкой разбора, после которой разбор продолжается со следующего
+// элемента (нестрогий режим разбора). Пропущенные ошибки передаются функции skipped, если она задана.
+//
+// Ошибки чтения ответа и ошибки разбора в строгом режиме обработку по-прежнему прекращают
+func WithSkip(skipped func(err *ParseError)) EachOption {
+	return func(options *eachOptions) {
+		options.skips = true
+		options.skipped = skipped
+	}
+}
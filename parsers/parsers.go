@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 )
 
 // Item элемент списка приборов учета/записей архива показаний
@@ -19,88 +21,188 @@ func (i Item) Error() bool {
 	return i.E != nil
 }
 
-func of(x interface{}) Item {
-	return Item{V: x}
-}
+// Each передает функции fn значения элементов items по мере их разбора и возвращает количество переданных
+// значений. Обработка прекращается на первой ошибке разбора, ошибке fn или при отмене ctx. Оставшиеся элементы
+// items при этом прочитываются без обработки, поэтому горутина разбора завершается и без отмены ctx.
+//
+// С опцией WithSkip ошибки разбора отдельных элементов, после которых разбор продолжается, пропускаются
+func Each(ctx context.Context, items <-chan Item, fn func(v interface{}) error, options ...EachOption) (int, error) {
+	opts := &eachOptions{}
 
-func e(err error) Item {
-	return Item{E: err}
-}
+	for _, option := range options {
+		option(opts)
+	}
 
-// ParseGaugesList разбирает ответ метода /api/cascade/counter-house
-func ParseGaugesList(ctx context.Context, b []byte) (<-chan Item, error) {
-	decoder := json.NewDecoder(bytes.NewReader(b))
+	var (
+		count int
+		err   error
+	)
 
-	_, err := decoder.Token()
+	for item := range items {
+		if err != nil {
+			continue
+		}
 
-	if err != nil {
-		return nil, err
+		switch {
+		case ctx.Err() != nil:
+			err = ctx.Err()
+		case item.Error():
+			if !opts.skip(item.E) {
+				err = item.E
+			}
+		default:
+			if err = fn(item.V); err == nil {
+				count++
+			}
+		}
 	}
 
-	out := make(chan Item)
+	if err == nil {
+		err = ctx.Err()
+	}
 
-	go func(decoder *json.Decoder, b []byte) {
-		defer close(out)
+	return count, err
+}
 
-		for {
-			select {
-			case <-ctx.Done():
-				return
+// EachGauge передает функции fn приборы учета, полученные от ParseGaugesList или ParseGaugesListFrom (см. Each)
+func EachGauge(ctx context.Context, items <-chan Item, fn func(gauge *Gauge) error,
+	options ...EachOption) (int, error) {
+	return Each(ctx, items, func(v interface{}) error {
+		gauge, ok := v.(*Gauge)
 
-			default:
-				if !decoder.More() {
-					return
-				}
+		if !ok {
+			return fmt.Errorf("unexpected item %T", v)
+		}
 
-				var counterHouse Gauge
+		return fn(gauge)
+	}, options...)
+}
 
-				if err := decoder.Decode(&counterHouse); err != nil {
-					out <- e(err)
-				} else {
-					out <- of(&counterHouse)
-				}
-			}
+// EachReadings передает функции fn показания, полученные от ParseReadings или ParseReadingsFrom (см. Each)
+func EachReadings(ctx context.Context, items <-chan Item, fn func(r *Readings) error,
+	options ...EachOption) (int, error) {
+	return Each(ctx, items, func(v interface{}) error {
+		r, ok := v.(*Readings)
+
+		if !ok {
+			return fmt.Errorf("unexpected item %T", v)
 		}
-	}(decoder, b)
 
-	return out, nil
+		return fn(r)
+	}, options...)
+}
+
+func of(x interface{}) Item {
+	return Item{V: x}
+}
+
+func e(err error) Item {
+	return Item{E: err}
+}
+
+// validator проверка разобранного элемента списка с учетом опций разбора
+type validator interface {
+	validate(options *parseOptions) error
+}
+
+// ParseGaugesList разбирает ответ метода /api/cascade/counter-house
+func ParseGaugesList(ctx context.Context, b []byte, options ...ParseOption) (<-chan Item, error) {
+	return ParseGaugesListFrom(ctx, bytes.NewReader(b), options...)
+}
+
+// ParseGaugesListFrom разбирает ответ метода /api/cascade/counter-house, читая его из r по мере разбора элементов
+// списка. Весь ответ в память не загружается
+func ParseGaugesListFrom(ctx context.Context, r io.Reader, options ...ParseOption) (<-chan Item, error) {
+	return parse(ctx, r, func() interface{} {
+		return &Gauge{}
+	}, options...)
 }
 
 // ParseReadings разбирает ответ метода /api/cascade/counter-house/readings
-func ParseReadings(ctx context.Context, b []byte) (<-chan Item, error) {
-	decoder := json.NewDecoder(bytes.NewReader(b))
+func ParseReadings(ctx context.Context, b []byte, options ...ParseOption) (<-chan Item, error) {
+	return ParseReadingsFrom(ctx, bytes.NewReader(b), options...)
+}
+
+// ParseReadingsFrom разбирает ответ метода /api/cascade/counter-house/readings, читая его из r по мере разбора
+// элементов архива. Весь ответ в память не загружается
+func ParseReadingsFrom(ctx context.Context, r io.Reader, options ...ParseOption) (<-chan Item, error) {
+	return parse(ctx, r, func() interface{} {
+		return &Readings{}
+	}, options...)
+}
+
+// parse разбирает JSON массив из r, создавая для каждого элемента массива значение с помощью newValue.
+//
+// Ошибки разбора возвращаются в виде *ParseError. Ошибка чтения или преждевременный конец потока завершают разбор в
+// любом режиме. Горутина разбора завершается при отмене ctx, даже если результаты никто не читает
+func parse(ctx context.Context, r io.Reader, newValue func() interface{}, options ...ParseOption) (<-chan Item, error) {
+	opts := &parseOptions{}
 
-	_, err := decoder.Token()
+	for _, option := range options {
+		option(opts)
+	}
+
+	scanner := newElementScanner(r)
 
-	if err != nil {
+	if err := scanner.begin(); err != nil {
 		return nil, err
 	}
 
 	out := make(chan Item)
 
-	go func(decoder *json.Decoder, b []byte) {
+	send := func(item Item) bool {
+		select {
+		case <-ctx.Done():
+			return false
+
+		case out <- item:
+			return true
+		}
+	}
+
+	go func(scanner *elementScanner) {
 		defer close(out)
 
-		for {
-			select {
-			case <-ctx.Done():
+		for index := 0; ctx.Err() == nil; index++ {
+			raw, start, err := scanner.next()
+
+			if err == io.EOF {
+				return
+			}
+
+			if err != nil {
+				send(e(&ParseError{Index: index, Offset: scanner.offset, Err: err}))
 				return
+			}
+
+			v := newValue()
 
-			default:
-				if !decoder.More() {
+			if r, ok := v.(*Readings); ok {
+				err = decodeReadings(raw, r)
+			} else {
+				err = json.Unmarshal(raw, v)
+			}
+
+			if vv, ok := v.(validator); ok && err == nil {
+				err = vv.validate(opts)
+			}
+
+			if err != nil {
+				pe := newElementError(index, start, err)
+				pe.skippable = !opts.strict
+
+				if !send(e(pe)) || opts.strict {
 					return
 				}
 
-				var reading Readings
+				continue
+			}
 
-				if err := decoder.Decode(&reading); err != nil {
-					out <- e(err)
-				} else {
-					out <- of(&reading)
-				}
+			if !send(of(v)) {
+				return
 			}
 		}
-	}(decoder, b)
+	}(scanner)
 
 	return out, nil
 }
@@ -1,10 +1,18 @@
 package parsers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -89,3 +97,520 @@ func TestParseReadings(t *testing.T) {
 		}
 	}
 }
+
+func TestGaugesRoundTrip(t *testing.T) {
+	path, err := filepath.Abs("../testdata/responses/counterHouse.json")
+
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path)
+
+	require.NoError(t, err)
+
+	items, err := ParseGaugesList(context.TODO(), data)
+
+	require.NoError(t, err)
+
+	gauges := make([]*Gauge, 0)
+
+	for item := range items {
+		require.NoError(t, item.E, path)
+
+		gauges = append(gauges, item.V.(*Gauge))
+	}
+
+	b, err := json.Marshal(gauges)
+
+	require.NoError(t, err)
+
+	assertRoundTrip(t, data, b)
+
+	b, err = json.Marshal(gauges[0].Inputs[0].Channels[3:])
+
+	require.NoError(t, err)
+
+	assert.Equal(t, `[{"id":9249,"number":4,"resourceType":"HotWater","type":"outFlow"},`+
+		`{"id":16201,"number":0,"resourceType":"None"}]`, string(b))
+}
+
+func TestReadingsRoundTrip(t *testing.T) {
+	path, err := filepath.Abs("../testdata/responses/readings200.json")
+
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path)
+
+	require.NoError(t, err)
+
+	items, err := ParseReadings(context.TODO(), data)
+
+	require.NoError(t, err)
+
+	readings := make([]*Readings, 0)
+
+	for item := range items {
+		require.NoError(t, item.E, path)
+
+		readings = append(readings, item.V.(*Readings))
+	}
+
+	b, err := json.Marshal(readings)
+
+	require.NoError(t, err)
+
+	assertRoundTrip(t, data, b)
+
+	b, err = json.Marshal(readings[0])
+
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"archiveType":"Hour","channelId":19265,"channelNum":1,"createAt":"2021-04-13T06:15:53.000",`+
+		`"deviceId":12032,"inputNum":1,"dt":"2021-04-11T01:00:00.000","id":14042944,"isBadRow":false,`+
+		`"m":2.2514917850494385,"p":7,"q":null,"q1":null,"q2":null,"t":67.08999633789062,"tcw":0,"ti":1,`+
+		`"v":2.2979884147644043,"isEmpty":null}`, string(b))
+}
+
+func TestParseEnums(t *testing.T) {
+	for _, r := range []Resource{ResourceHeat, ResourceHotWater, ResourceNone} {
+		text, err := r.MarshalText()
+
+		require.NoError(t, err)
+
+		var parsed Resource
+
+		err = parsed.UnmarshalText(text)
+
+		require.NoError(t, err)
+		assert.Equal(t, r, parsed)
+	}
+
+	for _, f := range []Flow{FlowDirect, FlowReverse} {
+		text, err := f.MarshalText()
+
+		require.NoError(t, err)
+
+		var parsed Flow
+
+		err = parsed.UnmarshalText(text)
+
+		require.NoError(t, err)
+		assert.Equal(t, f, parsed)
+	}
+
+	_, err := ParseResource("Steam2")
+
+	assert.Error(t, err)
+
+	_, err = ParseFlow("sideFlow")
+
+	assert.Error(t, err)
+}
+
+func TestReadingTime(t *testing.T) {
+	rt, err := ParseReadingTime("2021-04-11T01:00:00.000")
+
+	require.NoError(t, err)
+	assert.Equal(t, "2021-04-11T01:00:00.000", rt.String())
+
+	b, err := json.Marshal(rt)
+
+	require.NoError(t, err)
+	assert.Equal(t, `"2021-04-11T01:00:00.000"`, string(b))
+
+	rt, err = ParseReadingTime("2021-04-11T01:00:00")
+
+	require.NoError(t, err)
+	assert.Equal(t, "2021-04-11T01:00:00.000", rt.String())
+
+	var zero ReadingTime
+
+	b, err = json.Marshal(zero)
+
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(b))
+
+	err = json.Unmarshal(b, &rt)
+
+	require.NoError(t, err)
+	assert.True(t, rt.IsZero())
+}
+
+// decodeJSON возвращает универсальное представление JSON
+func decodeJSON(t *testing.T, data []byte) interface{} {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+
+	err := decoder.Decode(&v)
+
+	require.NoError(t, err)
+
+	return v
+}
+
+// assertRoundTrip проверяет, что JSON got, полученный маршализацией разобранного ответа want, совпадает с ответом.
+// Поля со значением null в ответе должны присутствовать в got со значением null. Поля, отсутствующие в ответе,
+// допускаются в got только со значением null
+func assertRoundTrip(t *testing.T, want, got []byte) {
+	var compare func(path string, want, got interface{})
+
+	compare = func(path string, want, got interface{}) {
+		wantObject, ok := want.(map[string]interface{})
+
+		if !ok {
+			wantArray, ok := want.([]interface{})
+
+			if !ok {
+				assert.Equal(t, want, got, path)
+				return
+			}
+
+			gotArray, ok := got.([]interface{})
+
+			if !assert.True(t, ok, path) || !assert.Len(t, gotArray, len(wantArray), path) {
+				return
+			}
+
+			for i := range wantArray {
+				compare(fmt.Sprintf("%s[%d]", path, i), wantArray[i], gotArray[i])
+			}
+
+			return
+		}
+
+		gotObject, ok := got.(map[string]interface{})
+
+		if !assert.True(t, ok, path) {
+			return
+		}
+
+		for key, value := range wantObject {
+			gotValue, ok := gotObject[key]
+
+			if assert.True(t, ok, "%s.%s is missing", path, key) {
+				compare(path+"."+key, value, gotValue)
+			}
+		}
+
+		for key, value := range gotObject {
+			if _, ok := wantObject[key]; !ok {
+				assert.Nil(t, value, "%s.%s is not in the response", path, key)
+			}
+		}
+	}
+
+	compare("$", decodeJSON(t, want), decodeJSON(t, got))
+}
+
+func TestParseReadingsFrom(t *testing.T) {
+	path, err := filepath.Abs("../testdata/responses/readings200.json")
+
+	require.NoError(t, err)
+
+	f, err := os.Open(path)
+
+	require.NoError(t, err)
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	items, err := ParseReadingsFrom(context.TODO(), iotest.OneByteReader(f))
+
+	require.NoError(t, err)
+
+	var count int
+
+	for item := range items {
+		require.NoError(t, item.E, path, count)
+
+		_, ok := item.V.(*Readings)
+
+		assert.True(t, ok, path, count)
+
+		count++
+	}
+
+	assert.Equal(t, 507, count)
+}
+
+func TestParseReadings_Errors(t *testing.T) {
+	const data = `[
+{"id": 1, "dt": "2021-04-11T01:00:00.000"},
+{"id": 2, "dt": "2021-04-11T02:00:00.000", "m": 1.2.3},
+{"id": "three"},
+{"id": 4, "channelId": [1, {"a": "]}"}}]},
+{"id": 5}
+]`
+
+	var cases = []struct {
+		options []ParseOption
+		ids     []int64
+		errors  []int
+	}{
+		{ids: []int64{1, 5}, errors: []int{1, 2, 3}},
+		{options: []ParseOption{WithStrict()}, ids: []int64{1}, errors: []int{1}},
+	}
+
+	for _, test := range cases {
+		items, err := ParseReadings(context.TODO(), []byte(data), test.options...)
+
+		require.NoError(t, err)
+
+		ids := make([]int64, 0)
+		errs := make([]int, 0)
+
+		for item := range items {
+			if item.Error() {
+				var parseError *ParseError
+
+				require.True(t, errors.As(item.E, &parseError), item.E)
+
+				errs = append(errs, parseError.Index)
+
+				assert.Greater(t, parseError.Offset, int64(0))
+				assert.Less(t, parseError.Offset, int64(len(data)))
+
+				continue
+			}
+
+			ids = append(ids, item.V.(*Readings).ID.ValueOrZero())
+		}
+
+		assert.Equal(t, test.ids, ids)
+		assert.Equal(t, test.errors, errs)
+	}
+}
+
+func TestParseReadings_Truncated(t *testing.T) {
+	items, err := ParseReadings(context.TODO(), []byte(`[{"id": 1}, {"id": 2, "m": 1`))
+
+	require.NoError(t, err)
+
+	var values, errs int
+
+	for item := range items {
+		if item.Error() {
+			assert.ErrorIs(t, item.E, io.ErrUnexpectedEOF)
+			errs++
+		} else {
+			values++
+		}
+	}
+
+	assert.Equal(t, 1, values)
+	assert.Equal(t, 1, errs)
+
+	items, err = ParseReadings(context.TODO(), []byte(`[{"id": 1}, ]`))
+
+	require.NoError(t, err)
+
+	values, errs = 0, 0
+
+	for item := range items {
+		if item.Error() {
+			var parseError *ParseError
+
+			assert.True(t, errors.As(item.E, &parseError), item.E)
+			errs++
+		} else {
+			values++
+		}
+	}
+
+	assert.Equal(t, 1, values)
+	assert.Equal(t, 1, errs)
+
+	_, err = ParseReadings(context.TODO(), []byte(`{"id": 1}`))
+
+	assert.Error(t, err)
+}
+
+func TestParseReadings_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	items, err := ParseReadings(ctx, []byte(`[{"id": 1}, {"id": 2}, {"id": 3}]`))
+
+	require.NoError(t, err)
+
+	<-items
+
+	cancel()
+
+	select {
+	case <-drain(items):
+	case <-time.After(time.Second):
+		t.Fatal("parser goroutine did not stop after cancel")
+	}
+}
+
+func drain(items <-chan Item) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		time.Sleep(10 * time.Millisecond)
+
+		for range items {
+		}
+	}()
+
+	return done
+}
+
+func TestParseGaugesList_UnknownEnums(t *testing.T) {
+	const data = `[{"id": 1, "inputs": [{"number": 1, "channels": [
+{"id": 10, "type": "inFlow", "resourceType": "Steam", "number": 1},
+{"id": 11, "type": "sideFlow", "resourceType": "Plasma", "number": 2},
+{"id": 12, "type": "makeUpFlow", "resourceType": "FeedWater", "number": 3}
+]}]}]`
+
+	items, err := ParseGaugesList(context.TODO(), []byte(data))
+
+	require.NoError(t, err)
+
+	var gauges []*Gauge
+
+	for item := range items {
+		require.NoError(t, item.E)
+
+		gauges = append(gauges, item.V.(*Gauge))
+	}
+
+	require.Len(t, gauges, 1)
+
+	channels := gauges[0].Inputs[0].Channels
+
+	assert.Equal(t, ResourceSteam, channels[0].Resource)
+	assert.Equal(t, FlowDirect, channels[0].Flow)
+
+	assert.Equal(t, ResourceUnknown, channels[1].Resource)
+	assert.Equal(t, "Plasma", channels[1].RawResource)
+	assert.Equal(t, FlowUnknown, channels[1].Flow)
+	assert.Equal(t, "sideFlow", channels[1].RawFlow)
+
+	assert.Equal(t, ResourceFeedWater, channels[2].Resource)
+	assert.Equal(t, FlowMakeUp, channels[2].Flow)
+
+	b, err := json.Marshal(channels[1])
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": 11, "type": "sideFlow", "resourceType": "Plasma", "number": 2}`, string(b))
+
+	b, err = json.Marshal(Channel{ID: 13, Number: 4})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": 13, "number": 4}`, string(b))
+
+	var channel Channel
+
+	require.NoError(t, json.Unmarshal(b, &channel))
+	assert.Equal(t, Channel{ID: 13, Number: 4}, channel)
+
+	items, err = ParseGaugesList(context.TODO(), []byte(data), WithStrictEnums())
+
+	require.NoError(t, err)
+
+	var errs int
+
+	for item := range items {
+		if item.Error() {
+			errs++
+		}
+	}
+
+	assert.Equal(t, 1, errs)
+}
+
+func TestEach(t *testing.T) {
+	items, err := ParseReadings(context.TODO(), []byte(`[{"id": 1}, {"id": "x"}, {"id": 3}]`))
+
+	require.NoError(t, err)
+
+	var ids []int64
+
+	count, err := EachReadings(context.TODO(), items, func(r *Readings) error {
+		ids = append(ids, r.ID.Int64)
+		return nil
+	})
+
+	var parseError *ParseError
+
+	assert.True(t, errors.As(err, &parseError), err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []int64{1}, ids)
+
+	_, ok := <-items
+
+	assert.False(t, ok)
+
+	items, err = ParseReadings(context.TODO(), []byte(`[{"id": 1}, {"id": "x"}, {"id": 3}]`))
+
+	require.NoError(t, err)
+
+	ids = nil
+
+	var skipped []int
+
+	count, err = EachReadings(context.TODO(), items, func(r *Readings) error {
+		ids = append(ids, r.ID.Int64)
+		return nil
+	}, WithSkip(func(err *ParseError) {
+		skipped = append(skipped, err.Index)
+	}))
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []int64{1, 3}, ids)
+	assert.Equal(t, []int{1}, skipped)
+
+	items, err = ParseReadings(context.TODO(), []byte(`[{"id": 1}, {"id": "x"}, {"id": 3}]`), WithStrict())
+
+	require.NoError(t, err)
+
+	count, err = EachReadings(context.TODO(), items, func(r *Readings) error {
+		return nil
+	}, WithSkip(nil))
+
+	assert.True(t, errors.As(err, &parseError), err)
+	assert.Equal(t, 1, count)
+
+	items, err = ParseReadings(context.TODO(), []byte(`[{"id": 1}, {"id": 2`))
+
+	require.NoError(t, err)
+
+	count, err = EachReadings(context.TODO(), items, func(r *Readings) error {
+		return nil
+	}, WithSkip(nil))
+
+	assert.True(t, errors.As(err, &parseError), err)
+	assert.Equal(t, 1, count)
+
+	items, err = ParseReadings(context.TODO(), []byte(`[{"id": 1}, {"id": 2}]`))
+
+	require.NoError(t, err)
+
+	count, err = EachGauge(context.TODO(), items, func(gauge *Gauge) error {
+		return nil
+	})
+
+	assert.EqualError(t, err, "unexpected item *parsers.Readings")
+	assert.Equal(t, 0, count)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cancel()
+
+	items, err = ParseReadings(context.TODO(), []byte(`[{"id": 1}, {"id": 2}]`))
+
+	require.NoError(t, err)
+
+	count, err = Each(ctx, items, func(v interface{}) error {
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, count)
+}
@@ -1,8 +1,10 @@
 package parsers
 
 import (
+	"encoding"
 	"fmt"
-	"strings"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/internal/jsontext"
 )
 
 const (
@@ -14,6 +16,27 @@ const (
 
 	// none тип ресурса - не указан
 	none = "None"
+
+	// coldWater тип ресурса - холодная вода
+	coldWater = "ColdWater"
+
+	// steam тип ресурса - пар
+	steam = "Steam"
+
+	// gas тип ресурса - газ
+	gas = "Gas"
+
+	// electricity тип ресурса - электроэнергия
+	electricity = "Electricity"
+
+	// condensate тип ресурса - конденсат
+	condensate = "Condensate"
+
+	// feedWater тип ресурса - подпиточная вода
+	feedWater = "FeedWater"
+
+	// unknown неизвестное значение перечисления
+	unknown = "Unknown"
 )
 
 // Resource тип ресурса
@@ -31,26 +54,127 @@ const (
 
 	// ResourceNone ресурс не указан (для общего потребления тепловой энергии по тепловому вводу прибора учета)
 	ResourceNone
+
+	// ResourceColdWater тип ресурса - холодная вода
+	ResourceColdWater
+
+	// ResourceSteam тип ресурса - пар
+	ResourceSteam
+
+	// ResourceGas тип ресурса - газ
+	ResourceGas
+
+	// ResourceElectricity тип ресурса - электроэнергия
+	ResourceElectricity
+
+	// ResourceCondensate тип ресурса - конденсат
+	ResourceCondensate
+
+	// ResourceFeedWater тип ресурса - подпиточная вода
+	ResourceFeedWater
+)
+
+var (
+	_ encoding.TextMarshaler   = Resource(0)
+	_ encoding.TextUnmarshaler = (*Resource)(nil)
 )
 
+// String возвращает строковое представление типа ресурса, принятое в API Каскада
+func (r Resource) String() string {
+	switch r {
+	case ResourceHeat:
+		return heat
+
+	case ResourceHotWater:
+		return hotWater
+
+	case ResourceNone:
+		return none
+
+	case ResourceColdWater:
+		return coldWater
+
+	case ResourceSteam:
+		return steam
+
+	case ResourceGas:
+		return gas
+
+	case ResourceElectricity:
+		return electricity
+
+	case ResourceCondensate:
+		return condensate
+
+	case ResourceFeedWater:
+		return feedWater
+
+	default:
+		return unknown
+	}
+}
+
 // UnmarshalJSON реализация интерфейса Unmarshaler для типа Resource
 func (r *Resource) UnmarshalJSON(b []byte) (err error) {
-	s := strings.Trim(string(b), `"`)
+	s, err := jsontext.Unquote(b)
 
+	if err != nil {
+		return err
+	}
+
+	*r, err = ParseResource(s)
+
+	return
+}
+
+// MarshalJSON реализация интерфейса Marshaler для типа Resource
+func (r Resource) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"%s"`, r.String())), nil
+}
+
+// UnmarshalText реализация интерфейса encoding.TextUnmarshaler для типа Resource
+func (r *Resource) UnmarshalText(text []byte) (err error) {
+	*r, err = ParseResource(string(text))
+
+	return
+}
+
+// MarshalText реализация интерфейса encoding.TextMarshaler для типа Resource
+func (r Resource) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// ParseResource преобразование строки в значение Resource
+func ParseResource(s string) (Resource, error) {
 	switch s {
 	case heat:
-		*r = ResourceHeat
+		return ResourceHeat, nil
 
 	case hotWater:
-		*r = ResourceHotWater
+		return ResourceHotWater, nil
 
 	case none:
-		*r = ResourceNone
+		return ResourceNone, nil
+
+	case coldWater:
+		return ResourceColdWater, nil
+
+	case steam:
+		return ResourceSteam, nil
+
+	case gas:
+		return ResourceGas, nil
+
+	case electricity:
+		return ResourceElectricity, nil
+
+	case condensate:
+		return ResourceCondensate, nil
+
+	case feedWater:
+		return ResourceFeedWater, nil
 
 	default:
-		*r = ResourceUnknown
-		err = fmt.Errorf("unknown resource %s", s)
+		return ResourceUnknown, fmt.Errorf("unknown resource %s", s)
 	}
-
-	return
 }
@@ -47,8 +47,14 @@ type Channel struct {
 	// Resource тип ресурса
 	Resource Resource `json:"resourceType"`
 
-	// Flow тип подключения - подача или обратка
-	Flow Flow `json:"type"`
+	// Flow тип подключения - подача или обратка. Для каналов без типа подключения значение не указывается
+	Flow Flow `json:"type,omitempty"`
+
+	// RawResource исходное значение типа ресурса, если тип ресурса неизвестен (Resource == ResourceUnknown)
+	RawResource string `json:"-"`
+
+	// RawFlow исходное значение типа подключения, если тип подключения неизвестен (Flow == FlowUnknown)
+	RawFlow string `json:"-"`
 }
 
 // Readings элемент архива показаний
@@ -59,6 +65,9 @@ type Readings struct {
 	// ChannelID идентификатор канала/трубы
 	ChannelID null.Int `json:"channelId"`
 
+	// ChannelNum номер канала/трубы на тепловом вводе
+	ChannelNum null.Int `json:"channelNum"`
+
 	// CreateAt момент чтения показания.
 	//
 	// Если в ответе метода API значение не указано или null, то в результате поле будет иметь значение 0
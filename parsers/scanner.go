@@ -0,0 +1,236 @@
+package parsers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// elementScanner выделяет из потока JSON массива отдельные элементы без их разбора. Границы элементов определяются
+// по запятым на верхнем уровне вложенности массива, поэтому синтаксическая ошибка внутри элемента не мешает
+// перейти к следующему элементу
+type elementScanner struct {
+	r      *bufio.Reader
+	offset int64
+	buf    []byte
+	done   bool
+
+	// comma признак того, что предыдущий элемент закончился запятой
+	comma bool
+
+	// состояние разбора текущего элемента
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+func newElementScanner(r io.Reader) *elementScanner {
+	return &elementScanner{
+		r:   bufio.NewReader(r),
+		buf: make([]byte, 0, 1024),
+	}
+}
+
+// begin читает начало массива
+func (s *elementScanner) begin() error {
+	c, err := s.skipSpaces()
+
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return &ParseError{Index: -1, Offset: s.offset, Err: err}
+	}
+
+	if c != '[' {
+		return &ParseError{Index: -1, Offset: s.offset - 1, Err: fmt.Errorf("invalid character %q, expected array", c)}
+	}
+
+	return nil
+}
+
+// next возвращает очередной элемент массива и смещение его начала в потоке. По окончании массива возвращается
+// io.EOF, закрывающая скобка массива после запятой считается ошибкой. Возвращаемый срез действителен до следующего
+// вызова next
+func (s *elementScanner) next() ([]byte, int64, error) {
+	if s.done {
+		return nil, s.offset, io.EOF
+	}
+
+	c, err := s.skipSpaces()
+
+	if err != nil {
+		return nil, s.offset, s.eof(err)
+	}
+
+	if c == ']' {
+		s.done = true
+
+		if s.comma {
+			return nil, s.offset, errors.New("invalid character ']' after ','")
+		}
+
+		return nil, s.offset, io.EOF
+	}
+
+	start := s.offset - 1
+
+	s.buf = s.buf[:0]
+	s.depth, s.inString, s.escaped = 0, false, false
+
+	if s.step(c) {
+		s.comma = !s.done
+		return s.buf, start, nil
+	}
+
+	s.buf = append(s.buf, c)
+
+	for {
+		window, err := s.window()
+
+		if err != nil {
+			s.done = true
+			return nil, start, s.eof(err)
+		}
+
+		if i := s.scan(window); i >= 0 {
+			s.buf = append(s.buf, window[:i]...)
+			s.discard(i + 1)
+
+			s.comma = !s.done
+
+			return s.buf, start, nil
+		}
+
+		s.buf = append(s.buf, window...)
+		s.discard(len(window))
+	}
+}
+
+// scan обрабатывает символы window и возвращает индекс символа, которым заканчивается элемент, или -1, если
+// элемент продолжается за пределами window. Строки пропускаются целиком до закрывающей кавычки
+func (s *elementScanner) scan(window []byte) int {
+	depth, inString, escaped := s.depth, s.inString, s.escaped
+
+	defer func() {
+		s.depth, s.inString, s.escaped = depth, inString, escaped
+	}()
+
+	for i := 0; i < len(window); i++ {
+		c := window[i]
+
+		if inString {
+			if escaped {
+				escaped = false
+				continue
+			}
+
+			j := bytes.IndexAny(window[i:], `"\`)
+
+			if j < 0 {
+				return -1
+			}
+
+			i += j
+
+			if window[i] == '\\' {
+				escaped = true
+			} else {
+				inString = false
+			}
+
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+
+		case '{', '[':
+			depth++
+
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+
+		case ']':
+			if depth == 0 {
+				s.done = true
+				return i
+			}
+
+			depth--
+
+		case ',':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// step обрабатывает первый символ элемента и возвращает признак окончания элемента. Символ, которым заканчивается
+// элемент, в элемент не входит
+func (s *elementScanner) step(c byte) bool {
+	return s.scan([]byte{c}) == 0
+}
+
+// window возвращает прочитанные, но еще не обработанные данные потока, при необходимости читая их
+func (s *elementScanner) window() ([]byte, error) {
+	if s.r.Buffered() == 0 {
+		if _, err := s.r.Peek(1); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.r.Peek(s.r.Buffered())
+}
+
+// discard отмечает первые n байт данных потока обработанными
+func (s *elementScanner) discard(n int) {
+	_, _ = s.r.Discard(n)
+	s.offset += int64(n)
+}
+
+func (s *elementScanner) readByte() (byte, error) {
+	c, err := s.r.ReadByte()
+
+	if err == nil {
+		s.offset++
+	}
+
+	return c, err
+}
+
+func (s *elementScanner) skipSpaces() (byte, error) {
+	for {
+		c, err := s.readByte()
+
+		if err != nil {
+			return 0, err
+		}
+
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+
+		return c, nil
+	}
+}
+
+func (s *elementScanner) eof(err error) error {
+	s.done = true
+
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
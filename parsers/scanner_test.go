@@ -0,0 +1,59 @@
+package parsers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElementScanner(t *testing.T) {
+	var cases = []struct {
+		data     string
+		elements []string
+		err      bool
+	}{
+		{data: `[]`, elements: []string{}},
+		{data: ` [ 1 , "a,]" , {"b": [2, 3]} ] `, elements: []string{`1 `, `"a,]" `, `{"b": [2, 3]} `}},
+		{data: `[1,]`, elements: []string{`1`}, err: true},
+		{data: `[{"id": 1}, ]`, elements: []string{`{"id": 1}`}, err: true},
+		{data: `[1, 2`, elements: []string{`1`}, err: true},
+	}
+
+	for _, test := range cases {
+		scanner := newElementScanner(bytes.NewReader([]byte(test.data)))
+
+		require.NoError(t, scanner.begin(), test.data)
+
+		elements := make([]string, 0)
+
+		var err error
+
+		for {
+			var raw []byte
+
+			raw, _, err = scanner.next()
+
+			if err != nil {
+				break
+			}
+
+			elements = append(elements, string(raw))
+		}
+
+		assert.Equal(t, test.elements, elements, test.data)
+
+		if test.err {
+			assert.False(t, errors.Is(err, io.EOF), test.data)
+		} else {
+			assert.Equal(t, io.EOF, err, test.data)
+		}
+
+		_, _, err = scanner.next()
+
+		assert.Equal(t, io.EOF, err, test.data)
+	}
+}
@@ -1,26 +1,96 @@
 package parsers
 
 import (
-	"strings"
+	"encoding"
+	"fmt"
 	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/internal/jsontext"
 )
 
 // ReadingTime описывает формат времени, принятый в показаниях АИСКУТЭ Каскад
 type ReadingTime time.Time
 
-const readingTimeLayout = `2006-01-02T15:04:05.999`
+const (
+	// readingTimeLayout формат разбора времени показания
+	readingTimeLayout = `2006-01-02T15:04:05.999`
+
+	// readingTimeFormat формат вывода времени показания
+	readingTimeFormat = `2006-01-02T15:04:05.000`
+)
+
+var (
+	_ encoding.TextMarshaler   = ReadingTime{}
+	_ encoding.TextUnmarshaler = (*ReadingTime)(nil)
+)
 
 // UnmarshalJSON реализация интерфейса Unmarshaler для типа ReadingTime
 func (rt *ReadingTime) UnmarshalJSON(b []byte) (err error) {
-	s := strings.Trim(string(b), `"`)
+	s, err := jsontext.Unquote(b)
+
+	if err != nil {
+		return err
+	}
 
-	var t = time.Time{}
+	*rt, err = ParseReadingTime(s)
 
-	if s != "null" && s != "" {
-		t, err = time.Parse(readingTimeLayout, s)
+	return
+}
+
+// MarshalJSON реализация интерфейса Marshaler для типа ReadingTime. Нулевое значение времени кодируется как null
+func (rt ReadingTime) MarshalJSON() ([]byte, error) {
+	if rt.IsZero() {
+		return []byte("null"), nil
 	}
 
-	*rt = ReadingTime(t)
+	return []byte(fmt.Sprintf(`"%s"`, rt.String())), nil
+}
+
+// UnmarshalText реализация интерфейса encoding.TextUnmarshaler для типа ReadingTime
+func (rt *ReadingTime) UnmarshalText(text []byte) (err error) {
+	*rt, err = ParseReadingTime(string(text))
 
 	return
 }
+
+// MarshalText реализация интерфейса encoding.TextMarshaler для типа ReadingTime. Нулевое значение времени кодируется
+// пустой строкой
+func (rt ReadingTime) MarshalText() ([]byte, error) {
+	if rt.IsZero() {
+		return []byte{}, nil
+	}
+
+	return []byte(rt.String()), nil
+}
+
+// String возвращает строковое представление времени в формате АИСКУТЭ Каскад
+func (rt ReadingTime) String() string {
+	return time.Time(rt).Format(readingTimeFormat)
+}
+
+// Time возвращает значение времени показания
+func (rt ReadingTime) Time() time.Time {
+	return time.Time(rt)
+}
+
+// IsZero возвращает признак нулевого (не указанного) значения времени
+func (rt ReadingTime) IsZero() bool {
+	return time.Time(rt).IsZero()
+}
+
+// WallClock возвращает момент времени с показаниями часов t в UTC. Время в показаниях Каскада не содержит часового
+// пояса, поэтому моменты времени показаний сравниваются и сохраняются по показаниям часов
+func WallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// ParseReadingTime преобразование строки в значение ReadingTime. Пустая строка и null преобразуются в нулевое значение
+func ParseReadingTime(s string) (ReadingTime, error) {
+	if s == "null" || s == "" {
+		return ReadingTime{}, nil
+	}
+
+	t, err := time.Parse(readingTimeLayout, s)
+
+	return ReadingTime(t), err
+}
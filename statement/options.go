@@ -0,0 +1,29 @@
+package statement
+
+import (
+	"github.com/vitpelekhaty/go-cascade-client/v2/pairing"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+type statementOptions struct {
+	resource parsers.Resource
+	pairing  []pairing.Option
+}
+
+// Option опция построения ведомости учета
+type Option func(options *statementOptions)
+
+// WithResource устанавливает ресурс, по которому строится ведомость. По умолчанию ведомость строится по отоплению
+func WithResource(resource parsers.Resource) Option {
+	return func(options *statementOptions) {
+		options.resource = resource
+	}
+}
+
+// WithPairingOptions устанавливает опции сопоставления подающего и обратного трубопроводов, используемые при
+// расчете тепловой энергии
+func WithPairingOptions(opts ...pairing.Option) Option {
+	return func(options *statementOptions) {
+		options.pairing = append(options.pairing, opts...)
+	}
+}
@@ -0,0 +1,213 @@
+package statement
+
+import (
+	"encoding/csv"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// Labels подписи ведомости учета
+type Labels struct {
+	// Title заголовок ведомости
+	Title string
+
+	// Device подпись прибора учета
+	Device string
+
+	// SN подпись серийного номера
+	SN string
+
+	// Input подпись теплового ввода
+	Input string
+
+	// Period подпись периода
+	Period string
+
+	// Columns заголовки колонок: сутки, Q, M1, M2, T1, T2, V, TI
+	Columns [8]string
+
+	// Total подпись строки итогов
+	Total string
+}
+
+// DefaultLabels подписи ведомости учета по умолчанию
+var DefaultLabels = Labels{
+	Title:  "Ведомость учета тепловой энергии",
+	Device: "Прибор учета",
+	SN:     "Заводской номер",
+	Input:  "Тепловой ввод",
+	Period: "Период",
+	Columns: [8]string{
+		"Дата", "Q, Гкал", "M1, т", "M2, т", "T1, °C", "T2, °C", "V, м3", "Tи, ч",
+	},
+	Total: "Итого",
+}
+
+// Format формат вывода значений ведомости
+type Format struct {
+	// Labels подписи ведомости
+	Labels Labels
+
+	// DateLayout формат вывода суток
+	DateLayout string
+
+	// Precision количество знаков после запятой
+	Precision int
+
+	// DecimalSeparator десятичный разделитель
+	DecimalSeparator string
+}
+
+// DefaultFormat формат вывода ведомости по умолчанию
+var DefaultFormat = Format{
+	Labels:           DefaultLabels,
+	DateLayout:       "02.01.2006",
+	Precision:        3,
+	DecimalSeparator: ",",
+}
+
+// Number возвращает строковое представление значения. Для значения null возвращается пустая строка
+func (f Format) Number(v null.Float) string {
+	if !v.Valid {
+		return ""
+	}
+
+	s := strconv.FormatFloat(v.Float64, 'f', f.Precision, 64)
+
+	if f.DecimalSeparator != "" && f.DecimalSeparator != "." {
+		s = strings.Replace(s, ".", f.DecimalSeparator, 1)
+	}
+
+	return s
+}
+
+// Date возвращает строковое представление суток
+func (f Format) Date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(f.DateLayout)
+}
+
+// cells возвращает значения строки ведомости в порядке колонок. Для строки итогов вместо суток выводится подпись
+func (f Format) cells(row Row, totals bool) []string {
+	date := f.Date(row.Date)
+
+	if totals {
+		date = f.Labels.Total
+	}
+
+	return []string{
+		date,
+		f.Number(row.Q), f.Number(row.M1), f.Number(row.M2), f.Number(row.T1), f.Number(row.T2),
+		f.Number(row.V), f.Number(row.TI),
+	}
+}
+
+// device возвращает описание прибора учета в заголовке ведомости: адрес объекта, наименование и модель прибора учета
+func (h *Header) device() string {
+	return h.Title + " / " + h.Name + " (" + h.Model + ")"
+}
+
+// WriteCSV выводит ведомость в формате CSV с разделителем comma. Заголовок ведомости выводится строками из двух
+// колонок перед таблицей
+func (s *Statement) WriteCSV(w io.Writer, format Format, comma rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+
+	labels := format.Labels
+	h := s.Header
+
+	records := [][]string{
+		{labels.Title, ""},
+		{labels.Device, h.device()},
+		{labels.SN, h.SN},
+		{labels.Input, strconv.Itoa(int(h.Input))},
+		{labels.Period, format.Date(h.From) + " - " + format.Date(h.To)},
+		{},
+		labels.Columns[:],
+	}
+
+	for _, row := range s.Rows {
+		records = append(records, format.cells(row, false))
+	}
+
+	records = append(records, format.cells(s.Totals, true))
+
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+// DefaultHTMLTemplate шаблон HTML представления ведомости по умолчанию. Шаблон получает значение типа HTMLData
+const DefaultHTMLTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Format.Labels.Title}}</title>
+<style>
+table { border-collapse: collapse; }
+th, td { border: 1px solid #999; padding: 2px 6px; }
+td.number { text-align: right; }
+</style>
+</head>
+<body>
+<h1>{{.Format.Labels.Title}}</h1>
+<p>{{.Format.Labels.Device}}: {{.Statement.Header.Title}} / {{.Statement.Header.Name}} ({{.Statement.Header.Model}})</p>
+<p>{{.Format.Labels.SN}}: {{.Statement.Header.SN}}</p>
+<p>{{.Format.Labels.Input}}: {{.Statement.Header.Input}}</p>
+<p>{{.Format.Labels.Period}}: {{date .Statement.Header.From}} - {{date .Statement.Header.To}}</p>
+<table>
+<tr>{{range .Format.Labels.Columns}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Statement.Rows}}
+<tr><td>{{date .Date}}</td><td class="number">{{number .Q}}</td><td class="number">{{number .M1}}</td><td class="number">{{number .M2}}</td><td class="number">{{number .T1}}</td><td class="number">{{number .T2}}</td><td class="number">{{number .V}}</td><td class="number">{{number .TI}}</td></tr>
+{{- end}}
+{{- with .Statement.Totals}}
+<tr><th>{{$.Format.Labels.Total}}</th><th class="number">{{number .Q}}</th><th class="number">{{number .M1}}</th><th class="number">{{number .M2}}</th><th class="number">{{number .T1}}</th><th class="number">{{number .T2}}</th><th class="number">{{number .V}}</th><th class="number">{{number .TI}}</th></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`
+
+// HTMLData данные шаблона HTML представления ведомости
+type HTMLData struct {
+	// Statement ведомость
+	Statement *Statement
+
+	// Format формат вывода
+	Format Format
+}
+
+// NewHTMLTemplate возвращает шаблон HTML представления ведомости из текста text. В шаблоне доступны функции number
+// и date, выводящие значения и сутки в формате format
+func NewHTMLTemplate(text string, format Format) (*template.Template, error) {
+	return template.New("statement").Funcs(template.FuncMap{
+		"number": format.Number,
+		"date":   format.Date,
+	}).Parse(text)
+}
+
+// WriteHTML выводит ведомость в формате HTML по шаблону tmpl. Если шаблон не указан, используется
+// DefaultHTMLTemplate
+func (s *Statement) WriteHTML(w io.Writer, format Format, tmpl *template.Template) error {
+	if tmpl == nil {
+		var err error
+
+		tmpl, err = NewHTMLTemplate(DefaultHTMLTemplate, format)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return tmpl.Execute(w, HTMLData{Statement: s, Format: format})
+}
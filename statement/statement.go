@@ -0,0 +1,216 @@
+package statement
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/pairing"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+// Header заголовок ведомости учета
+type Header struct {
+	// DeviceID идентификатор прибора учета
+	DeviceID int64
+
+	// Title наименование прибора учета в АИСКУТЭ (адрес объекта)
+	Title string
+
+	// Name наименование прибора учета
+	Name string
+
+	// Model модель прибора учета
+	Model string
+
+	// SN серийный номер прибора учета
+	SN string
+
+	// Input номер теплового ввода
+	Input int32
+
+	// Resource тип ресурса
+	Resource parsers.Resource
+
+	// From первые сутки ведомости
+	From time.Time
+
+	// To последние сутки ведомости
+	To time.Time
+}
+
+// Row строка ведомости учета за сутки
+type Row struct {
+	// Date сутки
+	Date time.Time
+
+	// Q тепловая энергия, Гкал
+	Q null.Float
+
+	// M1 масса теплоносителя в подающем трубопроводе, т
+	M1 null.Float
+
+	// M2 масса теплоносителя в обратном трубопроводе, т
+	M2 null.Float
+
+	// T1 температура теплоносителя в подающем трубопроводе, °C
+	T1 null.Float
+
+	// T2 температура теплоносителя в обратном трубопроводе, °C
+	T2 null.Float
+
+	// V объем теплоносителя в подающем трубопроводе, м3
+	V null.Float
+
+	// TI время штатной работы прибора учета, ч
+	TI null.Float
+}
+
+// Statement ведомость учета тепловой энергии по тепловому вводу прибора учета
+type Statement struct {
+	// Header заголовок ведомости
+	Header Header
+
+	// Rows строки ведомости в порядке возрастания суток
+	Rows []Row
+
+	// Totals итоги ведомости: суммы Q, M1, M2, V, TI и средневзвешенные по массе температуры T1, T2. Поле Date не
+	// заполняется
+	Totals Row
+}
+
+// Build строит ведомость учета по суточным показаниям readings теплового ввода input прибора учета deviceID.
+// Сведения о приборе учета и каналах берутся из topo. Показания других приборов учета и тепловых вводов
+// пропускаются.
+//
+// Тепловая энергия по ресурсу (Q1 для отопления, Q2 для горячего водоснабжения) берется из показаний прибора учета;
+// если прибор учета ее не передает, используется тепловая энергия, рассчитанная по массам и температурам
+// теплоносителя. Тепловая энергия по вводу в целом (Q) к ресурсу не относится и не используется
+func Build(topo *topology.Topology, deviceID int64, input int32, readings []parsers.Readings,
+	opts ...Option) (*Statement, error) {
+	options := &statementOptions{resource: parsers.ResourceHeat}
+
+	for _, option := range opts {
+		option(options)
+	}
+
+	device, ok := topo.Device(deviceID)
+
+	if !ok {
+		return nil, fmt.Errorf("unknown gauge %d", deviceID)
+	}
+
+	if _, ok := topo.Input(deviceID, input); !ok {
+		return nil, fmt.Errorf("gauge %d has no input %d", deviceID, input)
+	}
+
+	selected := make([]parsers.Readings, 0, len(readings))
+
+	for _, r := range readings {
+		if r.DeviceID.Int64 != deviceID || r.Input.Int64 != int64(input) {
+			continue
+		}
+
+		if r.Archive != archive.DailyArchive && r.Archive != archive.UnknownArchive {
+			return nil, fmt.Errorf("reading %d: statement requires %s archive, got %s", r.ID.Int64,
+				archive.DailyArchive, r.Archive)
+		}
+
+		selected = append(selected, r)
+	}
+
+	s := &Statement{
+		Header: Header{
+			DeviceID: device.ID,
+			Title:    device.Title,
+			Name:     device.Name,
+			Model:    device.Model,
+			SN:       device.SN,
+			Input:    input,
+			Resource: options.resource,
+		},
+	}
+
+	for _, pair := range pairing.Pairs(topo, selected, options.pairing...) {
+		if pair.Resource != options.resource {
+			continue
+		}
+
+		row := Row{Date: archive.DailyArchive.Truncate(pair.DT)}
+
+		if pair.Supply != nil {
+			row.M1, row.T1, row.V, row.TI = pair.Supply.M, pair.Supply.T, pair.Supply.V, pair.Supply.TI
+		}
+
+		if pair.Return != nil {
+			row.M2, row.T2 = pair.Return.M, pair.Return.T
+		}
+
+		row.Q = pair.MeterHeat
+
+		if !row.Q.Valid {
+			row.Q = pair.Heat
+		}
+
+		s.Rows = append(s.Rows, row)
+	}
+
+	sort.Slice(s.Rows, func(i, j int) bool {
+		return s.Rows[i].Date.Before(s.Rows[j].Date)
+	})
+
+	if len(s.Rows) > 0 {
+		s.Header.From = s.Rows[0].Date
+		s.Header.To = s.Rows[len(s.Rows)-1].Date
+	}
+
+	s.Totals = total(s.Rows)
+
+	return s, nil
+}
+
+// total возвращает итоги строк ведомости
+func total(rows []Row) Row {
+	var (
+		totals   Row
+		t1, t2   float64
+		mt1, mt2 float64
+		sumOf    = func(acc *null.Float, v null.Float) {
+			if v.Valid {
+				*acc = null.FloatFrom(acc.Float64 + v.Float64)
+			}
+		}
+	)
+
+	for _, row := range rows {
+		sumOf(&totals.Q, row.Q)
+		sumOf(&totals.M1, row.M1)
+		sumOf(&totals.M2, row.M2)
+		sumOf(&totals.V, row.V)
+		sumOf(&totals.TI, row.TI)
+
+		if row.T1.Valid && row.M1.Valid {
+			t1 += row.T1.Float64 * row.M1.Float64
+			mt1 += row.M1.Float64
+		}
+
+		if row.T2.Valid && row.M2.Valid {
+			t2 += row.T2.Float64 * row.M2.Float64
+			mt2 += row.M2.Float64
+		}
+	}
+
+	if mt1 > 0 {
+		totals.T1 = null.FloatFrom(t1 / mt1)
+	}
+
+	if mt2 > 0 {
+		totals.T2 = null.FloatFrom(t2 / mt2)
+	}
+
+	return totals
+}
@@ -0,0 +1,234 @@
+package statement
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+func testTopology() *topology.Topology {
+	return topology.New([]parsers.Gauge{
+		{
+			ID:    1,
+			Title: "Шумакова, 32",
+			Name:  "ТМК-Н30 11412",
+			Model: "ТМК-Н30",
+			SN:    "11412",
+			Inputs: []parsers.Input{
+				{
+					Number: 1,
+					Channels: []parsers.Channel{
+						{ID: 10, Number: 1, Resource: parsers.ResourceHeat, Flow: parsers.FlowDirect},
+						{ID: 11, Number: 2, Resource: parsers.ResourceHeat, Flow: parsers.FlowReverse},
+						{ID: 13, Number: 0, Resource: parsers.ResourceNone},
+					},
+				},
+			},
+		},
+	})
+}
+
+func testReadings() []parsers.Readings {
+	f := null.FloatFrom
+	readings := make([]parsers.Readings, 0)
+
+	for day := 1; day <= 2; day++ {
+		dt := parsers.ReadingTime(time.Date(2021, 4, day, 0, 0, 0, 0, time.UTC))
+
+		row := func(channelID int64) parsers.Readings {
+			return parsers.Readings{
+				Archive:   archive.DailyArchive,
+				DeviceID:  null.IntFrom(1),
+				Input:     null.IntFrom(1),
+				ChannelID: null.IntFrom(channelID),
+				DT:        dt,
+			}
+		}
+
+		supply := row(10)
+		supply.M, supply.T, supply.V, supply.TI = f(float64(100*day)), f(70), f(102), f(24)
+
+		ret := row(11)
+		ret.M, ret.T = f(float64(98*day)), f(float64(40+day))
+
+		total := row(13)
+		total.Q1 = f(float64(day) * 3)
+
+		readings = append(readings, supply, ret, total)
+	}
+
+	return readings
+}
+
+func TestBuild(t *testing.T) {
+	s, err := Build(testTopology(), 1, 1, testReadings())
+
+	require.NoError(t, err)
+	require.Len(t, s.Rows, 2)
+
+	assert.Equal(t, "11412", s.Header.SN)
+	assert.True(t, s.Header.From.Equal(time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, s.Header.To.Equal(time.Date(2021, 4, 2, 0, 0, 0, 0, time.UTC)))
+
+	assert.Equal(t, 3.0, s.Rows[0].Q.Float64)
+	assert.Equal(t, 98.0, s.Rows[0].M2.Float64)
+	assert.Equal(t, 41.0, s.Rows[0].T2.Float64)
+
+	assert.InDelta(t, 9, s.Totals.Q.Float64, 1e-9)
+	assert.InDelta(t, 300, s.Totals.M1.Float64, 1e-9)
+	assert.InDelta(t, 294, s.Totals.M2.Float64, 1e-9)
+	assert.InDelta(t, 70, s.Totals.T1.Float64, 1e-9)
+	assert.InDelta(t, (41*98+42*196)/294.0, s.Totals.T2.Float64, 1e-9)
+	assert.InDelta(t, 48, s.Totals.TI.Float64, 1e-9)
+
+	_, err = Build(testTopology(), 2, 1, testReadings())
+
+	assert.Error(t, err)
+
+	hourly := testReadings()
+	hourly[0].Archive = archive.HourArchive
+
+	_, err = Build(testTopology(), 1, 1, hourly)
+
+	assert.Error(t, err)
+}
+
+func TestBuild_HotWater(t *testing.T) {
+	f := null.FloatFrom
+
+	topo := topology.New([]parsers.Gauge{
+		{
+			ID: 1,
+			Inputs: []parsers.Input{
+				{
+					Number: 1,
+					Channels: []parsers.Channel{
+						{ID: 10, Number: 1, Resource: parsers.ResourceHeat, Flow: parsers.FlowDirect},
+						{ID: 11, Number: 2, Resource: parsers.ResourceHeat, Flow: parsers.FlowReverse},
+						{ID: 20, Number: 3, Resource: parsers.ResourceHotWater, Flow: parsers.FlowDirect},
+						{ID: 21, Number: 4, Resource: parsers.ResourceHotWater, Flow: parsers.FlowReverse},
+						{ID: 13, Number: 0, Resource: parsers.ResourceNone},
+					},
+				},
+			},
+		},
+	})
+
+	dt := parsers.ReadingTime(time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC))
+
+	row := func(channelID int64, m, t float64) parsers.Readings {
+		return parsers.Readings{
+			Archive:   archive.DailyArchive,
+			DeviceID:  null.IntFrom(1),
+			Input:     null.IntFrom(1),
+			ChannelID: null.IntFrom(channelID),
+			DT:        dt,
+			M:         f(m),
+			T:         f(t),
+		}
+	}
+
+	total := parsers.Readings{
+		Archive:   archive.DailyArchive,
+		DeviceID:  null.IntFrom(1),
+		Input:     null.IntFrom(1),
+		ChannelID: null.IntFrom(13),
+		DT:        dt,
+		Q:         f(10),
+		Q1:        f(3),
+	}
+
+	readings := []parsers.Readings{row(10, 100, 70), row(11, 98, 40), row(20, 20, 60), row(21, 15, 45), total}
+
+	heating, err := Build(topo, 1, 1, readings)
+
+	require.NoError(t, err)
+	require.Len(t, heating.Rows, 1)
+	assert.Equal(t, 3.0, heating.Rows[0].Q.Float64)
+
+	hotWater, err := Build(topo, 1, 1, readings, WithResource(parsers.ResourceHotWater))
+
+	require.NoError(t, err)
+	require.Len(t, hotWater.Rows, 1)
+
+	// Q2 не передается, поэтому тепловая энергия по ГВС рассчитывается, а не берется из Q по вводу в целом
+	assert.True(t, hotWater.Rows[0].Q.Valid)
+	assert.Less(t, hotWater.Rows[0].Q.Float64, 2.0)
+	assert.Less(t, heating.Rows[0].Q.Float64+hotWater.Rows[0].Q.Float64, total.Q.Float64)
+}
+
+func TestStatement_Write(t *testing.T) {
+	s, err := Build(testTopology(), 1, 1, testReadings())
+
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	err = s.WriteCSV(&buf, DefaultFormat, ';')
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "01.04.2021;3,000;100,000;98,000;70,000;41,000;102,000;24,000\n")
+	assert.Contains(t, buf.String(), "Итого;9,000;300,000;294,000;70,000;")
+	assert.Contains(t, buf.String(), "Прибор учета;Шумакова, 32 / ТМК-Н30 11412 (ТМК-Н30)\n")
+	assert.Contains(t, buf.String(), "Заводской номер;11412\n")
+
+	buf.Reset()
+
+	err = s.WriteHTML(&buf, DefaultFormat, nil)
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "<td>02.04.2021</td><td class=\"number\">6,000</td>")
+	assert.Contains(t, buf.String(), "Шумакова, 32 / ТМК-Н30 11412 (ТМК-Н30)")
+
+	tmpl, err := NewHTMLTemplate(`{{range .Statement.Rows}}{{date .Date}}={{number .Q}} {{end}}`, DefaultFormat)
+
+	require.NoError(t, err)
+
+	buf.Reset()
+
+	err = s.WriteHTML(&buf, DefaultFormat, tmpl)
+
+	require.NoError(t, err)
+	assert.Equal(t, "01.04.2021=3,000 02.04.2021=6,000 ", buf.String())
+
+	buf.Reset()
+
+	err = s.WriteXLSX(&buf, DefaultFormat)
+
+	require.NoError(t, err)
+
+	book, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+
+	require.NoError(t, err)
+
+	var sheet string
+
+	for _, f := range book.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			r, err := f.Open()
+
+			require.NoError(t, err)
+
+			b, err := ioutil.ReadAll(r)
+
+			require.NoError(t, err)
+
+			sheet = string(b)
+		}
+	}
+
+	assert.True(t, strings.Contains(sheet, `<c r="B8"><v>3</v></c>`), sheet)
+	assert.True(t, strings.Contains(sheet, `<t>11412</t>`), sheet)
+	assert.True(t, strings.Contains(sheet, `<t>Шумакова, 32 / ТМК-Н30 11412 (ТМК-Н30)</t>`), sheet)
+}
@@ -0,0 +1,184 @@
+package statement
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/guregu/null"
+)
+
+// xlsxParts неизменяемые части книги Office Open XML
+var xlsxParts = []struct {
+	name, content string
+}{
+	{
+		name: "[Content_Types].xml",
+		content: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`,
+	},
+	{
+		name: "_rels/.rels",
+		content: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`,
+	},
+	{
+		name: "xl/workbook.xml",
+		content: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Statement" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`,
+	},
+	{
+		name: "xl/_rels/workbook.xml.rels",
+		content: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`,
+	},
+}
+
+// WriteXLSX выводит ведомость в формате XLSX (Office Open XML). Значения величин записываются числами без
+// округления, сутки и подписи - строками в формате format
+func (s *Statement) WriteXLSX(w io.Writer, format Format) error {
+	book := zip.NewWriter(w)
+
+	for _, part := range xlsxParts {
+		f, err := book.Create(part.name)
+
+		if err != nil {
+			return err
+		}
+
+		if _, err = io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+
+	f, err := book.Create("xl/worksheets/sheet1.xml")
+
+	if err != nil {
+		return err
+	}
+
+	sheet := newSheetWriter()
+
+	labels := format.Labels
+	h := s.Header
+
+	sheet.row(text(labels.Title))
+	sheet.row(text(labels.Device), text(h.device()))
+	sheet.row(text(labels.SN), text(h.SN))
+	sheet.row(text(labels.Input), number(null.FloatFrom(float64(h.Input))))
+	sheet.row(text(labels.Period), text(format.Date(h.From)+" - "+format.Date(h.To)))
+	sheet.row()
+
+	columns := make([]cell, 0, len(labels.Columns))
+
+	for _, column := range labels.Columns {
+		columns = append(columns, text(column))
+	}
+
+	sheet.row(columns...)
+
+	for _, row := range s.Rows {
+		sheet.row(rowCells(text(format.Date(row.Date)), row)...)
+	}
+
+	sheet.row(rowCells(text(labels.Total), s.Totals)...)
+
+	if _, err = io.WriteString(f, sheet.String()); err != nil {
+		return err
+	}
+
+	return book.Close()
+}
+
+// cell ячейка листа
+type cell struct {
+	value   string
+	numeric bool
+}
+
+func text(s string) cell {
+	return cell{value: s}
+}
+
+func number(v null.Float) cell {
+	if !v.Valid {
+		return cell{}
+	}
+
+	return cell{value: strconv.FormatFloat(v.Float64, 'g', -1, 64), numeric: true}
+}
+
+func rowCells(first cell, row Row) []cell {
+	return []cell{
+		first,
+		number(row.Q), number(row.M1), number(row.M2), number(row.T1), number(row.T2), number(row.V), number(row.TI),
+	}
+}
+
+// sheetWriter построитель XML листа книги
+type sheetWriter struct {
+	builder strings.Builder
+	rows    int
+}
+
+func newSheetWriter() *sheetWriter {
+	w := &sheetWriter{}
+
+	w.builder.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	w.builder.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	return w
+}
+
+func (w *sheetWriter) row(cells ...cell) {
+	w.rows++
+
+	w.builder.WriteString(`<row r="` + strconv.Itoa(w.rows) + `">`)
+
+	for i, c := range cells {
+		if c.value == "" {
+			continue
+		}
+
+		ref := columnName(i) + strconv.Itoa(w.rows)
+
+		if c.numeric {
+			w.builder.WriteString(`<c r="` + ref + `"><v>` + c.value + `</v></c>`)
+			continue
+		}
+
+		w.builder.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t>`)
+		_ = xml.EscapeText(&w.builder, []byte(c.value))
+		w.builder.WriteString(`</t></is></c>`)
+	}
+
+	w.builder.WriteString(`</row>`)
+}
+
+func (w *sheetWriter) String() string {
+	return w.builder.String() + `</sheetData></worksheet>`
+}
+
+// columnName возвращает буквенное обозначение колонки листа по ее номеру, начиная с 0
+func columnName(i int) string {
+	name := ""
+
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+
+	return name
+}
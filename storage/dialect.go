@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// ColumnType тип данных колонки таблицы
+type ColumnType byte
+
+const (
+	// TypeInteger целое число
+	TypeInteger ColumnType = iota
+	// TypeFloat вещественное число
+	TypeFloat
+	// TypeText строка
+	TypeText
+	// TypeBoolean логическое значение
+	TypeBoolean
+	// TypeTime момент времени без часового пояса
+	TypeTime
+)
+
+// Column колонка таблицы
+type Column struct {
+	// Name наименование колонки
+	Name string
+
+	// Type тип данных колонки
+	Type ColumnType
+
+	// Nullable признак колонки, допускающей NULL
+	Nullable bool
+}
+
+// Index индекс таблицы
+type Index struct {
+	// Name наименование индекса
+	Name string
+
+	// Columns колонки индекса
+	Columns []string
+}
+
+// Table таблица базы данных
+type Table struct {
+	// Name наименование таблицы
+	Name string
+
+	// Columns колонки таблицы
+	Columns []Column
+
+	// Key колонки первичного ключа
+	Key []string
+
+	// Version колонка версии строки. Если колонка указана, сохраняемая строка заменяет строку с тем же ключом,
+	// только если ее версия не меньше версии сохраненной строки
+	Version string
+
+	// Indexes индексы таблицы
+	Indexes []Index
+}
+
+// ColumnNames возвращает наименования колонок таблицы через запятую
+func (t *Table) ColumnNames() string {
+	names := make([]string, len(t.Columns))
+
+	for i, column := range t.Columns {
+		names[i] = column.Name
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Dialect особенности диалекта SQL базы данных. Запросы формируются с параметрами '?', которые заменяются
+// параметрами диалекта (см. Dialect.Placeholder). База данных должна поддерживать транзакции, удаление строк
+// инструкцией DELETE и вставку с заменой строк по первичному ключу с учетом версии строки (см. Table.Version)
+type Dialect interface {
+	// Name возвращает наименование диалекта
+	Name() string
+
+	// ColumnType возвращает тип данных колонки в диалекте
+	ColumnType(column Column) string
+
+	// CreateTable возвращает инструкции создания таблицы и ее индексов, если они не существуют
+	CreateTable(table *Table) []string
+
+	// Insert возвращает инструкцию вставки rows строк в таблицу table с заменой строк с тем же первичным ключом
+	Insert(table *Table, rows int) string
+
+	// Placeholder возвращает обозначение параметра запроса с порядковым номером n (начиная с 1)
+	Placeholder(n int) string
+
+	// MaxParameters возвращает наибольшее количество параметров запроса
+	MaxParameters() int
+
+	// TimeValue возвращает значение параметра запроса для момента времени t (см. TypeTime)
+	TimeValue(t time.Time) driver.Value
+}
+
+// rebind заменяет параметры запроса '?' параметрами диалекта d
+func rebind(d Dialect, query string) string {
+	var (
+		b strings.Builder
+		n int
+	)
+
+	b.Grow(len(query))
+
+	for _, r := range query {
+		if r != '?' {
+			b.WriteRune(r)
+			continue
+		}
+
+		n++
+
+		b.WriteString(d.Placeholder(n))
+	}
+
+	return b.String()
+}
+
+// createTable возвращает инструкции создания таблицы table с первичным ключом и индексов в диалекте d
+func createTable(d Dialect, table *Table) []string {
+	columns := make([]string, 0, len(table.Columns)+1)
+
+	for _, column := range table.Columns {
+		columns = append(columns, column.Name+" "+d.ColumnType(column))
+	}
+
+	columns = append(columns, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(table.Key, ", ")))
+
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n\t%s\n)", table.Name, strings.Join(columns, ",\n\t")),
+	}
+
+	for _, index := range table.Indexes {
+		statements = append(statements, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)",
+			index.Name, table.Name, strings.Join(index.Columns, ", ")))
+	}
+
+	return statements
+}
+
+// insertValues возвращает инструкцию вставки rows строк в таблицу table без обработки конфликтов
+func insertValues(table *Table, rows int) string {
+	var b strings.Builder
+
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(table.Columns)), ", ") + ")"
+
+	b.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table.Name, table.ColumnNames()))
+
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteString(row)
+	}
+
+	return b.String()
+}
+
+// onConflictUpdate возвращает условие ON CONFLICT, заменяющее строку с тем же первичным ключом, с учетом версии
+// строки. Условие поддерживается SQLite и PostgreSQL
+func onConflictUpdate(table *Table) string {
+	set := make([]string, 0, len(table.Columns))
+
+	for _, column := range table.Columns {
+		if !contains(table.Key, column.Name) {
+			set = append(set, fmt.Sprintf("%s = excluded.%s", column.Name, column.Name))
+		}
+	}
+
+	key := strings.Join(table.Key, ", ")
+
+	if len(set) == 0 {
+		return fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", key)
+	}
+
+	clause := fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", key, strings.Join(set, ", "))
+
+	if table.Version != "" {
+		clause += fmt.Sprintf(" WHERE excluded.%[2]s IS NULL OR %[1]s.%[2]s IS NULL OR excluded.%[2]s >= %[1]s.%[2]s",
+			table.Name, table.Version)
+	}
+
+	return clause
+}
+
+// contains проверяет наличие строки s в списке list
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readingTime значение колонки TypeTime, прочитанное из базы данных. Поддерживаются строковое представление
+// времени и time.Time
+type readingTime struct {
+	rt parsers.ReadingTime
+}
+
+// Scan реализация интерфейса sql.Scanner для типа readingTime
+func (t *readingTime) Scan(src interface{}) (err error) {
+	switch v := src.(type) {
+	case nil:
+		t.rt = parsers.ReadingTime{}
+
+	case string:
+		t.rt, err = parsers.ParseReadingTime(v)
+
+	case []byte:
+		t.rt, err = parsers.ParseReadingTime(string(v))
+
+	case time.Time:
+		t.rt = parsers.ReadingTime(parsers.WallClock(v))
+
+	default:
+		err = fmt.Errorf("cannot scan %T into reading time", src)
+	}
+
+	return
+}
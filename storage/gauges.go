@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// SaveGauges сохраняет список приборов учета. Тепловые вводы и каналы сохраненного прибора учета заменяются
+// тепловыми вводами и каналами из списка gauges, приборы учета, отсутствующие в списке, не изменяются
+func (s *DB) SaveGauges(ctx context.Context, gauges []parsers.Gauge) error {
+	var devices, inputs, channels [][]interface{}
+
+	for _, gauge := range gauges {
+		devices = append(devices, []interface{}{gauge.ID, gauge.Name, gauge.Model, gauge.SN, gauge.Title})
+
+		for _, input := range gauge.Inputs {
+			inputs = append(inputs, []interface{}{gauge.ID, input.Number})
+
+			for i := range input.Channels {
+				channel := &input.Channels[i]
+
+				channels = append(channels, []interface{}{
+					channel.ID, gauge.ID, input.Number, channel.Number, channel.ResourceValue(), flowValue(channel),
+				})
+			}
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	for _, gauge := range gauges {
+		for _, table := range []string{channelsTable.Name, inputsTable.Name} {
+			query := rebind(s.dialect, "DELETE FROM "+table+" WHERE device_id = ?")
+
+			if _, err := tx.ExecContext(ctx, query, gauge.ID); err != nil {
+				return fmt.Errorf("save gauge %d: %w", gauge.ID, err)
+			}
+		}
+	}
+
+	for _, batch := range []struct {
+		table *Table
+		rows  [][]interface{}
+	}{
+		{table: devicesTable, rows: devices},
+		{table: inputsTable, rows: inputs},
+		{table: channelsTable, rows: channels},
+	} {
+		if err := s.insert(ctx, tx, batch.table, batch.rows); err != nil {
+			return fmt.Errorf("save %s: %w", batch.table.Name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Gauges возвращает список сохраненных приборов учета в порядке возрастания идентификатора
+func (s *DB) Gauges(ctx context.Context) ([]parsers.Gauge, error) {
+	return s.gauges(ctx, `SELECT `+devicesTable.ColumnNames()+` FROM devices ORDER BY id`)
+}
+
+// Gauge возвращает сохраненный прибор учета с идентификатором id. Если прибор учета не сохранен, возвращается
+// ошибка sql.ErrNoRows
+func (s *DB) Gauge(ctx context.Context, id int64) (*parsers.Gauge, error) {
+	gauges, err := s.gauges(ctx, `SELECT `+devicesTable.ColumnNames()+` FROM devices WHERE id = ?`, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(gauges) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &gauges[0], nil
+}
+
+// gauges возвращает приборы учета, выбранные запросом query, с тепловыми вводами и каналами
+func (s *DB) gauges(ctx context.Context, query string, args ...interface{}) ([]parsers.Gauge, error) {
+	rows, err := s.query(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	gauges := make([]parsers.Gauge, 0)
+
+	for rows.Next() {
+		var gauge parsers.Gauge
+
+		if err := rows.Scan(&gauge.ID, &gauge.Name, &gauge.Model, &gauge.SN, &gauge.Title); err != nil {
+			return nil, err
+		}
+
+		gauges = append(gauges, gauge)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range gauges {
+		if gauges[i].Inputs, err = s.inputs(ctx, gauges[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return gauges, nil
+}
+
+// inputs возвращает тепловые вводы прибора учета с идентификатором deviceID
+func (s *DB) inputs(ctx context.Context, deviceID int64) ([]parsers.Input, error) {
+	rows, err := s.query(ctx, `SELECT i.number, c.id, c.number, c.resource, c.flow
+		FROM inputs i LEFT JOIN channels c ON c.device_id = i.device_id AND c.input = i.number
+		WHERE i.device_id = ? ORDER BY i.number, c.number, c.id`, deviceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	inputs := make([]parsers.Input, 0)
+
+	for rows.Next() {
+		var (
+			number    int32
+			id        sql.NullInt64
+			channelNo sql.NullInt32
+			resource  sql.NullString
+			flow      sql.NullString
+		)
+
+		if err := rows.Scan(&number, &id, &channelNo, &resource, &flow); err != nil {
+			return nil, err
+		}
+
+		if len(inputs) == 0 || inputs[len(inputs)-1].Number != number {
+			inputs = append(inputs, parsers.Input{Number: number, Channels: make([]parsers.Channel, 0)})
+		}
+
+		if !id.Valid {
+			continue
+		}
+
+		channel := parsers.Channel{ID: id.Int64, Number: channelNo.Int32}
+
+		setResource(&channel, resource.String)
+		setFlow(&channel, flow)
+
+		input := &inputs[len(inputs)-1]
+		input.Channels = append(input.Channels, channel)
+	}
+
+	return inputs, rows.Err()
+}
+
+// flowValue возвращает сохраняемое значение типа подключения канала. Для каналов без типа подключения
+// сохраняется NULL, для неизвестного типа подключения - исходное значение
+func flowValue(channel *parsers.Channel) sql.NullString {
+	flow := channel.FlowValue()
+
+	return sql.NullString{String: flow, Valid: flow != ""}
+}
+
+// setResource устанавливает тип ресурса канала по сохраненному значению
+func setResource(channel *parsers.Channel, value string) {
+	resource, err := parsers.ParseResource(value)
+
+	channel.Resource = resource
+
+	if err != nil && value != parsers.ResourceUnknown.String() {
+		channel.RawResource = value
+	}
+}
+
+// setFlow устанавливает тип подключения канала по сохраненному значению
+func setFlow(channel *parsers.Channel, value sql.NullString) {
+	if !value.Valid {
+		return
+	}
+
+	flow, err := parsers.ParseFlow(value.String)
+
+	channel.Flow = flow
+
+	if err != nil && value.String != parsers.FlowUnknown.String() {
+		channel.RawFlow = value.String
+	}
+}
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration версия схемы базы данных. Версия хранит изменения схемы в том виде, в котором она была выпущена, и не
+// зависит от описания таблиц, используемого запросами (см. schema.go)
+type migration struct {
+	// version номер версии схемы
+	version int
+
+	// description описание изменений схемы
+	description string
+
+	// tables таблицы, создаваемые версией схемы
+	tables []*Table
+}
+
+// migrations версии схемы базы данных в порядке применения. Примененные версии не изменяются, изменения схемы
+// добавляются новыми версиями
+var migrations = []migration{
+	{
+		version:     1,
+		description: "gauges",
+		tables: []*Table{
+			{
+				Name: "devices",
+				Columns: []Column{
+					{Name: "id", Type: TypeInteger},
+					{Name: "name", Type: TypeText},
+					{Name: "model", Type: TypeText},
+					{Name: "serial_number", Type: TypeText},
+					{Name: "title", Type: TypeText},
+				},
+				Key: []string{"id"},
+			},
+			{
+				Name: "inputs",
+				Columns: []Column{
+					{Name: "device_id", Type: TypeInteger},
+					{Name: "number", Type: TypeInteger},
+				},
+				Key: []string{"device_id", "number"},
+			},
+			{
+				Name: "channels",
+				Columns: []Column{
+					{Name: "id", Type: TypeInteger},
+					{Name: "device_id", Type: TypeInteger},
+					{Name: "input", Type: TypeInteger},
+					{Name: "number", Type: TypeInteger},
+					{Name: "resource", Type: TypeText},
+					{Name: "flow", Type: TypeText, Nullable: true},
+				},
+				Key: []string{"id"},
+				Indexes: []Index{
+					{Name: "channels_device", Columns: []string{"device_id", "input"}},
+				},
+			},
+		},
+	},
+	{
+		version:     2,
+		description: "readings",
+		tables: []*Table{
+			{
+				Name: "readings",
+				Columns: []Column{
+					{Name: "id", Type: TypeInteger},
+					{Name: "archive", Type: TypeText},
+					{Name: "device_id", Type: TypeInteger, Nullable: true},
+					{Name: "input", Type: TypeInteger, Nullable: true},
+					{Name: "channel_id", Type: TypeInteger, Nullable: true},
+					{Name: "channel_num", Type: TypeInteger, Nullable: true},
+					{Name: "dt", Type: TypeTime},
+					{Name: "create_at", Type: TypeTime, Nullable: true},
+					{Name: "m", Type: TypeFloat, Nullable: true},
+					{Name: "v", Type: TypeFloat, Nullable: true},
+					{Name: "p", Type: TypeFloat, Nullable: true},
+					{Name: "t", Type: TypeFloat, Nullable: true},
+					{Name: "tcw", Type: TypeFloat, Nullable: true},
+					{Name: "ti", Type: TypeFloat, Nullable: true},
+					{Name: "q", Type: TypeFloat, Nullable: true},
+					{Name: "q1", Type: TypeFloat, Nullable: true},
+					{Name: "q2", Type: TypeFloat, Nullable: true},
+					{Name: "is_bad_row", Type: TypeBoolean},
+					{Name: "is_empty", Type: TypeBoolean, Nullable: true},
+				},
+				Key: []string{"id"},
+				Indexes: []Index{
+					{Name: "readings_device_dt", Columns: []string{"device_id", "archive", "dt"}},
+				},
+			},
+		},
+	},
+}
+
+// SchemaVersion актуальная версия схемы базы данных
+var SchemaVersion = migrations[len(migrations)-1].version
+
+// migrate применяет к базе данных db недостающие версии схемы в диалекте d. Каждая версия применяется в отдельной
+// транзакции. База данных со схемой версии новее SchemaVersion не открывается
+func migrate(ctx context.Context, db *sql.DB, d Dialect) error {
+	for _, statement := range d.CreateTable(migrationsTable) {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("create %s: %w", migrationsTable.Name, err)
+		}
+	}
+
+	current, err := schemaVersion(ctx, db)
+
+	if err != nil {
+		return err
+	}
+
+	if current > SchemaVersion {
+		return fmt.Errorf("schema version %d is newer than supported version %d", current, SchemaVersion)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		if err := apply(ctx, db, d, m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.description, err)
+		}
+	}
+
+	return nil
+}
+
+// apply применяет версию схемы m
+func apply(ctx context.Context, db *sql.DB, d Dialect, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	for _, table := range m.tables {
+		for _, statement := range d.CreateTable(table) {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, rebind(d, insertValues(migrationsTable, 1)),
+		m.version, m.description, d.TimeValue(time.Now().UTC()))
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// schemaVersion возвращает версию схемы базы данных db
+func schemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version sql.NullInt64
+
+	if err := db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+
+	return int(version.Int64), nil
+}
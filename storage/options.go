@@ -0,0 +1,35 @@
+package storage
+
+// defaultBatchSize наибольшее количество строк, вставляемых одной инструкцией, по умолчанию
+const defaultBatchSize = 500
+
+type dbOptions struct {
+	batchSize int
+}
+
+func newDBOptions(opts []Option) *dbOptions {
+	options := &dbOptions{
+		batchSize: defaultBatchSize,
+	}
+
+	for _, option := range opts {
+		option(options)
+	}
+
+	if options.batchSize < 1 {
+		options.batchSize = 1
+	}
+
+	return options
+}
+
+// Option опция хранилища
+type Option func(options *dbOptions)
+
+// WithBatchSize устанавливает наибольшее количество строк, вставляемых одной инструкцией. Количество строк также
+// ограничено наибольшим количеством параметров запроса диалекта. По умолчанию 500
+func WithBatchSize(size int) Option {
+	return func(options *dbOptions) {
+		options.batchSize = size
+	}
+}
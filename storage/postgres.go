@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"database/sql/driver"
+	"strconv"
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// PostgreSQL диалект PostgreSQL (в том числе TimescaleDB). Драйвер базы данных подключается приложением
+var PostgreSQL Dialect = postgres{}
+
+type postgres struct{}
+
+// Name реализация метода Dialect.Name
+func (postgres) Name() string {
+	return "postgres"
+}
+
+// ColumnType реализация метода Dialect.ColumnType
+func (postgres) ColumnType(column Column) string {
+	var t string
+
+	switch column.Type {
+	case TypeInteger:
+		t = "BIGINT"
+	case TypeFloat:
+		t = "DOUBLE PRECISION"
+	case TypeBoolean:
+		t = "BOOLEAN"
+	case TypeTime:
+		t = "TIMESTAMP(3)"
+	default:
+		t = "TEXT"
+	}
+
+	if !column.Nullable {
+		t += " NOT NULL"
+	}
+
+	return t
+}
+
+// CreateTable реализация метода Dialect.CreateTable
+func (d postgres) CreateTable(table *Table) []string {
+	return createTable(d, table)
+}
+
+// Insert реализация метода Dialect.Insert
+func (postgres) Insert(table *Table, rows int) string {
+	return insertValues(table, rows) + onConflictUpdate(table)
+}
+
+// Placeholder реализация метода Dialect.Placeholder
+func (postgres) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// MaxParameters реализация метода Dialect.MaxParameters
+func (postgres) MaxParameters() int {
+	return 65535
+}
+
+// TimeValue реализация метода Dialect.TimeValue
+func (postgres) TimeValue(t time.Time) driver.Value {
+	return parsers.WallClock(t)
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// SaveReadings сохраняет показания и возвращает количество сохраненных показаний. Показания сохраняются по
+// идентификатору: повторно полученное показание заменяет сохраненное, если оно прочитано с прибора учета не
+// раньше сохраненного (см. parsers.Readings.CreateAt), поэтому повторная загрузка архивов и загрузка измененных
+// показаний (AlteredReadings) не нарушают данные. Из показаний readings с одинаковым идентификатором по тому же
+// правилу сохраняется одно. Показания без идентификатора не сохраняются
+func (s *DB) SaveReadings(ctx context.Context, readings []parsers.Readings) (int, error) {
+	rows := make([][]interface{}, 0, len(readings))
+
+	// latest номер строки rows по идентификатору показания, saved показания, сохраняемые строками rows. Многострочная
+	// вставка с обработкой конфликтов не допускает строк с одинаковым ключом (PostgreSQL)
+	latest := make(map[int64]int, len(readings))
+	saved := make([]*parsers.Readings, 0, len(readings))
+
+	for i := range readings {
+		r := &readings[i]
+
+		if !r.ID.Valid {
+			continue
+		}
+
+		row := []interface{}{
+			r.ID, r.Archive, r.DeviceID, r.Input, r.ChannelID, r.ChannelNum, s.timeValue(r.DT),
+			s.timeValue(r.CreateAt), r.M, r.V, r.P, r.T, r.TCW, r.TI, r.Q, r.Q1, r.Q2, r.IsBadRow, r.Empty,
+		}
+
+		if n, ok := latest[r.ID.Int64]; ok {
+			if !r.CreateAt.Time().Before(saved[n].CreateAt.Time()) {
+				rows[n], saved[n] = row, r
+			}
+
+			continue
+		}
+
+		latest[r.ID.Int64] = len(rows)
+
+		rows = append(rows, row)
+		saved = append(saved, r)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	if err := s.insert(ctx, tx, readingsTable, rows); err != nil {
+		return 0, fmt.Errorf("save readings: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(rows), nil
+}
+
+// Readings возвращает сохраненные показания архива a прибора учета с идентификатором deviceID за период
+// [from, to) в порядке возрастания момента показания и номера канала. Моменты показаний сравниваются по показаниям
+// часов без учета часового пояса
+func (s *DB) Readings(ctx context.Context, deviceID int64, a archive.DataArchive, from, to time.Time) (
+	[]parsers.Readings, error) {
+	rows, err := s.query(ctx, `SELECT `+readingsTable.ColumnNames()+` FROM readings
+		WHERE device_id = ? AND archive = ? AND dt >= ? AND dt < ?
+		ORDER BY dt, input, channel_num, id`,
+		deviceID, a, s.dialect.TimeValue(parsers.WallClock(from)), s.dialect.TimeValue(parsers.WallClock(to)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	readings := make([]parsers.Readings, 0)
+
+	for rows.Next() {
+		r, err := scanReadings(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		readings = append(readings, *r)
+	}
+
+	return readings, rows.Err()
+}
+
+// LastReadingTime возвращает момент последнего сохраненного показания архива a прибора учета с идентификатором
+// deviceID. Если показания не сохранены, возвращается нулевое значение
+func (s *DB) LastReadingTime(ctx context.Context, deviceID int64, a archive.DataArchive) (parsers.ReadingTime, error) {
+	var dt readingTime
+
+	query := rebind(s.dialect, `SELECT MAX(dt) FROM readings WHERE device_id = ? AND archive = ?`)
+
+	err := s.db.QueryRowContext(ctx, query, deviceID, a).Scan(&dt)
+
+	return dt.rt, err
+}
+
+// scanReadings читает показание из строки результата запроса
+func scanReadings(rows *sql.Rows) (*parsers.Readings, error) {
+	var (
+		r            parsers.Readings
+		dt, createAt readingTime
+	)
+
+	err := rows.Scan(&r.ID, &r.Archive, &r.DeviceID, &r.Input, &r.ChannelID, &r.ChannelNum, &dt, &createAt,
+		&r.M, &r.V, &r.P, &r.T, &r.TCW, &r.TI, &r.Q, &r.Q1, &r.Q2, &r.IsBadRow, &r.Empty)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.DT, r.CreateAt = dt.rt, createAt.rt
+
+	return &r, nil
+}
+
+// timeValue возвращает сохраняемое значение момента времени. Нулевое значение сохраняется как NULL
+func (s *DB) timeValue(rt parsers.ReadingTime) driver.Value {
+	if rt.IsZero() {
+		return nil
+	}
+
+	return s.dialect.TimeValue(rt.Time())
+}
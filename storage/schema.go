@@ -0,0 +1,87 @@
+package storage
+
+// Описания таблиц, используемые запросами. Изменение описания таблицы сопровождается новой версией схемы (см.
+// migrations): описания должны совпадать со схемой, получаемой применением всех версий
+var (
+	// migrationsTable таблица примененных версий схемы
+	migrationsTable = &Table{
+		Name: "schema_migrations",
+		Columns: []Column{
+			{Name: "version", Type: TypeInteger},
+			{Name: "description", Type: TypeText},
+			{Name: "applied_at", Type: TypeTime},
+		},
+		Key: []string{"version"},
+	}
+
+	// devicesTable таблица приборов учета
+	devicesTable = &Table{
+		Name: "devices",
+		Columns: []Column{
+			{Name: "id", Type: TypeInteger},
+			{Name: "name", Type: TypeText},
+			{Name: "model", Type: TypeText},
+			{Name: "serial_number", Type: TypeText},
+			{Name: "title", Type: TypeText},
+		},
+		Key: []string{"id"},
+	}
+
+	// inputsTable таблица тепловых вводов
+	inputsTable = &Table{
+		Name: "inputs",
+		Columns: []Column{
+			{Name: "device_id", Type: TypeInteger},
+			{Name: "number", Type: TypeInteger},
+		},
+		Key: []string{"device_id", "number"},
+	}
+
+	// channelsTable таблица каналов
+	channelsTable = &Table{
+		Name: "channels",
+		Columns: []Column{
+			{Name: "id", Type: TypeInteger},
+			{Name: "device_id", Type: TypeInteger},
+			{Name: "input", Type: TypeInteger},
+			{Name: "number", Type: TypeInteger},
+			{Name: "resource", Type: TypeText},
+			{Name: "flow", Type: TypeText, Nullable: true},
+		},
+		Key: []string{"id"},
+		Indexes: []Index{
+			{Name: "channels_device", Columns: []string{"device_id", "input"}},
+		},
+	}
+
+	// readingsTable таблица показаний. Версией показания является момент чтения показания с прибора учета
+	readingsTable = &Table{
+		Name: "readings",
+		Columns: []Column{
+			{Name: "id", Type: TypeInteger},
+			{Name: "archive", Type: TypeText},
+			{Name: "device_id", Type: TypeInteger, Nullable: true},
+			{Name: "input", Type: TypeInteger, Nullable: true},
+			{Name: "channel_id", Type: TypeInteger, Nullable: true},
+			{Name: "channel_num", Type: TypeInteger, Nullable: true},
+			{Name: "dt", Type: TypeTime},
+			{Name: "create_at", Type: TypeTime, Nullable: true},
+			{Name: "m", Type: TypeFloat, Nullable: true},
+			{Name: "v", Type: TypeFloat, Nullable: true},
+			{Name: "p", Type: TypeFloat, Nullable: true},
+			{Name: "t", Type: TypeFloat, Nullable: true},
+			{Name: "tcw", Type: TypeFloat, Nullable: true},
+			{Name: "ti", Type: TypeFloat, Nullable: true},
+			{Name: "q", Type: TypeFloat, Nullable: true},
+			{Name: "q1", Type: TypeFloat, Nullable: true},
+			{Name: "q2", Type: TypeFloat, Nullable: true},
+			{Name: "is_bad_row", Type: TypeBoolean},
+			{Name: "is_empty", Type: TypeBoolean, Nullable: true},
+		},
+		Key:     []string{"id"},
+		Version: "create_at",
+		Indexes: []Index{
+			{Name: "readings_device_dt", Columns: []string{"device_id", "archive", "dt"}},
+		},
+	}
+)
@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// Sink хранилище данных Каскада
+type Sink interface {
+	// SaveGauges сохраняет список приборов учета. Тепловые вводы и каналы сохраненного прибора учета заменяются
+	// тепловыми вводами и каналами из списка gauges
+	SaveGauges(ctx context.Context, gauges []parsers.Gauge) error
+
+	// SaveReadings сохраняет показания по идентификатору и возвращает количество сохраненных показаний. Повторное
+	// сохранение показания не создает дубликатов
+	SaveReadings(ctx context.Context, readings []parsers.Readings) (int, error)
+
+	// Close закрывает хранилище
+	Close() error
+}
+
+var _ Sink = (*DB)(nil)
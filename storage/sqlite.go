@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// SQLite диалект SQLite. Моменты времени хранятся строками в формате АИСКУТЭ Каскад, что сохраняет порядок
+// сравнения строк. Драйвер базы данных подключается приложением; база данных с драйвером modernc.org/sqlite
+// открывается функцией Open пакета storage/sqlite
+var SQLite Dialect = sqlite{}
+
+type sqlite struct{}
+
+// Name реализация метода Dialect.Name
+func (sqlite) Name() string {
+	return "sqlite"
+}
+
+// ColumnType реализация метода Dialect.ColumnType
+func (sqlite) ColumnType(column Column) string {
+	var t string
+
+	switch column.Type {
+	case TypeInteger, TypeBoolean:
+		t = "INTEGER"
+	case TypeFloat:
+		t = "REAL"
+	default:
+		t = "TEXT"
+	}
+
+	if !column.Nullable {
+		t += " NOT NULL"
+	}
+
+	return t
+}
+
+// CreateTable реализация метода Dialect.CreateTable
+func (d sqlite) CreateTable(table *Table) []string {
+	return createTable(d, table)
+}
+
+// Insert реализация метода Dialect.Insert
+func (sqlite) Insert(table *Table, rows int) string {
+	return insertValues(table, rows) + onConflictUpdate(table)
+}
+
+// Placeholder реализация метода Dialect.Placeholder
+func (sqlite) Placeholder(int) string {
+	return "?"
+}
+
+// MaxParameters реализация метода Dialect.MaxParameters
+func (sqlite) MaxParameters() int {
+	return 32766
+}
+
+// TimeValue реализация метода Dialect.TimeValue
+func (sqlite) TimeValue(t time.Time) driver.Value {
+	return parsers.ReadingTime(t).String()
+}
@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	// драйвер SQLite
+	_ "modernc.org/sqlite"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/storage"
+)
+
+// Open открывает базу данных SQLite в файле path (":memory:" - в памяти) с драйвером modernc.org/sqlite и обновляет
+// ее схему до актуальной версии
+func Open(ctx context.Context, path string, opts ...storage.Option) (*storage.DB, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", url.PathEscape(path))
+
+	db, err := sql.Open("sqlite", dsn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite допускает одновременную запись только одним соединением, а база данных в памяти существует в
+	// пределах соединения
+	db.SetMaxOpenConns(1)
+
+	s, err := storage.New(ctx, db, storage.SQLite, opts...)
+
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
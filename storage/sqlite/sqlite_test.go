@@ -0,0 +1,307 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/storage"
+	"github.com/vitpelekhaty/go-cascade-client/v2/storage/sqlite"
+)
+
+func loadGauges(t *testing.T) []parsers.Gauge {
+	path, err := filepath.Abs("../../testdata/responses/counterHouse.json")
+
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path)
+
+	require.NoError(t, err)
+
+	items, err := parsers.ParseGaugesList(context.TODO(), data)
+
+	require.NoError(t, err)
+
+	gauges := make([]parsers.Gauge, 0)
+
+	for item := range items {
+		require.NoError(t, item.E)
+
+		gauges = append(gauges, *item.V.(*parsers.Gauge))
+	}
+
+	return gauges
+}
+
+func loadReadings(t *testing.T) []parsers.Readings {
+	path, err := filepath.Abs("../../testdata/responses/readings200.json")
+
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path)
+
+	require.NoError(t, err)
+
+	items, err := parsers.ParseReadings(context.TODO(), data)
+
+	require.NoError(t, err)
+
+	readings := make([]parsers.Readings, 0)
+
+	for item := range items {
+		require.NoError(t, item.E)
+
+		readings = append(readings, *item.V.(*parsers.Readings))
+	}
+
+	return readings
+}
+
+func find(readings []parsers.Readings, id int64) *parsers.Readings {
+	for i := range readings {
+		if readings[i].ID.Int64 == id {
+			return &readings[i]
+		}
+	}
+
+	return &parsers.Readings{}
+}
+
+func openDB(t *testing.T, path string) *storage.DB {
+	db, err := sqlite.Open(context.TODO(), path)
+
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	return db
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cascade.db")
+
+	db, err := sqlite.Open(context.TODO(), path)
+
+	require.NoError(t, err)
+
+	version, err := db.Version(context.TODO())
+
+	require.NoError(t, err)
+	assert.Equal(t, storage.SchemaVersion, version)
+
+	require.NoError(t, db.Close())
+
+	db = openDB(t, path)
+
+	version, err = db.Version(context.TODO())
+
+	require.NoError(t, err)
+	assert.Equal(t, storage.SchemaVersion, version)
+
+	raw, err := sql.Open("sqlite", path)
+
+	require.NoError(t, err)
+
+	_, err = raw.Exec(`INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)`,
+		storage.SchemaVersion+1, "future", "2021-04-11T01:00:00.000")
+
+	require.NoError(t, err)
+	require.NoError(t, raw.Close())
+
+	_, err = sqlite.Open(context.TODO(), path)
+
+	assert.EqualError(t, err, fmt.Sprintf("schema version %d is newer than supported version %d",
+		storage.SchemaVersion+1, storage.SchemaVersion))
+}
+
+func TestDB_Gauges(t *testing.T) {
+	db := openDB(t, ":memory:")
+	gauges := loadGauges(t)
+
+	require.NoError(t, db.SaveGauges(context.TODO(), gauges))
+	require.NoError(t, db.SaveGauges(context.TODO(), gauges))
+
+	saved, err := db.Gauges(context.TODO())
+
+	require.NoError(t, err)
+	require.Len(t, saved, 38)
+
+	var channels int
+
+	for _, gauge := range saved {
+		for _, input := range gauge.Inputs {
+			channels += len(input.Channels)
+		}
+	}
+
+	assert.Equal(t, 127+50, channels)
+
+	gauge := gauges[0]
+	gauge.Inputs = gauge.Inputs[:1]
+	gauge.Inputs[0].Channels = []parsers.Channel{
+		{ID: 9246, Number: 1, Resource: parsers.ResourceUnknown, RawResource: "Plasma", Flow: parsers.FlowDirect},
+		{ID: 16201, Number: 0, Resource: parsers.ResourceNone},
+	}
+
+	require.NoError(t, db.SaveGauges(context.TODO(), []parsers.Gauge{gauge}))
+
+	g, err := db.Gauge(context.TODO(), gauge.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, []parsers.Input{
+		{
+			Number: gauge.Inputs[0].Number,
+			Channels: []parsers.Channel{
+				{ID: 16201, Number: 0, Resource: parsers.ResourceNone},
+				{ID: 9246, Number: 1, Resource: parsers.ResourceUnknown, RawResource: "Plasma", Flow: parsers.FlowDirect},
+			},
+		},
+	}, g.Inputs)
+
+	_, err = db.Gauge(context.TODO(), -1)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDB_Readings(t *testing.T) {
+	db := openDB(t, ":memory:")
+	readings := loadReadings(t)
+
+	count, err := db.SaveReadings(context.TODO(), readings)
+
+	require.NoError(t, err)
+	assert.Equal(t, 507, count)
+
+	count, err = db.SaveReadings(context.TODO(), readings)
+
+	require.NoError(t, err)
+	assert.Equal(t, 507, count)
+
+	from := time.Date(2021, 4, 11, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 4, 12, 0, 0, 0, 0, time.UTC)
+
+	saved, err := db.Readings(context.TODO(), 12032, archive.HourArchive, from, to)
+
+	require.NoError(t, err)
+	require.Len(t, saved, 23*3)
+	assert.Equal(t, readings[0], *find(saved, readings[0].ID.Int64))
+
+	altered := readings[0]
+	altered.M = null.FloatFrom(3)
+	altered.CreateAt = parsers.ReadingTime(time.Time(altered.CreateAt).Add(time.Hour))
+
+	outdated := readings[0]
+	outdated.M = null.FloatFrom(1)
+	outdated.CreateAt = parsers.ReadingTime(time.Time(altered.CreateAt).Add(-2 * time.Hour))
+
+	_, err = db.SaveReadings(context.TODO(), []parsers.Readings{altered, outdated, {DeviceID: null.IntFrom(12032)}})
+
+	require.NoError(t, err)
+
+	saved, err = db.Readings(context.TODO(), 12032, archive.HourArchive, from, from.Add(time.Hour+time.Second))
+
+	require.NoError(t, err)
+	require.Len(t, saved, 3)
+	assert.Equal(t, altered, *find(saved, altered.ID.Int64))
+
+	last, err := db.LastReadingTime(context.TODO(), 12032, archive.HourArchive)
+
+	require.NoError(t, err)
+	assert.Equal(t, "2021-04-18T01:00:00.000", last.String())
+
+	last, err = db.LastReadingTime(context.TODO(), 12032, archive.DailyArchive)
+
+	require.NoError(t, err)
+	assert.True(t, last.IsZero())
+}
+
+func TestDB_SaveReadingsBatches(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+
+	require.NoError(t, err)
+
+	db.SetMaxOpenConns(1)
+
+	var sink storage.Sink
+
+	sink, err = storage.New(context.TODO(), db, storage.SQLite, storage.WithBatchSize(7))
+
+	require.NoError(t, err)
+
+	defer sink.Close()
+
+	count, err := sink.SaveReadings(context.TODO(), loadReadings(t))
+
+	require.NoError(t, err)
+	assert.Equal(t, 507, count)
+
+	var rows int
+
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM readings`).Scan(&rows))
+	assert.Equal(t, 507, rows)
+}
+
+// rowDialect диалект SQLite, наибольшее количество параметров запроса которого меньше количества колонок таблиц
+type rowDialect struct {
+	storage.Dialect
+}
+
+func (rowDialect) MaxParameters() int {
+	return 0
+}
+
+func TestDB_SaveReadingsMaxParameters(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+
+	require.NoError(t, err)
+
+	db.SetMaxOpenConns(1)
+
+	s, err := storage.New(context.TODO(), db, rowDialect{storage.SQLite})
+
+	require.NoError(t, err)
+
+	defer s.Close()
+
+	count, err := s.SaveReadings(context.TODO(), loadReadings(t)[:3])
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestDB_SaveReadingsDuplicates(t *testing.T) {
+	db := openDB(t, ":memory:")
+	r := loadReadings(t)[0]
+
+	newest := r
+	newest.M = null.FloatFrom(3)
+	newest.CreateAt = parsers.ReadingTime(time.Time(r.CreateAt).Add(time.Hour))
+
+	outdated := r
+	outdated.M = null.FloatFrom(1)
+
+	count, err := db.SaveReadings(context.TODO(), []parsers.Readings{r, newest, outdated})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	from := time.Time(r.DT)
+
+	saved, err := db.Readings(context.TODO(), r.DeviceID.Int64, r.Archive, from, from.Add(time.Second))
+
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	assert.Equal(t, newest, saved[0])
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+)
+
+// DB хранилище данных Каскада в базе данных, доступной через database/sql: список приборов учета (приборы учета,
+// тепловые вводы и каналы) и архивы показаний. Особенности базы данных определяются диалектом SQL (см. Dialect)
+type DB struct {
+	db      *sql.DB
+	dialect Dialect
+	options *dbOptions
+}
+
+// New возвращает DB для открытой базы данных db с диалектом SQL dialect и обновляет схему базы данных до
+// актуальной версии
+func New(ctx context.Context, db *sql.DB, dialect Dialect, opts ...Option) (*DB, error) {
+	if err := migrate(ctx, db, dialect); err != nil {
+		return nil, err
+	}
+
+	return &DB{db: db, dialect: dialect, options: newDBOptions(opts)}, nil
+}
+
+// Dialect возвращает диалект SQL базы данных
+func (s *DB) Dialect() Dialect {
+	return s.dialect
+}
+
+// Version возвращает версию схемы базы данных
+func (s *DB) Version(ctx context.Context) (int, error) {
+	return schemaVersion(ctx, s.db)
+}
+
+// Close закрывает базу данных
+func (s *DB) Close() error {
+	return s.db.Close()
+}
+
+// insert вставляет строки rows в таблицу table пакетами с заменой строк с тем же первичным ключом
+func (s *DB) insert(ctx context.Context, tx *sql.Tx, table *Table, rows [][]interface{}) error {
+	size := s.options.batchSize
+
+	if limit := s.dialect.MaxParameters() / len(table.Columns); limit < size {
+		size = limit
+	}
+
+	// диалект с наибольшим количеством параметров меньше количества колонок вставляет строки по одной
+	if size < 1 {
+		size = 1
+	}
+
+	for len(rows) > 0 {
+		n := size
+
+		if len(rows) < n {
+			n = len(rows)
+		}
+
+		args := make([]interface{}, 0, n*len(table.Columns))
+
+		for _, row := range rows[:n] {
+			args = append(args, row...)
+		}
+
+		if _, err := tx.ExecContext(ctx, rebind(s.dialect, s.dialect.Insert(table, n)), args...); err != nil {
+			return err
+		}
+
+		rows = rows[n:]
+	}
+
+	return nil
+}
+
+// query выполняет запрос query с параметрами '?'
+func (s *DB) query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, rebind(s.dialect, query), args...)
+}
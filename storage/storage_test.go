@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialects(t *testing.T) {
+	assert.Equal(t, "SELECT * FROM readings WHERE device_id = $1 AND dt >= $2",
+		rebind(PostgreSQL, "SELECT * FROM readings WHERE device_id = ? AND dt >= ?"))
+
+	assert.Equal(t, "INSERT INTO inputs (device_id, number) VALUES ($1, $2), ($3, $4) "+
+		"ON CONFLICT (device_id, number) DO NOTHING",
+		rebind(PostgreSQL, PostgreSQL.Insert(inputsTable, 2)))
+
+	assert.Equal(t, "INSERT INTO devices (id, name, model, serial_number, title) VALUES (?, ?, ?, ?, ?) "+
+		"ON CONFLICT (id) DO UPDATE SET name = excluded.name, model = excluded.model, "+
+		"serial_number = excluded.serial_number, title = excluded.title",
+		SQLite.Insert(devicesTable, 1))
+
+	assert.Contains(t, SQLite.Insert(readingsTable, 1),
+		"WHERE excluded.create_at IS NULL OR readings.create_at IS NULL OR excluded.create_at >= readings.create_at")
+
+	assert.Equal(t, []string{
+		"CREATE TABLE IF NOT EXISTS inputs (\n\tdevice_id BIGINT NOT NULL,\n\tnumber BIGINT NOT NULL,\n\t" +
+			"PRIMARY KEY (device_id, number)\n)",
+	}, PostgreSQL.CreateTable(inputsTable))
+
+	moment := time.Date(2021, 4, 11, 1, 0, 0, 0, time.FixedZone("UTC+5", 5*60*60))
+
+	assert.Equal(t, "2021-04-11T01:00:00.000", SQLite.TimeValue(moment))
+	assert.Equal(t, time.Date(2021, 4, 11, 1, 0, 0, 0, time.UTC), PostgreSQL.TimeValue(moment))
+}
+
+func TestMigrations(t *testing.T) {
+	tables := make(map[string]*Table)
+
+	for i, m := range migrations {
+		require.Equal(t, i+1, m.version)
+
+		for _, table := range m.tables {
+			tables[table.Name] = table
+		}
+	}
+
+	require.Len(t, tables, 4)
+
+	for _, live := range []*Table{devicesTable, inputsTable, channelsTable, readingsTable} {
+		table, ok := tables[live.Name]
+
+		require.True(t, ok, live.Name)
+
+		assert.Equal(t, live.Columns, table.Columns, live.Name)
+		assert.Equal(t, live.Key, table.Key, live.Name)
+		assert.Equal(t, live.Indexes, table.Indexes, live.Name)
+	}
+}
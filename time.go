@@ -2,8 +2,9 @@ package cascade
 
 import (
 	"fmt"
-	"strings"
 	"time"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/internal/jsontext"
 )
 
 // RequestTime описывает формат времени, принятый в запросах к АИСКУТЭ Каскад
@@ -13,7 +14,12 @@ const requestTimeLayout = `02.01.2006 15:04:05`
 
 // UnmarshalJSON реализация интерфейса Unmarshaler для типа RequestTime
 func (rt *RequestTime) UnmarshalJSON(b []byte) (err error) {
-	s := strings.Trim(string(b), `"`)
+	s, err := jsontext.Unquote(b)
+
+	if err != nil {
+		return err
+	}
+
 	t, err := time.Parse(requestTimeLayout, s)
 
 	*rt = RequestTime(t)
@@ -1,5 +1,7 @@
 package cascade
 
+import "time"
+
 // token ответ сервера авторизации
 type token struct {
 	// Value токен сессии
@@ -8,7 +10,7 @@ type token struct {
 	// Type тип токена (bearer etc)
 	Type string `json:"token_type"`
 
-	// ExpiresIn timestamp времени окончания действия токена
+	// ExpiresIn срок действия токена, с
 	ExpiresIn int64 `json:"expires_in"`
 
 	// Scope ???
@@ -26,3 +28,46 @@ type token struct {
 	// ServerType тип сервера (development etc)
 	ServerType string `json:"server_type"`
 }
+
+// Session параметры сеанса соединения с API Каскада, полученные при авторизации
+type Session struct {
+	// Type тип токена (bearer etc)
+	Type string
+
+	// ExpiresIn срок действия токена на момент авторизации
+	ExpiresIn time.Duration
+
+	// UserID идентификатор пользователя в Каскаде
+	UserID int
+
+	// Connection наименование соединения
+	Connection string
+
+	// ServerType тип сервера (development etc)
+	ServerType string
+}
+
+// SessionOf возвращает параметры сеанса соединения conn. Параметры доступны только для открытого соединения,
+// созданного функцией NewConnection
+func SessionOf(conn IConnection) (Session, bool) {
+	c, ok := conn.(*connection)
+
+	if !ok {
+		return Session{}, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.token == nil {
+		return Session{}, false
+	}
+
+	return Session{
+		Type:       c.token.Type,
+		ExpiresIn:  time.Duration(c.token.ExpiresIn) * time.Second,
+		UserID:     c.token.UserID,
+		Connection: c.token.Connection,
+		ServerType: c.token.ServerType,
+	}, true
+}
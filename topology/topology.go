@@ -0,0 +1,242 @@
+package topology
+
+import (
+	"context"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+// Topology индекс приборов учета, тепловых вводов и каналов, построенный по списку приборов учета
+type Topology struct {
+	devices  []*parsers.Gauge
+	byDevice map[int64]*parsers.Gauge
+	channels map[int64]*Channel
+	byNumber map[channelKey]*Channel
+}
+
+// Channel канал прибора учета с указанием прибора учета и теплового ввода, к которым он относится
+type Channel struct {
+	// Device прибор учета
+	Device *parsers.Gauge
+
+	// Input тепловой ввод
+	Input *parsers.Input
+
+	// Channel канал
+	Channel *parsers.Channel
+}
+
+// Resource возвращает тип ресурса канала
+func (c *Channel) Resource() parsers.Resource {
+	return c.Channel.Resource
+}
+
+// Flow возвращает тип подключения канала
+func (c *Channel) Flow() parsers.Flow {
+	return c.Channel.Flow
+}
+
+// Reading показание прибора учета, дополненное сведениями о канале
+type Reading struct {
+	*parsers.Readings
+
+	// Device прибор учета
+	Device *parsers.Gauge
+
+	// Resource тип ресурса канала
+	Resource parsers.Resource
+
+	// Flow тип подключения канала
+	Flow parsers.Flow
+}
+
+// channelKey ключ поиска канала по номеру на тепловом вводе прибора учета
+type channelKey struct {
+	device  int64
+	input   int32
+	channel int32
+}
+
+// New возвращает индекс списка приборов учета gauges. Индекс строится по копии списка, включая тепловые вводы и
+// каналы, поэтому последующие изменения gauges на индекс не влияют
+func New(gauges []parsers.Gauge) *Topology {
+	gauges = append([]parsers.Gauge(nil), gauges...)
+
+	for i := range gauges {
+		inputs := append([]parsers.Input(nil), gauges[i].Inputs...)
+
+		for j := range inputs {
+			inputs[j].Channels = append([]parsers.Channel(nil), inputs[j].Channels...)
+		}
+
+		gauges[i].Inputs = inputs
+	}
+
+	t := &Topology{
+		devices:  make([]*parsers.Gauge, 0, len(gauges)),
+		byDevice: make(map[int64]*parsers.Gauge, len(gauges)),
+		channels: make(map[int64]*Channel),
+		byNumber: make(map[channelKey]*Channel),
+	}
+
+	for i := range gauges {
+		t.add(&gauges[i])
+	}
+
+	return t
+}
+
+// FromItems возвращает индекс списка приборов учета, полученного от ParseGaugesList (см. parsers.Each). Возвращает
+// первую ошибку разбора списка, если приборы учета с ошибкой разбора не пропускаются опцией parsers.WithSkip
+func FromItems(ctx context.Context, items <-chan parsers.Item, options ...parsers.EachOption) (*Topology, error) {
+	gauges := make([]parsers.Gauge, 0)
+
+	_, err := parsers.EachGauge(ctx, items, func(gauge *parsers.Gauge) error {
+		gauges = append(gauges, *gauge)
+		return nil
+	}, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return New(gauges), nil
+}
+
+func (t *Topology) add(gauge *parsers.Gauge) {
+	t.devices = append(t.devices, gauge)
+	t.byDevice[gauge.ID] = gauge
+
+	for i := range gauge.Inputs {
+		input := &gauge.Inputs[i]
+
+		for j := range input.Channels {
+			channel := &Channel{
+				Device:  gauge,
+				Input:   input,
+				Channel: &input.Channels[j],
+			}
+
+			t.channels[channel.Channel.ID] = channel
+			t.byNumber[channelKey{device: gauge.ID, input: input.Number, channel: channel.Channel.Number}] = channel
+		}
+	}
+}
+
+// Devices возвращает список приборов учета в порядке исходного списка
+func (t *Topology) Devices() []*parsers.Gauge {
+	return t.devices
+}
+
+// Device возвращает прибор учета по его идентификатору
+func (t *Topology) Device(id int64) (*parsers.Gauge, bool) {
+	gauge, ok := t.byDevice[id]
+	return gauge, ok
+}
+
+// Input возвращает тепловой ввод прибора учета по его номеру
+func (t *Topology) Input(deviceID int64, number int32) (*parsers.Input, bool) {
+	gauge, ok := t.byDevice[deviceID]
+
+	if !ok {
+		return nil, false
+	}
+
+	for i := range gauge.Inputs {
+		if gauge.Inputs[i].Number == number {
+			return &gauge.Inputs[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// Channel возвращает канал по его идентификатору
+func (t *Topology) Channel(id int64) (*Channel, bool) {
+	channel, ok := t.channels[id]
+	return channel, ok
+}
+
+// Channels возвращает каналы теплового ввода прибора учета
+func (t *Topology) Channels(deviceID int64, input int32) []*Channel {
+	in, ok := t.Input(deviceID, input)
+
+	if !ok {
+		return nil
+	}
+
+	channels := make([]*Channel, 0, len(in.Channels))
+
+	for i := range in.Channels {
+		if channel, ok := t.channels[in.Channels[i].ID]; ok {
+			channels = append(channels, channel)
+		}
+	}
+
+	return channels
+}
+
+// Resource возвращает тип ресурса канала
+func (t *Topology) Resource(channelID int64) (parsers.Resource, bool) {
+	channel, ok := t.channels[channelID]
+
+	if !ok {
+		return parsers.ResourceUnknown, false
+	}
+
+	return channel.Resource(), true
+}
+
+// Flow возвращает тип подключения канала
+func (t *Topology) Flow(channelID int64) (parsers.Flow, bool) {
+	channel, ok := t.channels[channelID]
+
+	if !ok {
+		return parsers.FlowUnknown, false
+	}
+
+	return channel.Flow(), true
+}
+
+// ChannelOf возвращает канал, к которому относится показание. Канал ищется по идентификатору, а если он не указан -
+// по идентификатору прибора учета, номеру теплового ввода и номеру канала
+func (t *Topology) ChannelOf(r *parsers.Readings) (*Channel, bool) {
+	if r.ChannelID.Valid {
+		channel, ok := t.channels[r.ChannelID.Int64]
+		return channel, ok
+	}
+
+	if r.DeviceID.Valid && r.Input.Valid && r.ChannelNum.Valid {
+		channel, ok := t.byNumber[channelKey{
+			device:  r.DeviceID.Int64,
+			input:   int32(r.Input.Int64),
+			channel: int32(r.ChannelNum.Int64),
+		}]
+
+		return channel, ok
+	}
+
+	return nil, false
+}
+
+// Enrich дополняет показание типом ресурса и типом подключения его канала. Если канал не найден, возвращается
+// показание с неизвестными типами ресурса и подключения и признак false
+func (t *Topology) Enrich(r *parsers.Readings) (*Reading, bool) {
+	reading := &Reading{Readings: r}
+
+	if r.DeviceID.Valid {
+		reading.Device = t.byDevice[r.DeviceID.Int64]
+	}
+
+	channel, ok := t.ChannelOf(r)
+
+	if !ok {
+		return reading, false
+	}
+
+	reading.Device = channel.Device
+	reading.Resource = channel.Resource()
+	reading.Flow = channel.Flow()
+
+	return reading, true
+}
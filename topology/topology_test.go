@@ -0,0 +1,152 @@
+package topology
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+)
+
+func loadTopology(t *testing.T) *Topology {
+	path, err := filepath.Abs("../testdata/responses/counterHouse.json")
+
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(path)
+
+	require.NoError(t, err)
+
+	items, err := parsers.ParseGaugesList(context.TODO(), data)
+
+	require.NoError(t, err)
+
+	topo, err := FromItems(context.TODO(), items)
+
+	require.NoError(t, err)
+
+	return topo
+}
+
+func TestTopology_Channel(t *testing.T) {
+	topo := loadTopology(t)
+
+	assert.Len(t, topo.Devices(), 38)
+
+	device, ok := topo.Device(8830)
+
+	require.True(t, ok)
+	assert.Equal(t, "11412", device.SN)
+
+	channel, ok := topo.Channel(9246)
+
+	require.True(t, ok)
+	assert.Equal(t, int64(8830), channel.Device.ID)
+	assert.Equal(t, int32(1), channel.Input.Number)
+	assert.Equal(t, parsers.ResourceHeat, channel.Resource())
+	assert.Equal(t, parsers.FlowDirect, channel.Flow())
+
+	resource, ok := topo.Resource(9249)
+
+	require.True(t, ok)
+	assert.Equal(t, parsers.ResourceHotWater, resource)
+
+	flow, ok := topo.Flow(9249)
+
+	require.True(t, ok)
+	assert.Equal(t, parsers.FlowReverse, flow)
+
+	_, ok = topo.Channel(-1)
+
+	assert.False(t, ok)
+
+	assert.Len(t, topo.Channels(8830, 1), 5)
+	assert.Empty(t, topo.Channels(8830, 2))
+}
+
+func TestTopology_Enrich(t *testing.T) {
+	topo := loadTopology(t)
+
+	var cases = []struct {
+		reading  parsers.Readings
+		ok       bool
+		device   int64
+		resource parsers.Resource
+		flow     parsers.Flow
+	}{
+		{
+			reading:  parsers.Readings{DeviceID: null.IntFrom(8830), ChannelID: null.IntFrom(9250)},
+			ok:       true,
+			device:   8830,
+			resource: parsers.ResourceHotWater,
+			flow:     parsers.FlowDirect,
+		},
+		{
+			reading: parsers.Readings{
+				DeviceID:   null.IntFrom(8830),
+				Input:      null.IntFrom(1),
+				ChannelNum: null.IntFrom(2),
+			},
+			ok:       true,
+			device:   8830,
+			resource: parsers.ResourceHeat,
+			flow:     parsers.FlowReverse,
+		},
+		{
+			reading: parsers.Readings{DeviceID: null.IntFrom(8830), ChannelID: null.IntFrom(-1)},
+			ok:      false,
+			device:  8830,
+		},
+	}
+
+	for _, test := range cases {
+		reading := test.reading
+
+		enriched, ok := topo.Enrich(&reading)
+
+		assert.Equal(t, test.ok, ok)
+		require.NotNil(t, enriched.Device)
+		assert.Equal(t, test.device, enriched.Device.ID)
+		assert.Equal(t, test.resource, enriched.Resource)
+		assert.Equal(t, test.flow, enriched.Flow)
+		assert.Same(t, &reading, enriched.Readings)
+	}
+}
+
+func TestNew_Copy(t *testing.T) {
+	gauges := []parsers.Gauge{
+		{ID: 1, Inputs: []parsers.Input{{Number: 1, Channels: []parsers.Channel{{ID: 10, Resource: parsers.ResourceHeat}}}}},
+	}
+
+	topo := New(gauges)
+
+	gauges[0].Inputs[0].Channels[0].Resource = parsers.ResourceColdWater
+	gauges[0].Inputs[0].Number = 2
+
+	resource, ok := topo.Resource(10)
+
+	require.True(t, ok)
+	assert.Equal(t, parsers.ResourceHeat, resource)
+	assert.Len(t, topo.Channels(1, 1), 1)
+}
+
+func TestFromItems_Error(t *testing.T) {
+	items := make(chan parsers.Item, 3)
+
+	items <- parsers.Item{E: errors.New("first")}
+	items <- parsers.Item{E: errors.New("second")}
+	items <- parsers.Item{V: &parsers.Gauge{ID: 1}}
+
+	close(items)
+
+	_, err := FromItems(context.TODO(), items)
+
+	assert.EqualError(t, err, "first")
+	assert.Empty(t, items)
+}
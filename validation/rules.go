@@ -0,0 +1,175 @@
+package validation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// Rule правило проверки показаний
+type Rule struct {
+	// Name наименование правила
+	Name string
+
+	// Severity важность нарушения правила
+	Severity Severity
+
+	// Check проверяет строку показаний и возвращает описания нарушений правила
+	Check func(row *Row) []string
+}
+
+const (
+	// DefaultMaxTemperature максимальная допустимая температура теплоносителя по умолчанию, °C
+	DefaultMaxTemperature = 150.0
+
+	// DefaultMinPressure минимальное допустимое давление по умолчанию, кгс/см2
+	DefaultMinPressure = 0.0
+
+	// DefaultMaxPressure максимальное допустимое давление по умолчанию, кгс/см2
+	DefaultMaxPressure = 25.0
+)
+
+// DefaultRules возвращает встроенные правила проверки показаний с параметрами по умолчанию
+func DefaultRules() []Rule {
+	return []Rule{
+		NonNegative(),
+		SupplyBelowReturn(),
+		MaxTemperature(DefaultMaxTemperature),
+		TIWithinInterval(),
+		PressureRange(DefaultMinPressure, DefaultMaxPressure),
+		HeatWithoutFlow(),
+	}
+}
+
+// NonNegative правило: массы, объемы и тепловая энергия (M, V, Q, Q1, Q2) не могут быть отрицательными
+func NonNegative() Rule {
+	return Rule{
+		Name:     "non-negative",
+		Severity: Error,
+		Check: func(row *Row) []string {
+			var messages []string
+
+			for _, field := range []struct {
+				name  string
+				value null.Float
+			}{
+				{name: "M", value: row.M},
+				{name: "V", value: row.V},
+				{name: "Q", value: row.Q},
+				{name: "Q1", value: row.Q1},
+				{name: "Q2", value: row.Q2},
+			} {
+				if field.value.Valid && field.value.Float64 < 0 {
+					messages = append(messages, fmt.Sprintf("negative %s %g", field.name, field.value.Float64))
+				}
+			}
+
+			return messages
+		},
+	}
+}
+
+// SupplyBelowReturn правило: температура подающего трубопровода не может быть ниже температуры обратного. Нарушение
+// отмечается на строке подающего трубопровода
+func SupplyBelowReturn() Rule {
+	return Rule{
+		Name:     "supply-below-return",
+		Severity: Warning,
+		Check: func(row *Row) []string {
+			if row.Pair == nil || row.Pair.Supply != row.Reading.Readings || !row.Pair.DeltaT.Valid {
+				return nil
+			}
+
+			if row.Pair.DeltaT.Float64 < 0 {
+				return []string{fmt.Sprintf("supply temperature %g is lower than return temperature %g",
+					row.Pair.Supply.T.Float64, row.Pair.Return.T.Float64)}
+			}
+
+			return nil
+		},
+	}
+}
+
+// MaxTemperature правило: температура теплоносителя не может превышать limit, °C
+func MaxTemperature(limit float64) Rule {
+	return Rule{
+		Name:     "max-temperature",
+		Severity: Error,
+		Check: func(row *Row) []string {
+			if row.T.Valid && row.T.Float64 > limit {
+				return []string{fmt.Sprintf("temperature %g is above %g", row.T.Float64, limit)}
+			}
+
+			return nil
+		},
+	}
+}
+
+// TIWithinInterval правило: время штатной работы прибора учета (TI, ч) не может превышать интервал архива. Для
+// месячного архива учитывается продолжительность месяца показания
+func TIWithinInterval() Rule {
+	return Rule{
+		Name:     "ti-within-interval",
+		Severity: Error,
+		Check: func(row *Row) []string {
+			if !row.TI.Valid {
+				return nil
+			}
+
+			interval := row.Archive.Duration().Hours()
+
+			if dt := time.Time(row.DT); !dt.IsZero() {
+				start := row.Archive.Truncate(dt)
+				interval = row.Archive.Next(start).Sub(start).Hours()
+			}
+
+			if interval == 0 {
+				return nil
+			}
+
+			if row.TI.Float64 > interval {
+				return []string{fmt.Sprintf("operating time %g h exceeds %s archive interval %g h",
+					row.TI.Float64, row.Archive, interval)}
+			}
+
+			return nil
+		},
+	}
+}
+
+// PressureRange правило: давление должно находиться в диапазоне от min до max
+func PressureRange(min, max float64) Rule {
+	return Rule{
+		Name:     "pressure-range",
+		Severity: Warning,
+		Check: func(row *Row) []string {
+			if row.P.Valid && (row.P.Float64 < min || row.P.Float64 > max) {
+				return []string{fmt.Sprintf("pressure %g is out of range [%g, %g]", row.P.Float64, min, max)}
+			}
+
+			return nil
+		},
+	}
+}
+
+// HeatWithoutFlow правило: тепловая энергия не может быть учтена при нулевом расходе теплоносителя
+func HeatWithoutFlow() Rule {
+	return Rule{
+		Name:     "heat-without-flow",
+		Severity: Warning,
+		Check: func(row *Row) []string {
+			if !row.M.Valid || row.M.Float64 != 0 {
+				return nil
+			}
+
+			for _, q := range []null.Float{row.Q, row.Q1, row.Q2} {
+				if q.Valid && q.Float64 > 0 {
+					return []string{fmt.Sprintf("heat %g Gcal with zero mass flow", q.Float64)}
+				}
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package validation
+
+// Severity важность нарушения
+type Severity byte
+
+const (
+	// Info информационное сообщение
+	Info Severity = iota
+
+	// Warning подозрительное значение
+	Warning
+
+	// Error физически невозможное значение
+	Error
+)
+
+// String возвращает строковое представление важности нарушения
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+
+	case Warning:
+		return "warning"
+
+	case Error:
+		return "error"
+
+	default:
+		return "unknown"
+	}
+}
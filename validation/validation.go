@@ -0,0 +1,174 @@
+package validation
+
+import (
+	"sort"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/pairing"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+// Row строка показаний с контекстом проверки
+type Row struct {
+	*topology.Reading
+
+	// Pair пара показаний подающего и обратного трубопроводов, в которую входит строка. Если строка не входит в пару
+	// или список приборов учета не указан, имеет значение nil
+	Pair *pairing.Pair
+}
+
+// Violation нарушение правила проверки
+type Violation struct {
+	// Rule наименование правила
+	Rule string
+
+	// Severity важность нарушения
+	Severity Severity
+
+	// Message описание нарушения
+	Message string
+}
+
+// Annotation результат проверки строки показаний
+type Annotation struct {
+	// Row строка показаний
+	Row *Row
+
+	// Violations нарушения правил проверки
+	Violations []Violation
+}
+
+// Valid возвращает признак отсутствия нарушений
+func (a *Annotation) Valid() bool {
+	return len(a.Violations) == 0
+}
+
+// Severity возвращает наибольшую важность нарушений строки
+func (a *Annotation) Severity() Severity {
+	var severity Severity
+
+	for _, v := range a.Violations {
+		if v.Severity > severity {
+			severity = v.Severity
+		}
+	}
+
+	return severity
+}
+
+// DeviceSummary сводка результатов проверки показаний прибора учета
+type DeviceSummary struct {
+	// DeviceID идентификатор прибора учета
+	DeviceID int64
+
+	// Rows количество проверенных строк
+	Rows int
+
+	// InvalidRows количество строк с нарушениями
+	InvalidRows int
+
+	// BySeverity количество нарушений по важности
+	BySeverity map[Severity]int
+
+	// ByRule количество нарушений по правилам
+	ByRule map[string]int
+}
+
+// Result результат проверки показаний
+type Result struct {
+	// Rows результаты проверки строк в порядке исходных показаний
+	Rows []*Annotation
+
+	// Devices сводка по приборам учета в порядке возрастания идентификатора
+	Devices []*DeviceSummary
+}
+
+// Engine механизм проверки показаний по набору правил
+type Engine struct {
+	rules []Rule
+}
+
+// NewEngine возвращает механизм проверки показаний по правилам rules. Если правила не указаны, используются
+// встроенные правила DefaultRules
+func NewEngine(rules ...Rule) *Engine {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+
+	return &Engine{rules: rules}
+}
+
+// Validate проверяет показания readings. Список приборов учета topo используется для определения типов ресурса и
+// подключения каналов и сопоставления подающих и обратных трубопроводов; может иметь значение nil
+func (e *Engine) Validate(topo *topology.Topology, readings []parsers.Readings) *Result {
+	pairs := make(map[*parsers.Readings]*pairing.Pair)
+
+	if topo != nil {
+		for _, pair := range pairing.Pairs(topo, readings) {
+			if pair.Supply != nil {
+				pairs[pair.Supply] = pair
+			}
+
+			if pair.Return != nil {
+				pairs[pair.Return] = pair
+			}
+		}
+	}
+
+	result := &Result{Rows: make([]*Annotation, 0, len(readings))}
+	devices := make(map[int64]*DeviceSummary)
+
+	for i := range readings {
+		r := &readings[i]
+
+		row := &Row{Reading: &topology.Reading{Readings: r}, Pair: pairs[r]}
+
+		if topo != nil {
+			row.Reading, _ = topo.Enrich(r)
+		}
+
+		annotation := &Annotation{Row: row}
+
+		for _, rule := range e.rules {
+			for _, message := range rule.Check(row) {
+				annotation.Violations = append(annotation.Violations, Violation{
+					Rule:     rule.Name,
+					Severity: rule.Severity,
+					Message:  message,
+				})
+			}
+		}
+
+		result.Rows = append(result.Rows, annotation)
+
+		summary, ok := devices[r.DeviceID.Int64]
+
+		if !ok {
+			summary = &DeviceSummary{
+				DeviceID:   r.DeviceID.Int64,
+				BySeverity: make(map[Severity]int),
+				ByRule:     make(map[string]int),
+			}
+
+			devices[r.DeviceID.Int64] = summary
+			result.Devices = append(result.Devices, summary)
+		}
+
+		summary.Rows++
+
+		if !annotation.Valid() {
+			summary.InvalidRows++
+		}
+
+		for _, v := range annotation.Violations {
+			summary.BySeverity[v.Severity]++
+			summary.ByRule[v.Rule]++
+		}
+	}
+
+	sort.Slice(result.Devices, func(i, j int) bool {
+		return result.Devices[i].DeviceID < result.Devices[j].DeviceID
+	})
+
+	return result
+}
@@ -0,0 +1,123 @@
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitpelekhaty/go-cascade-client/v2/archive"
+	"github.com/vitpelekhaty/go-cascade-client/v2/parsers"
+	"github.com/vitpelekhaty/go-cascade-client/v2/topology"
+)
+
+func testTopology() *topology.Topology {
+	return topology.New([]parsers.Gauge{
+		{
+			ID: 1,
+			Inputs: []parsers.Input{
+				{
+					Number: 1,
+					Channels: []parsers.Channel{
+						{ID: 10, Number: 1, Resource: parsers.ResourceHeat, Flow: parsers.FlowDirect},
+						{ID: 11, Number: 2, Resource: parsers.ResourceHeat, Flow: parsers.FlowReverse},
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestEngine_Validate(t *testing.T) {
+	dt := parsers.ReadingTime(time.Date(2021, 4, 11, 1, 0, 0, 0, time.UTC))
+
+	row := func(channelID int64, m, temp, p, ti, q null.Float) parsers.Readings {
+		return parsers.Readings{
+			Archive:   archive.HourArchive,
+			DeviceID:  null.IntFrom(1),
+			Input:     null.IntFrom(1),
+			ChannelID: null.IntFrom(channelID),
+			DT:        dt,
+			M:         m,
+			T:         temp,
+			P:         p,
+			TI:        ti,
+			Q:         q,
+		}
+	}
+
+	f := null.FloatFrom
+
+	readings := []parsers.Readings{
+		row(10, f(10), f(40), f(7), f(1), null.Float{}),
+		row(11, f(-1), f(160), f(30), f(1.5), null.Float{}),
+		row(12, f(0), null.Float{}, null.Float{}, f(1), f(0.5)),
+	}
+
+	daily := row(12, f(1), f(70), f(7), f(24), null.Float{})
+	daily.Archive = archive.DailyArchive
+	daily.DeviceID = null.IntFrom(2)
+
+	monthly := row(12, f(1), f(70), f(7), f(720), null.Float{})
+	monthly.Archive = archive.MonthlyArchive
+	monthly.DeviceID = null.IntFrom(2)
+
+	readings = append(readings, daily, monthly)
+
+	result := NewEngine().Validate(testTopology(), readings)
+
+	require.Len(t, result.Rows, 5)
+
+	rules := func(a *Annotation) []string {
+		names := make([]string, 0)
+
+		for _, v := range a.Violations {
+			names = append(names, v.Rule)
+		}
+
+		return names
+	}
+
+	assert.Equal(t, []string{"supply-below-return"}, rules(result.Rows[0]))
+	assert.Equal(t, Warning, result.Rows[0].Severity())
+	assert.Equal(t, parsers.FlowDirect, result.Rows[0].Row.Flow)
+
+	assert.Equal(t, []string{"non-negative", "max-temperature", "ti-within-interval", "pressure-range"},
+		rules(result.Rows[1]))
+	assert.Equal(t, Error, result.Rows[1].Severity())
+
+	assert.Equal(t, []string{"heat-without-flow"}, rules(result.Rows[2]))
+	assert.True(t, result.Rows[3].Valid())
+	assert.True(t, result.Rows[4].Valid())
+
+	require.Len(t, result.Devices, 2)
+
+	device := result.Devices[0]
+
+	assert.Equal(t, int64(1), device.DeviceID)
+	assert.Equal(t, 3, device.Rows)
+	assert.Equal(t, 3, device.InvalidRows)
+	assert.Equal(t, 3, device.BySeverity[Error])
+	assert.Equal(t, 3, device.BySeverity[Warning])
+	assert.Equal(t, 1, device.ByRule["pressure-range"])
+
+	custom := NewEngine(Rule{
+		Name:     "no-bad-rows",
+		Severity: Info,
+		Check: func(row *Row) []string {
+			if row.DeviceID.Int64 == 2 {
+				return []string{"device 2"}
+			}
+
+			return nil
+		},
+	})
+
+	result = custom.Validate(nil, readings)
+
+	assert.True(t, result.Rows[0].Valid())
+	assert.False(t, result.Rows[3].Valid())
+	assert.Equal(t, 2, result.Devices[1].BySeverity[Info])
+}